@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"tournois-tt/api/pkg/apikeys"
+)
+
+// apikeysCommand runs the subcommands administering the API keys of third-party consumers.
+// The API reloads the keys, they apply without restarting it.
+func apikeysCommand(args []string) error {
+	return subcommand("apikeys", args, map[string]func([]string) error{
+		"create": apikeysCreate,
+		"list":   apikeysList,
+		"tier":   apikeysTier,
+		"revoke": apikeysRevoke,
+	})
+}
+
+// openKeys loads the configuration and the API keys of the cache directory
+func (o *options) openKeys() (*apikeys.Store, error) {
+	if _, err := o.config(); err != nil {
+		return nil, err
+	}
+	store, err := apikeys.NewStore(o.dir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API keys: %v", err)
+	}
+	return store, nil
+}
+
+// findKey returns the key with id
+func findKey(store *apikeys.Store, id string) (apikeys.Key, error) {
+	for _, key := range store.List() {
+		if key.ID == id {
+			return key, nil
+		}
+	}
+	return apikeys.Key{}, fmt.Errorf("unknown key %q", id)
+}
+
+// checkTier returns an error for an unknown tier
+func checkTier(tier string) error {
+	if _, ok := apikeys.Tiers[tier]; !ok {
+		return fmt.Errorf("unknown tier %q", tier)
+	}
+	return nil
+}
+
+// apikeysCreate issues a key and prints its token, which cannot be displayed again
+func apikeysCreate(args []string) error {
+	var o options
+	flags := o.flags("apikeys create", true)
+	name := flags.String("name", "", "name of the consumer")
+	tier := flags.String("tier", apikeys.DefaultTier, "rate tier: free, partner or internal")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return usagef("apikeys create: missing -name")
+	}
+	store, err := o.openKeys()
+	if err != nil {
+		return err
+	}
+
+	if o.dryRun {
+		if err := checkTier(*tier); err != nil {
+			return err
+		}
+		fmt.Printf("Dry run, a key for %s in tier %s was not created\n", *name, *tier)
+		return nil
+	}
+	key, token, err := store.Create(*name, *tier)
+	if err != nil {
+		return fmt.Errorf("failed to create API key: %v", err)
+	}
+	fmt.Printf("Created key %s (%s, tier %s)\n", key.ID, key.Name, key.Tier)
+	fmt.Printf("API key: %s\n", token)
+	fmt.Println("Store it now, it cannot be displayed again.")
+	return nil
+}
+
+// apikeysList prints every key with its usage, as last flushed by the API
+func apikeysList(args []string) error {
+	var o options
+	flags := o.flags("apikeys list", false)
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return usagef("apikeys list: unexpected arguments %q", flags.Args())
+	}
+	store, err := o.openKeys()
+	if err != nil {
+		return err
+	}
+
+	keys := store.List()
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].RevokedAt == nil && keys[j].RevokedAt != nil })
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tTIER\tSTATUS\tTODAY\tTOTAL\tREJECTED\tLAST USED")
+	for _, key := range keys {
+		status := "active"
+		if key.RevokedAt != nil {
+			status = "revoked " + key.RevokedAt.Format("2006-01-02")
+		}
+
+		usage := store.Usage(key.ID)
+		lastUsed := "never"
+		if usage.LastUsedAt != nil {
+			lastUsed = usage.LastUsedAt.Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%s\n",
+			key.ID, key.Name, key.Tier, status, usage.Today, usage.Total, usage.Rejected, lastUsed)
+	}
+	return w.Flush()
+}
+
+// apikeysTier moves a key to another rate tier
+func apikeysTier(args []string) error {
+	var o options
+	flags := o.flags("apikeys tier", true)
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return usagef("apikeys tier: expected <id> <tier>")
+	}
+	id, tier := flags.Arg(0), flags.Arg(1)
+	store, err := o.openKeys()
+	if err != nil {
+		return err
+	}
+
+	if o.dryRun {
+		if err := checkTier(tier); err != nil {
+			return err
+		}
+		if _, err := findKey(store, id); err != nil {
+			return err
+		}
+		fmt.Printf("Dry run, key %s was not moved to tier %s\n", id, tier)
+		return nil
+	}
+	if err := store.SetTier(id, tier); err != nil {
+		return fmt.Errorf("failed to change tier: %v", err)
+	}
+	fmt.Printf("Key %s moved to tier %s\n", id, tier)
+	return nil
+}
+
+// apikeysRevoke disables a key
+func apikeysRevoke(args []string) error {
+	var o options
+	flags := o.flags("apikeys revoke", true)
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usagef("apikeys revoke: expected <id>")
+	}
+	id := flags.Arg(0)
+	store, err := o.openKeys()
+	if err != nil {
+		return err
+	}
+
+	if o.dryRun {
+		if _, err := findKey(store, id); err != nil {
+			return err
+		}
+		fmt.Printf("Dry run, key %s was not revoked\n", id)
+		return nil
+	}
+	if err := store.Revoke(id); err != nil {
+		return fmt.Errorf("failed to revoke API key: %v", err)
+	}
+	fmt.Printf("Key %s revoked\n", id)
+	return nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"tournois-tt/api/pkg/cache"
+)
+
+// cacheCommand runs the cache subcommands
+func cacheCommand(args []string) error {
+	return subcommand("cache", args, map[string]func([]string) error{
+		"stats":  cacheStats,
+		"export": cacheExport,
+		"import": cacheImport,
+		"diff":   cacheDiff,
+	})
+}
+
+// cacheStats prints statistics about the tournament cache
+func cacheStats(args []string) error {
+	var o options
+	if err := parse(o.flags("cache stats", false), args); err != nil {
+		return err
+	}
+	a, dir, cleanup, err := o.open()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	stats := a.Store.Stats()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Directory\t%s\n", dir)
+	fmt.Fprintf(w, "Tournaments\t%d\n", stats.Tournaments)
+	fmt.Fprintf(w, "Cancelled\t%d\n", stats.Cancelled)
+	fmt.Fprintf(w, "Failed geocoding\t%d\n", stats.FailedGeocoding)
+	fmt.Fprintf(w, "Missing coordinates\t%d\n", stats.MissingCoordinates)
+	fmt.Fprintf(w, "Purged\t%d\n", stats.Purged)
+	fmt.Fprintf(w, "File size\t%d bytes\n", stats.FileSize)
+	if stats.FileModified != nil {
+		fmt.Fprintf(w, "File modified\t%s\n", stats.FileModified.Format(time.RFC3339))
+	}
+	if stats.LastRefresh != nil {
+		fmt.Fprintf(w, "Last refresh\t%s\n", stats.LastRefresh.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+// cacheExport writes the tournaments to a file, or stdout
+func cacheExport(args []string) error {
+	var o options
+	flags := o.flags("cache export", true)
+	out := flags.String("out", "", "file to write, stdout by default")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	a, _, cleanup, err := o.open()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if *out == "" {
+		return cache.WriteTournaments(os.Stdout, a.Store.All())
+	}
+	if o.dryRun {
+		fmt.Fprintf(os.Stderr, "Dry run, %d tournaments would be exported to %s\n", a.Store.Size(), *out)
+		return nil
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := cache.WriteTournaments(file, a.Store.All()); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d tournaments exported to %s\n", a.Store.Size(), *out)
+	return nil
+}
+
+// cacheImport replaces the tournaments of the cache with those of an export
+func cacheImport(args []string) error {
+	var o options
+	flags := o.flags("cache import", true)
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usagef("cache import: expected the file to import")
+	}
+	imported, err := cache.ReadTournaments(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	a, dir, cleanup, err := o.openLocked()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	printChanges(cache.Diff(a.Store.All(), imported))
+	if o.dryRun {
+		fmt.Printf("Dry run, %s was not changed\n", dir)
+		return nil
+	}
+
+	a.Store.Replace(cache.Sorted(imported))
+	if err := a.Store.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("%d tournaments imported into %s\n", len(imported), dir)
+	return nil
+}
+
+// cacheDiff prints the changes from the cache to an export, or between two exports
+func cacheDiff(args []string) error {
+	var o options
+	flags := o.flags("cache diff", false)
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	var before, after map[string]cache.TournamentCache
+	var err error
+	switch flags.NArg() {
+	case 1:
+		a, _, cleanup, err := o.open()
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		before = a.Store.All()
+	case 2:
+		if before, err = cache.ReadTournaments(flags.Arg(0)); err != nil {
+			return err
+		}
+	default:
+		return usagef("cache diff: expected one export to compare to the cache, or two exports")
+	}
+	if after, err = cache.ReadTournaments(flags.Arg(flags.NArg() - 1)); err != nil {
+		return err
+	}
+
+	printChanges(cache.Diff(before, after))
+	return nil
+}
+
+// printChanges prints one line per changed tournament and a summary
+func printChanges(changes []cache.TournamentChange) {
+	counts := make(map[cache.ChangeKind]int)
+	for _, change := range changes {
+		counts[change.Kind]++
+		line := fmt.Sprintf("%-9s %6d  %s", change.Kind, change.After.ID, change.After.Name)
+		if change.Kind == cache.ChangeUpdated {
+			line += " (" + strings.Join(cache.ChangedFields(*change.Before, change.After), ", ") + ")"
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("%d created, %d updated, %d cancelled, %d removed\n", counts[cache.ChangeCreated],
+		counts[cache.ChangeUpdated], counts[cache.ChangeCancelled], counts[cache.ChangeRemoved])
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"tournois-tt/api/pkg/clubs"
+)
+
+// clubsCommand runs the clubs subcommands
+func clubsCommand(args []string) error {
+	return subcommand("clubs", args, map[string]func([]string) error{
+		"scrape": clubsScrape,
+	})
+}
+
+// clubsScrape prints the email addresses of clubs, or of leagues and committees, from the
+// FFTT directory, as email;department;region; lines
+func clubsScrape(args []string) error {
+	var o options
+	flags := o.flags("clubs scrape", true)
+	departments := flags.String("departments", "", "comma separated department codes, all by default")
+	structures := flags.Bool("structures", false, "scrape leagues and committees instead of clubs")
+	out := flags.String("out", "", "file to write, stdout by default")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if _, err := o.config(); err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" && o.dryRun {
+		w = io.Discard
+	} else if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	client := clubs.NewHTTPClient(10 * time.Second)
+	count := 0
+	found := func(contact clubs.Contact) {
+		count++
+		fmt.Fprintf(w, "%s;%s;%s;\n", contact.Email, contact.Department, contact.Region)
+	}
+
+	ctx, stop := interruptible()
+	defer stop()
+
+	var err error
+	if *structures {
+		err = clubs.ScrapeStructureContacts(ctx, client, found)
+	} else {
+		codes := clubs.Departments()
+		if *departments != "" {
+			codes = strings.Split(*departments, ",")
+		}
+		err = clubs.ScrapeClubContacts(ctx, client, codes, found)
+	}
+	fmt.Fprintf(os.Stderr, "%d contacts found\n", count)
+	if *out != "" && o.dryRun {
+		fmt.Fprintf(os.Stderr, "Dry run, %s was not written\n", *out)
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/image"
+)
+
+// imageCommand runs the image subcommands
+func imageCommand(args []string) error {
+	return subcommand("image", args, map[string]func([]string) error{
+		"render": imageRender,
+	})
+}
+
+// imageRender renders the feed and story images of a cached tournament
+func imageRender(args []string) error {
+	var o options
+	flags := o.flags("image render", true)
+	id := flags.Int("id", 0, "tournament to render")
+	kind := flags.String("kind", "both", "image to render: feed, story or both")
+	out := flags.String("out", "./instagram-images", "directory of the images")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if *id == 0 {
+		return usagef("image render: -id is required")
+	}
+
+	var kinds []string
+	switch *kind {
+	case image.KindFeed, image.KindStory:
+		kinds = []string{*kind}
+	case "both":
+		kinds = []string{image.KindFeed, image.KindStory}
+	default:
+		return usagef("image render: unknown kind %q", *kind)
+	}
+
+	a, _, cleanup, err := o.open()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	tournament, ok := a.Store.Get(*id)
+	if !ok {
+		return cache.ErrTournamentNotFound
+	}
+	data := cache.ImageData(tournament)
+
+	for _, kind := range kinds {
+		img, err := image.Render(data, kind)
+		if err != nil {
+			return err
+		}
+		filename := image.Filename(tournament.ID, kind, a.Now())
+		if o.dryRun {
+			fmt.Printf("Dry run, would write the %s image of tournament %d to %s\n", kind, tournament.ID, filepath.Join(*out, filename))
+			continue
+		}
+		path, err := image.WritePNG(img, *out, filename)
+		if err != nil {
+			return err
+		}
+		fmt.Println(path)
+	}
+	return nil
+}
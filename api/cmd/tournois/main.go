@@ -0,0 +1,270 @@
+// Command tournois runs the API and its operational tasks.
+//
+//	tournois serve
+//	tournois refresh [-dry-run]
+//	tournois regeocode [-id <id>] [-dry-run]
+//	tournois cache stats|export|import|diff
+//	tournois image render -id <id> [-kind feed|story] [-out <dir>] [-dry-run]
+//	tournois clubs scrape [-departments 35,56] [-structures] [-out <file>] [-dry-run]
+//	tournois newsletter digest [-days 14] [-send] [-dry-run]
+//	tournois apikeys create -name <name> [-tier free|partner|internal] [-dry-run]
+//	tournois apikeys list
+//	tournois apikeys tier [-dry-run] <id> <tier>
+//	tournois apikeys revoke [-dry-run] <id>
+//
+// Every command reads the configuration like the API, from the environment, .env and the
+// YAML file given by -config or CONFIG_FILE. Commands writing the tournament cache, files
+// or sending emails accept -dry-run, which reports what would be done instead. Commands
+// writing the tournament cache fail while another process, such as the API, writes it.
+//
+// The exit code is 0 on success, 1 when the command failed and 2 on invalid usage.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"tournois-tt/api/internal/app"
+	"tournois-tt/api/internal/config"
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/logging"
+)
+
+// Exit codes
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// command is a subcommand of tournois, run with the arguments following its name
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"serve", "serve the API and run the scheduled jobs", serve},
+	{"refresh", "refresh the tournaments of the last and current seasons", refresh},
+	{"regeocode", "geocode again tournaments without usable coordinates", regeocode},
+	{"cache", "inspect, export, import and compare the tournament cache", cacheCommand},
+	{"image", "render the Instagram images of a tournament", imageCommand},
+	{"clubs", "scrape the contacts of clubs from the FFTT directory", clubsCommand},
+	{"newsletter", "prepare and send the newsletter", newsletterCommand},
+	{"apikeys", "create, list, change the tier of and revoke API keys", apikeysCommand},
+}
+
+// usageError is returned for invalid arguments, exiting with exitUsage
+type usageError struct {
+	message string
+}
+
+func (e usageError) Error() string {
+	return e.message
+}
+
+// usagef returns a usageError
+func usagef(format string, args ...any) error {
+	return usageError{fmt.Sprintf(format, args...)}
+}
+
+// newDryRunApp builds the application of -dry-run, replaced by tests
+var newDryRunApp = app.NewDryRun
+
+// options are the flags shared by the commands
+type options struct {
+	configFile string
+	cacheDir   string
+	logLevel   string
+	dryRun     bool
+}
+
+// flags returns the flag set of a command with the shared flags. Only commands that write
+// have -dry-run.
+func (o *options) flags(name string, writes bool) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.StringVar(&o.configFile, "config", "", "YAML configuration file, CONFIG_FILE by default")
+	flags.StringVar(&o.cacheDir, "cache-dir", "", "directory of the tournament cache, api/cache by default")
+	flags.StringVar(&o.logLevel, "log-level", "", "log level: debug, info, warn or error")
+	if writes {
+		flags.BoolVar(&o.dryRun, "dry-run", false, "report what would be written without writing it")
+	}
+	return flags
+}
+
+// parse parses the arguments of a command, returning a usageError for invalid ones
+func parse(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError{err.Error()}
+	}
+	return nil
+}
+
+// config loads the configuration and sets up logging
+func (o *options) config() (*config.Config, error) {
+	cfg, err := config.Load(o.configFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%v", err)
+	}
+	if o.logLevel != "" {
+		cfg.Logging.Level = o.logLevel
+	}
+	if err := logging.Setup(cfg.Logging.Options()); err != nil {
+		return nil, fmt.Errorf("invalid logging configuration: %v", err)
+	}
+	return cfg, nil
+}
+
+// dir returns the cache directory given by -cache-dir, api/cache by default
+func (o *options) dir() string {
+	if o.cacheDir != "" {
+		return o.cacheDir
+	}
+	return cache.Dir()
+}
+
+// open loads the configuration and returns the application. With -dry-run, the application
+// works on a copy of the cache in a temporary directory, removed by cleanup, and dir is the
+// directory of the actual cache.
+func (o *options) open() (a *app.App, dir string, cleanup func(), err error) {
+	return o.openCache(false)
+}
+
+// openLocked is open for the commands writing the cache, which hold the lock of its
+// directory until cleanup unless they run with -dry-run
+func (o *options) openLocked() (a *app.App, dir string, cleanup func(), err error) {
+	return o.openCache(true)
+}
+
+func (o *options) openCache(lock bool) (a *app.App, dir string, cleanup func(), err error) {
+	cfg, err := o.config()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	dir = o.dir()
+	if !o.dryRun {
+		unlock := func() {}
+		if lock {
+			if unlock, err = cache.LockDir(dir); errors.Is(err, cache.ErrLocked) {
+				return nil, "", nil, fmt.Errorf("%v: stop the API first, or use its admin API to refresh and geocode tournaments", err)
+			} else if err != nil {
+				return nil, "", nil, err
+			}
+		}
+		if a, err = app.New(cfg, dir); err != nil {
+			unlock()
+			return nil, "", nil, err
+		}
+		return a, dir, unlock, nil
+	}
+
+	scratch, err := os.MkdirTemp("", "tournois-dry-run-")
+	if err != nil {
+		return nil, "", nil, err
+	}
+	cleanup = func() { os.RemoveAll(scratch) }
+	for _, name := range []string{"data.json", "purged.json", "refresh.json"} {
+		if err := copyFile(filepath.Join(dir, name), filepath.Join(scratch, name)); err != nil {
+			cleanup()
+			return nil, "", nil, err
+		}
+	}
+	if a, err = newDryRunApp(cfg, scratch); err != nil {
+		cleanup()
+		return nil, "", nil, err
+	}
+	return a, dir, cleanup, nil
+}
+
+// copyFile copies a file, if it exists
+func copyFile(from, to string) error {
+	src, err := os.Open(from)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// subcommand runs the subcommand named by the first argument
+func subcommand(name string, args []string, subcommands map[string]func([]string) error) error {
+	if len(args) == 0 {
+		return usagef("%s: missing subcommand", name)
+	}
+	run, ok := subcommands[args[0]]
+	if !ok {
+		return usagef("%s: unknown subcommand %q", name, args[0])
+	}
+	return run(args[1:])
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: tournois <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-11s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run tournois <command> -h for the arguments of a command.")
+}
+
+// run runs the command named by args[0] and returns the exit code
+func run(args []string) int {
+	if len(args) == 0 {
+		usage(os.Stderr)
+		return exitUsage
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(os.Stdout)
+		return exitOK
+	}
+
+	for _, c := range commands {
+		if c.name != args[0] {
+			continue
+		}
+
+		err := c.run(args[1:])
+		var invalid usageError
+		switch {
+		case err == nil, errors.Is(err, flag.ErrHelp):
+			return exitOK
+		case errors.As(err, &invalid):
+			fmt.Fprintf(os.Stderr, "%v\nRun tournois %s -h for usage.\n", err, c.name)
+			return exitUsage
+		default:
+			fmt.Fprintf(os.Stderr, "tournois %s: %v\n", c.name, err)
+			return exitFailure
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", args[0])
+	usage(os.Stderr)
+	return exitUsage
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
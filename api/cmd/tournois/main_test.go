@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tournois-tt/api/internal/app"
+	"tournois-tt/api/internal/config"
+	"tournois-tt/api/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCacheDir returns a cache directory holding one tournament
+func newCacheDir(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	store, err := cache.OpenStore(dir)
+	require.NoError(t, err)
+	store.Set(cache.TournamentCache{ID: 1, Name: "Open de Rennes", StartDate: "2025-10-04T00:00:00"})
+	require.NoError(t, store.Flush())
+	return dir
+}
+
+// writeExport writes an export of tournaments to a temporary file
+func writeExport(t *testing.T, tournaments ...cache.TournamentCache) string {
+	t.Helper()
+
+	byID := make(map[string]cache.TournamentCache)
+	for _, tournament := range tournaments {
+		byID[cache.GenerateTournamentCacheKey(tournament)] = tournament
+	}
+	path := filepath.Join(t.TempDir(), "export.json")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	defer file.Close()
+	require.NoError(t, cache.WriteTournaments(file, byID))
+	return path
+}
+
+func TestRunExitCodes(t *testing.T) {
+	dir := newCacheDir(t)
+	export := writeExport(t, cache.TournamentCache{ID: 2, Name: "Open de Vannes"})
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"no command", nil, exitUsage},
+		{"help", []string{"help"}, exitOK},
+		{"-h", []string{"-h"}, exitOK},
+		{"unknown command", []string{"deploy"}, exitUsage},
+		{"command help", []string{"refresh", "-h"}, exitOK},
+		{"unknown flag", []string{"refresh", "-force"}, exitUsage},
+		{"missing subcommand", []string{"cache"}, exitUsage},
+		{"unknown subcommand", []string{"cache", "drop"}, exitUsage},
+		{"missing argument", []string{"cache", "import", "-cache-dir", dir}, exitUsage},
+		{"read-only commands have no -dry-run", []string{"cache", "stats", "-dry-run"}, exitUsage},
+		{"stats", []string{"cache", "stats", "-cache-dir", dir}, exitOK},
+		{"diff", []string{"cache", "diff", "-cache-dir", dir, export}, exitOK},
+		{"missing export", []string{"cache", "import", "-cache-dir", dir, filepath.Join(dir, "missing.json")}, exitFailure},
+		{"apikeys without name", []string{"apikeys", "create", "-cache-dir", dir}, exitUsage},
+		{"apikeys tier without tier", []string{"apikeys", "tier", "-cache-dir", dir, "0123abcd"}, exitUsage},
+		{"apikeys list", []string{"apikeys", "list", "-cache-dir", dir}, exitOK},
+		{"apikeys create dry run", []string{"apikeys", "create", "-dry-run", "-cache-dir", dir, "-name", "Ligue"}, exitOK},
+		{"apikeys unknown tier", []string{"apikeys", "create", "-cache-dir", dir, "-name", "Ligue", "-tier", "gold"}, exitFailure},
+		{"apikeys unknown key", []string{"apikeys", "revoke", "-cache-dir", dir, "0123abcd"}, exitFailure},
+		{"missing configuration file", []string{"cache", "stats", "-config", filepath.Join(dir, "missing.yaml")}, exitFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, run(tt.args))
+		})
+	}
+}
+
+func TestDryRunLeavesTheCacheUntouched(t *testing.T) {
+	dir := newCacheDir(t)
+	before, err := os.ReadFile(filepath.Join(dir, "data.json"))
+	require.NoError(t, err)
+	export := writeExport(t, cache.TournamentCache{ID: 2, Name: "Open de Vannes"})
+	out := filepath.Join(t.TempDir(), "out.json")
+
+	assert.Equal(t, exitOK, run([]string{"cache", "import", "-dry-run", "-cache-dir", dir, export}))
+	assert.Equal(t, exitOK, run([]string{"cache", "export", "-dry-run", "-cache-dir", dir, "-out", out}))
+
+	after, err := os.ReadFile(filepath.Join(dir, "data.json"))
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+	assert.NoFileExists(t, out)
+}
+
+// snapshot returns the content of the files of dir by name
+func snapshot(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	files := make(map[string]string)
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		require.NoError(t, err)
+		files[entry.Name()] = string(data)
+	}
+	return files
+}
+
+// ffttClient answers every request with the tournaments of body
+type ffttClient struct {
+	body string
+}
+
+func (c ffttClient) GetTournaments(context.Context, url.Values) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(c.body))}, nil
+}
+
+func TestDryRunRefreshLeavesTheCacheUntouched(t *testing.T) {
+	newDryRunApp = func(cfg *config.Config, dir string) (*app.App, error) {
+		a, err := app.NewDryRun(cfg, dir)
+		if err == nil {
+			a.FFTT = ffttClient{`[{"id": 2, "name": "Open de Vannes", "startDate": "2026-10-04T00:00:00",
+				"address": {"postalCode": "56000", "addressLocality": "Vannes", "latitude": 47.65, "longitude": -2.76}}]`}
+		}
+		return a, err
+	}
+	defer func() { newDryRunApp = app.NewDryRun }()
+	dir := newCacheDir(t)
+	before := snapshot(t, dir)
+
+	assert.Equal(t, exitOK, run([]string{"refresh", "-dry-run", "-cache-dir", dir}))
+
+	assert.Equal(t, before, snapshot(t, dir))
+	assert.NoFileExists(t, filepath.Join(dir, "job_runs.json"), "the run is recorded in the copy of the cache")
+}
+
+func TestWritingCommandsFailWhileTheCacheIsLocked(t *testing.T) {
+	dir := newCacheDir(t)
+	unlock, err := cache.LockDir(dir)
+	require.NoError(t, err)
+	defer unlock()
+	export := writeExport(t, cache.TournamentCache{ID: 2, Name: "Open de Vannes"})
+
+	assert.Equal(t, exitFailure, run([]string{"cache", "import", "-cache-dir", dir, export}))
+	assert.Equal(t, exitOK, run([]string{"cache", "import", "-dry-run", "-cache-dir", dir, export}))
+	assert.Equal(t, exitOK, run([]string{"cache", "stats", "-cache-dir", dir}))
+
+	tournaments, err := cache.ReadTournaments(filepath.Join(dir, "data.json"))
+	require.NoError(t, err)
+	assert.Len(t, tournaments, 1)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"tournois-tt/api/internal/crons/campaigns"
+)
+
+// newsletterCommand runs the newsletter subcommands
+func newsletterCommand(args []string) error {
+	return subcommand("newsletter", args, map[string]func([]string) error{
+		"digest": newsletterDigest,
+	})
+}
+
+// newsletterDigest prints the upcoming tournaments and optionally sends the Brevo campaign
+func newsletterDigest(args []string) error {
+	var o options
+	flags := o.flags("newsletter digest", true)
+	days := flags.Int("days", 14, "days ahead covered by the digest")
+	send := flags.Bool("send", false, "send the Brevo campaign BREVO_CAMPAIGN_ID")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if *days <= 0 {
+		return usagef("newsletter digest: -days must be positive")
+	}
+	a, _, cleanup, err := o.open()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	digest := campaigns.Digest(a.Store.All(), a.Now(), *days)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, tournament := range digest {
+		fmt.Fprintf(w, "%.10s\t%s\t%s %s\t%d €\thttps://tournois-tt.fr/%d\n", tournament.StartDate, tournament.Name,
+			tournament.Address.PostalCode, tournament.Address.AddressLocality, tournament.Endowment, tournament.ID)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("%d tournaments in the next %d days\n", len(digest), *days)
+
+	if !*send {
+		return nil
+	}
+	if o.dryRun {
+		fmt.Printf("Dry run, campaign %d was not sent\n", a.Config.Brevo.CampaignID)
+		return nil
+	}
+	ctx, stop := interruptible()
+	defer stop()
+	return campaigns.Send(ctx, a)
+}
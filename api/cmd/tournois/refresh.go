@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"tournois-tt/api/internal/crons"
+	"tournois-tt/api/internal/crons/tournaments"
+	"tournois-tt/api/internal/crons/tournaments/geocoding"
+	"tournois-tt/api/pkg/cache"
+)
+
+// interruptible returns a context cancelled on SIGINT or SIGTERM. Interrupted jobs save the
+// work done so far.
+func interruptible() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// refresh runs the refresh job once
+func refresh(args []string) error {
+	var o options
+	if err := parse(o.flags("refresh", true), args); err != nil {
+		return err
+	}
+	a, dir, cleanup, err := o.openLocked()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, stop := interruptible()
+	defer stop()
+
+	before := a.Store.All()
+	job := crons.Job{
+		Name: crons.RefreshTournaments,
+		Run: func(ctx context.Context) error {
+			return tournaments.RefreshListWithGeocoding(ctx, a)
+		},
+	}
+	err = a.Jobs.Run(ctx, job)
+	if o.dryRun {
+		printChanges(cache.Diff(before, a.Store.All()))
+		fmt.Printf("Dry run, %s was not changed\n", dir)
+	}
+	return err
+}
+
+// regeocode geocodes again one tournament, or every tournament without usable coordinates
+func regeocode(args []string) error {
+	var o options
+	flags := o.flags("regeocode", true)
+	id := flags.Int("id", 0, "tournament to geocode again, all those without usable coordinates by default")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	a, dir, cleanup, err := o.openLocked()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, stop := interruptible()
+	defer stop()
+
+	before := a.Store.All()
+	if *id != 0 {
+		tournament, err := geocoding.Regeocode(ctx, a, *id)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Tournament %d geocoded at %f, %f (failed: %t)\n", tournament.ID,
+			tournament.Address.Latitude, tournament.Address.Longitude, tournament.Address.Failed)
+	} else {
+		succeeded, failed, err := geocoding.RegeocodeFailed(ctx, a)
+		fmt.Printf("%d tournaments geocoded, %d failed\n", succeeded, failed)
+		if err != nil {
+			return err
+		}
+	}
+
+	if o.dryRun {
+		printChanges(cache.Diff(before, a.Store.All()))
+		fmt.Printf("Dry run, %s was not changed\n", dir)
+	}
+	return ctx.Err()
+}
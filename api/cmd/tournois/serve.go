@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"tournois-tt/api/internal/config"
+	"tournois-tt/api/internal/crons"
+	"tournois-tt/api/internal/crons/tournaments"
+	"tournois-tt/api/internal/router"
+	"tournois-tt/api/pkg/alerting"
+	"tournois-tt/api/pkg/logging"
+)
+
+var logger = logging.For("main")
+
+// serve serves the API until SIGINT or SIGTERM, then shuts down gracefully
+func serve(args []string) error {
+	var o options
+	if err := parse(o.flags("serve", false), args); err != nil {
+		return err
+	}
+	a, _, cleanup, err := o.openLocked()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	cfg := a.Config
+
+	// ctx is cancelled on SIGINT or SIGTERM, sent by docker stop
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Background workers stop with ctx and are waited for before exiting
+	var workers sync.WaitGroup
+	background := func(run func()) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run()
+		}()
+	}
+
+	configureAlerting(a.Alerts, cfg)
+
+	background(func() { a.Limiters.SweepEvery(ctx, time.Minute) })
+	if a.Webhooks != nil {
+		background(func() { a.Webhooks.Run(ctx, a.Events) })
+	}
+	if a.APIKeys != nil {
+		background(func() { a.APIKeys.FlushUsage(ctx, time.Minute) })
+	}
+	if a.Tally != nil {
+		background(func() { a.Tally.FlushEvery(ctx, time.Minute) })
+	}
+
+	if err := a.Jobs.Add(tournaments.Job(a)); err != nil {
+		return err
+	}
+	a.Jobs.Schedule()
+	if err := a.Jobs.Trigger(crons.RefreshTournaments); err != nil {
+		logger.Error("Failed to start the initial refresh", "error", err)
+	}
+
+	server := &http.Server{
+		Addr:    cfg.Server.Addr,
+		Handler: router.NewRouter(a),
+	}
+	// Event streams never end on their own
+	server.RegisterOnShutdown(a.Events.Close)
+
+	servers := []*http.Server{server}
+	serverErr := make(chan error, 2)
+	go func() {
+		logger.Info("Server starting", "addr", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	if cfg.Admin.TLSAddr != "" {
+		adminServer, err := newAdminServer(cfg.Admin, server.Handler)
+		if err != nil {
+			return fmt.Errorf("invalid admin TLS configuration: %v", err)
+		}
+		servers = append(servers, adminServer)
+		go func() {
+			logger.Info("Admin TLS server starting", "addr", adminServer.Addr)
+			serverErr <- adminServer.ListenAndServeTLS(cfg.Admin.TLSCert, cfg.Admin.TLSKey)
+		}()
+	}
+
+	select {
+	case err := <-serverErr:
+		return fmt.Errorf("error starting server: %v", err)
+	case <-ctx.Done():
+	}
+
+	logger.Info("Shutting down", "timeout", cfg.Server.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	for _, s := range servers {
+		if err := s.Shutdown(shutdownCtx); err != nil {
+			logger.Warn("In-flight requests were cut", "addr", s.Addr, "error", err)
+		}
+		if err := <-serverErr; !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Server stopped with an error", "error", err)
+		}
+	}
+
+	if err := a.Jobs.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("Running cron jobs were interrupted", "error", err)
+	}
+
+	workers.Wait()
+
+	// Alerts in flight are bounded by the notification timeout
+	defer a.Alerts.Close()
+
+	if err := a.Store.Flush(); err != nil {
+		return fmt.Errorf("failed to flush the tournament cache: %v", err)
+	}
+
+	logger.Info("Shutdown complete")
+	return nil
+}
+
+// newAdminServer returns a server only accepting clients with a certificate signed by the
+// admin client CA
+func newAdminServer(admin config.AdminConfig, handler http.Handler) (*http.Server, error) {
+	pem, err := os.ReadFile(admin.ClientCA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read admin client CA: %v", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", admin.ClientCA)
+	}
+
+	return &http.Server{
+		Addr:    admin.TLSAddr,
+		Handler: handler,
+		TLSConfig: &tls.Config{
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  clientCAs,
+			MinVersion: tls.VersionTLS12,
+		},
+	}, nil
+}
+
+// configureAlerting registers the configured alert channels of alerts and routes each
+// severity to its channels
+func configureAlerting(d *alerting.Dispatcher, cfg *config.Config) {
+	alerts := cfg.Alerting
+	if alerts.WebhookURL != "" {
+		d.AddChannel("webhook", alerting.WebhookNotifier{URL: alerts.WebhookURL})
+	}
+	if cfg.Brevo.APIKey != "" && alerts.EmailFrom != "" && len(alerts.EmailTo) > 0 {
+		d.AddChannel("email", alerting.EmailNotifier{
+			APIKey: cfg.Brevo.APIKey,
+			Sender: alerts.EmailFrom,
+			To:     alerts.EmailTo,
+		})
+	}
+	if alerts.DiscordWebhookURL != "" {
+		d.AddChannel("discord", alerting.DiscordNotifier{WebhookURL: alerts.DiscordWebhookURL})
+	}
+	if alerts.TelegramBotToken != "" && alerts.TelegramChatID != "" {
+		d.AddChannel("telegram", alerting.TelegramNotifier{
+			BotToken: alerts.TelegramBotToken,
+			ChatID:   alerts.TelegramChatID,
+		})
+	}
+	d.SetLimits(alerts.RepeatInterval, alerts.MaxPerHour)
+	for severity, channels := range alerts.Channels.BySeverity() {
+		d.Route(alerting.Severity(severity), channels...)
+	}
+}
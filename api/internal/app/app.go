@@ -0,0 +1,138 @@
+// Package app holds the application: its configuration and the dependencies shared by the
+// router, the handlers and the jobs
+package app
+
+import (
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"tournois-tt/api/internal/config"
+	"tournois-tt/api/internal/crons"
+	"tournois-tt/api/pkg/alerting"
+	"tournois-tt/api/pkg/apikeys"
+	"tournois-tt/api/pkg/audit"
+	"tournois-tt/api/pkg/brevo"
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/degraded"
+	"tournois-tt/api/pkg/events"
+	"tournois-tt/api/pkg/fftt"
+	"tournois-tt/api/pkg/geocoding"
+	"tournois-tt/api/pkg/geocoding/providers"
+	"tournois-tt/api/pkg/logging"
+	"tournois-tt/api/pkg/ratelimit"
+	"tournois-tt/api/pkg/tally"
+	"tournois-tt/api/pkg/webhooks"
+)
+
+var logger = logging.For("app")
+
+// httpTimeout bounds the outgoing requests sent with App.HTTP
+const httpTimeout = 30 * time.Second
+
+// App owns the configuration, the stores persisted in its directory, the clients of
+// external services and the background jobs. It is passed explicitly to the router, the
+// handlers and the jobs, so that several applications can run side by side, as in tests.
+type App struct {
+	Config   *config.Config
+	Store    *cache.Store
+	FFTT     fftt.FFTTClientInterface
+	Geocoder geocoding.Geocoder
+	Brevo    *brevo.Client
+	// Events broadcasts the tournament changes detected by refreshes
+	Events *events.Bus
+	// HTTP sends the other outgoing requests, such as analytics events
+	HTTP *http.Client
+	// Now is the clock, replaced in tests
+	Now func() time.Time
+
+	// Alerts notifies the operators, through the logs until the channels are configured
+	Alerts *alerting.Dispatcher
+	// Breaker stops calling the FFTT API while it fails
+	Breaker *fftt.Breaker
+	// Degraded tracks whether the tournaments are stale because refreshes fail
+	Degraded *degraded.Machine
+	// Jobs runs the background jobs and keeps the history of their runs
+	Jobs *crons.Scheduler
+	// Audit records the admin actions
+	Audit *audit.Log
+	// Limiters holds the rate limiting buckets of the clients
+	Limiters *ratelimit.Store
+	// APIKeys, Webhooks and Tally are nil when their file cannot be loaded, disabling
+	// their routes
+	APIKeys  *apikeys.Store
+	Webhooks *webhooks.Manager
+	Tally    *tally.Store
+}
+
+// New returns the application configured by cfg, with its stores persisted in dir. Saving
+// refreshed tournaments updates the sitemap and the RSS feed.
+func New(cfg *config.Config, dir string) (*App, error) {
+	a, err := NewDryRun(cfg, dir)
+	if err != nil {
+		return nil, err
+	}
+	a.Store.ReportMetrics()
+	a.Breaker.ReportMetrics()
+	a.Store.OnSaved(func() { go cache.UpdateSitemap() })
+	return a, nil
+}
+
+// NewDryRun returns the application configured by cfg, with its stores persisted in dir,
+// usually a copy of the cache. It reports no metrics and saving its store leaves the sitemap
+// and the RSS feed alone.
+func NewDryRun(cfg *config.Config, dir string) (*App, error) {
+	store, err := cache.OpenStore(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := alerting.NewLogDispatcher()
+	geocoder, err := providers.Registry().Chain(geocoding.Strategy(cfg.Geocoding.Strategy),
+		cfg.Geocoding.Providers, cfg.Geocoding.ProviderSettings(), geocoding.NewMonitor(alerts))
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := crons.New(dir, alerts)
+	if err != nil {
+		return nil, err
+	}
+
+	breaker := fftt.NewAlertingBreaker(alerts)
+	httpClient := &http.Client{Timeout: httpTimeout}
+	a := &App{
+		Config: cfg,
+		Store:  store,
+		// FFTT requests for a whole season can be slow, they are bounded by the job timeout
+		FFTT:     fftt.NewClient(&http.Client{}, breaker),
+		Geocoder: geocoder,
+		Brevo:    brevo.NewClient(cfg.Brevo.APIKey, httpClient),
+		Events:   events.NewBus(events.DefaultReplaySize),
+		HTTP:     httpClient,
+		Now:      time.Now,
+		Alerts:   alerts,
+		Breaker:  breaker,
+		Degraded: degraded.New(degraded.Policy{
+			FailuresBeforeDegraded: cfg.Refresh.DegradedAfterFailures,
+			EscalateAfter:          cfg.Refresh.DegradedEscalateAfter,
+		}, alerts.Fire),
+		Jobs:     jobs,
+		Audit:    audit.NewLog(filepath.Join(dir, "audit.log")),
+		Limiters: ratelimit.NewStore(ratelimit.DefaultShards, ratelimit.DefaultMaxEntries, ratelimit.DefaultIdleTTL),
+	}
+	store.SetClock(func() time.Time { return a.Now() })
+	store.SetAlerts(alerts)
+	store.OnTournamentsChanged(a.Events.PublishChanges)
+
+	if a.APIKeys, err = apikeys.NewStore(dir); err != nil {
+		logger.Warn("API keys disabled", "error", err)
+	}
+	if a.Webhooks, err = webhooks.NewManager(filepath.Join(dir, "webhooks.json"), webhooks.NewDeliveryClient()); err != nil {
+		logger.Warn("Webhooks disabled", "error", err)
+	}
+	if a.Tally, err = tally.NewStore(filepath.Join(dir, "tally.json")); err != nil {
+		logger.Warn("Dashboard counters disabled", "error", err)
+	}
+	return a, nil
+}
@@ -1,61 +1,296 @@
 package config
 
 import (
-	"os"
-	"strconv"
+	"fmt"
+	"time"
 
-	"github.com/joho/godotenv"
+	"tournois-tt/api/pkg/geocoding"
+	"tournois-tt/api/pkg/logging"
+
+	"gopkg.in/yaml.v3"
 )
 
-var FrontendURL = "http://frontend:3000"
+// Config is the application configuration. Each setting is read, by increasing priority,
+// from its default, the YAML file named by CONFIG_FILE, and its environment variable.
+// Secrets can also be read from a file named by the variable suffixed with _FILE, for
+// Docker secrets.
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Logging   LoggingConfig   `yaml:"logging"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Readiness ReadinessConfig `yaml:"readiness"`
+	Refresh   RefreshConfig   `yaml:"refresh"`
+	Alerting  AlertingConfig  `yaml:"alerting"`
+	Admin     AdminConfig     `yaml:"admin"`
+	Brevo     BrevoConfig     `yaml:"brevo"`
+	Analytics AnalyticsConfig `yaml:"analytics"`
+	Geocoding GeocodingConfig `yaml:"geocoding"`
 
-// Instagram configuration
-var (
-	InstagramEnabled     bool
-	InstagramAccessToken string
-	InstagramPageID      string
-	InstagramAppID       string
-	InstagramAppSecret   string
-)
+	// file is the YAML file the configuration was read from
+	file string
+	// sources tells where each setting, by environment variable, was read from
+	sources map[string]string
+}
 
-// Imgur configuration
-var (
-	ImgurClientID string
-)
+// ServerConfig configures the HTTP server
+type ServerConfig struct {
+	Addr        string `yaml:"addr" env:"HTTP_ADDR"`
+	FrontendURL string `yaml:"frontend_url" env:"FRONTEND_URL"`
+	// ShutdownTimeout bounds how long the API waits for in-flight requests and running
+	// jobs when stopping. It must stay below the container stop timeout.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	// LogPrivacy is how client IPs and User-Agents are logged: hash, truncate or off
+	LogPrivacy string `yaml:"log_privacy" env:"LOG_PRIVACY"`
+	// MetricsToken is the bearer token required to read /metrics, which is disabled when
+	// it is empty
+	MetricsToken string `yaml:"metrics_token" env:"METRICS_TOKEN" secret:"true"`
+}
 
-// Threads configuration
-var (
-	ThreadsEnabled     bool
-	ThreadsAccessToken string
-	ThreadsUserID      string
-)
+// LoggingConfig configures the logs: json or text format (json by default when
+// GIN_MODE=release), default and per package levels, and optional daily files in Dir
+type LoggingConfig struct {
+	Format        string `yaml:"format" env:"LOG_FORMAT"`
+	Level         string `yaml:"level" env:"LOG_LEVEL"`
+	Levels        string `yaml:"levels" env:"LOG_LEVELS"`
+	Dir           string `yaml:"dir" env:"LOG_DIR"`
+	RetentionDays int    `yaml:"retention_days" env:"LOG_RETENTION_DAYS"`
+}
 
-// Instagram Bot configuration
-var (
-	InstagramBotEnabled bool
-)
+// Options returns the logging options
+func (l LoggingConfig) Options() logging.Options {
+	return logging.Options{
+		Format:        l.Format,
+		Level:         l.Level,
+		Levels:        l.Levels,
+		Dir:           l.Dir,
+		RetentionDays: l.RetentionDays,
+	}
+}
 
-func init() {
-	if err := godotenv.Load("../.env"); err != nil {
-		godotenv.Load("./.env")
+// RateLimitConfig limits anonymous clients, per IP. Allowlist lists the IP addresses and
+// CIDR ranges of internal callers that are not rate limited.
+type RateLimitConfig struct {
+	RequestsPerMinute int      `yaml:"requests_per_minute" env:"RATE_LIMIT_PER_MINUTE"`
+	Burst             int      `yaml:"burst" env:"RATE_LIMIT_BURST"`
+	Allowlist         []string `yaml:"allowlist" env:"RATE_LIMIT_ALLOWLIST"`
+}
+
+// ReadinessConfig holds the thresholds on the age of the last current season refresh:
+// older data makes /v1/readyz report degraded, then down
+type ReadinessConfig struct {
+	DegradedAfter time.Duration `yaml:"degraded_after" env:"READY_DEGRADED_AFTER"`
+	DownAfter     time.Duration `yaml:"down_after" env:"READY_DOWN_AFTER"`
+}
+
+// RefreshConfig schedules the tournaments refresh. A run is cancelled after Timeout and
+// starts up to Jitter after its schedule so that restarts don't hit the FFTT API in sync.
+// Degraded mode starts after DegradedAfterFailures consecutive failed refreshes, and
+// escalates to critical alerts after DegradedEscalateAfter.
+type RefreshConfig struct {
+	Schedule              string        `yaml:"schedule" env:"REFRESH_SCHEDULE"`
+	Timeout               time.Duration `yaml:"timeout" env:"REFRESH_TIMEOUT"`
+	Jitter                time.Duration `yaml:"jitter" env:"REFRESH_JITTER"`
+	DegradedAfterFailures int           `yaml:"degraded_after_failures" env:"DEGRADED_AFTER_FAILURES"`
+	DegradedEscalateAfter time.Duration `yaml:"degraded_escalate_after" env:"DEGRADED_ESCALATE_AFTER"`
+}
+
+// AlertingConfig configures the alert channels. An unresolved alert is not repeated
+// before RepeatInterval, and at most MaxPerHour alerts are sent.
+type AlertingConfig struct {
+	WebhookURL        string        `yaml:"webhook_url" env:"ALERT_WEBHOOK_URL" secret:"true"`
+	EmailFrom         string        `yaml:"email_from" env:"ALERT_EMAIL_FROM"`
+	EmailTo           []string      `yaml:"email_to" env:"ALERT_EMAIL_TO"`
+	DiscordWebhookURL string        `yaml:"discord_webhook_url" env:"ALERT_DISCORD_WEBHOOK_URL" secret:"true"`
+	TelegramBotToken  string        `yaml:"telegram_bot_token" env:"ALERT_TELEGRAM_BOT_TOKEN" secret:"true"`
+	TelegramChatID    string        `yaml:"telegram_chat_id" env:"ALERT_TELEGRAM_CHAT_ID"`
+	RepeatInterval    time.Duration `yaml:"repeat_interval" env:"ALERT_REPEAT_INTERVAL"`
+	MaxPerHour        int           `yaml:"max_per_hour" env:"ALERT_MAX_PER_HOUR"`
+	Channels          AlertChannels `yaml:"channels"`
+}
+
+// AlertChannels lists the channels notified for each severity, among log, webhook, email,
+// discord and telegram
+type AlertChannels struct {
+	Info     []string `yaml:"info" env:"ALERT_CHANNELS_INFO"`
+	Warning  []string `yaml:"warning" env:"ALERT_CHANNELS_WARNING"`
+	Critical []string `yaml:"critical" env:"ALERT_CHANNELS_CRITICAL"`
+}
+
+// BySeverity returns the channels of each severity
+func (a AlertChannels) BySeverity() map[string][]string {
+	return map[string][]string{
+		"info":     a.Info,
+		"warning":  a.Warning,
+		"critical": a.Critical,
 	}
+}
 
-	// Load Instagram configuration
-	InstagramEnabled, _ = strconv.ParseBool(os.Getenv("INSTAGRAM_ENABLED"))
-	InstagramAccessToken = os.Getenv("INSTAGRAM_ACCESS_TOKEN")
-	InstagramPageID = os.Getenv("INSTAGRAM_PAGE_ID")
-	InstagramAppID = os.Getenv("INSTAGRAM_APP_ID")
-	InstagramAppSecret = os.Getenv("INSTAGRAM_APP_SECRET")
+// AdminConfig gives access to the admin API with a bearer token, and/or a TLS listener
+// requiring client certificates signed by ClientCA
+type AdminConfig struct {
+	Token    string `yaml:"token" env:"ADMIN_TOKEN" secret:"true"`
+	TLSAddr  string `yaml:"tls_addr" env:"ADMIN_TLS_ADDR"`
+	TLSCert  string `yaml:"tls_cert" env:"ADMIN_TLS_CERT"`
+	TLSKey   string `yaml:"tls_key" env:"ADMIN_TLS_KEY"`
+	ClientCA string `yaml:"client_ca" env:"ADMIN_CLIENT_CA"`
+}
+
+// BrevoConfig configures the newsletter, sent with Brevo
+type BrevoConfig struct {
+	APIKey           string `yaml:"api_key" env:"BREVO_API_KEY" secret:"true"`
+	NewsletterListID int    `yaml:"newsletter_list_id" env:"BREVO_NEWSLETTER_LIST_ID"`
+	CampaignID       int    `yaml:"campaign_id" env:"BREVO_CAMPAIGN_ID"`
+}
+
+// AnalyticsConfig enables tracking rules redirects with the GA4 Measurement Protocol
+type AnalyticsConfig struct {
+	MeasurementID string `yaml:"measurement_id" env:"GA_MEASUREMENT_ID"`
+	APISecret     string `yaml:"api_secret" env:"GA_API_SECRET" secret:"true"`
+}
+
+// GeocodingConfig selects the geocoding providers (nominatim, google and ban, the Base
+// Adresse Nationale), tried in the order of Providers, and how their locations are
+// combined: first-success or best-precision
+type GeocodingConfig struct {
+	Providers []string `yaml:"providers" env:"GEOCODING_PROVIDERS"`
+	Strategy  string   `yaml:"strategy" env:"GEOCODING_STRATEGY"`
+	// Policies are keyed by provider name, set by GEOCODING_<NAME>_* variables
+	Policies GeocodingPolicies `yaml:"policies" envprefix:"GEOCODING_"`
+}
+
+// GeocodingProviderConfig is the policy of a geocoding provider. Interval spaces its
+// requests, a DailyQuota of 0 is unlimited, and providers scoring their results reject
+// those below MinScore.
+type GeocodingProviderConfig struct {
+	Enabled    bool          `yaml:"enabled" env:"ENABLED"`
+	URL        string        `yaml:"url" env:"URL"`
+	APIKey     string        `yaml:"api_key" env:"API_KEY" secret:"true"`
+	Interval   time.Duration `yaml:"interval" env:"INTERVAL"`
+	Timeout    time.Duration `yaml:"timeout" env:"TIMEOUT"`
+	Retries    int           `yaml:"retries" env:"RETRIES"`
+	RetryDelay time.Duration `yaml:"retry_delay" env:"RETRY_DELAY"`
+	DailyQuota int           `yaml:"daily_quota" env:"DAILY_QUOTA"`
+	MinScore   float64       `yaml:"min_score" env:"MIN_SCORE"`
+}
+
+// GeocodingPolicies are the policies of the geocoding providers by name. A provider
+// without a policy is disabled.
+type GeocodingPolicies map[string]*GeocodingProviderConfig
 
-	// Load Imgur configuration
-	ImgurClientID = os.Getenv("IMGUR_CLIENT_ID")
+// UnmarshalYAML reads the policies of a file over the current ones, so that a policy only
+// changes the settings it lists
+func (p *GeocodingPolicies) UnmarshalYAML(node *yaml.Node) error {
+	var nodes map[string]yaml.Node
+	if err := node.Decode(&nodes); err != nil {
+		return err
+	}
+	if *p == nil {
+		*p = make(GeocodingPolicies, len(nodes))
+	}
+	for name, value := range nodes {
+		var policy GeocodingProviderConfig
+		if current := (*p)[name]; current != nil {
+			policy = *current
+		}
+		if err := decodeKnownFields(&value, &policy); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		(*p)[name] = &policy
+	}
+	return nil
+}
+
+// Policy returns the geocoding policy
+func (p GeocodingProviderConfig) Policy() geocoding.Policy {
+	return geocoding.Policy{
+		Enabled:    p.Enabled,
+		Interval:   p.Interval,
+		Timeout:    p.Timeout,
+		Retries:    p.Retries,
+		RetryDelay: p.RetryDelay,
+		DailyQuota: p.DailyQuota,
+	}
+}
 
-	// Load Threads configuration
-	ThreadsEnabled, _ = strconv.ParseBool(os.Getenv("THREADS_ENABLED"))
-	ThreadsAccessToken = os.Getenv("THREADS_ACCESS_TOKEN")
-	ThreadsUserID = os.Getenv("THREADS_USER_ID")
+// ProviderSettings returns the settings of the geocoding providers by name
+func (g GeocodingConfig) ProviderSettings() map[string]geocoding.Settings {
+	settings := make(map[string]geocoding.Settings, len(g.Policies))
+	for name, p := range g.Policies {
+		settings[name] = geocoding.Settings{Policy: p.Policy(), URL: p.URL, APIKey: p.APIKey, MinScore: p.MinScore}
+	}
+	return settings
+}
 
-	// Load Instagram Bot configuration
-	// Default to false if not set (safe default)
-	InstagramBotEnabled, _ = strconv.ParseBool(os.Getenv("INSTAGRAM_BOT_ENABLED"))
+// Defaults returns the default configuration
+func Defaults() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:            ":8080",
+			FrontendURL:     "http://frontend:3000",
+			ShutdownTimeout: 25 * time.Second,
+			LogPrivacy:      "hash",
+		},
+		Logging: LoggingConfig{
+			Level:         "info",
+			RetentionDays: 30,
+		},
+		RateLimit: RateLimitConfig{
+			RequestsPerMinute: 45,
+			Burst:             5,
+		},
+		Readiness: ReadinessConfig{
+			DegradedAfter: 30 * time.Minute,
+			DownAfter:     6 * time.Hour,
+		},
+		Refresh: RefreshConfig{
+			Schedule:              "*/5 * * * *",
+			Timeout:               30 * time.Minute,
+			Jitter:                30 * time.Second,
+			DegradedAfterFailures: 2,
+			DegradedEscalateAfter: time.Hour,
+		},
+		Alerting: AlertingConfig{
+			RepeatInterval: time.Hour,
+			MaxPerHour:     30,
+			Channels: AlertChannels{
+				Info:     []string{"log"},
+				Warning:  []string{"log"},
+				Critical: []string{"log"},
+			},
+		},
+		Brevo: BrevoConfig{
+			NewsletterListID: 11,
+		},
+		Geocoding: GeocodingConfig{
+			Providers: []string{"nominatim", "google"},
+			Strategy:  "first-success",
+			Policies: GeocodingPolicies{
+				// The Nominatim usage policy allows one request per second
+				"nominatim": {
+					Enabled:    true,
+					Interval:   1500 * time.Millisecond,
+					Timeout:    10 * time.Second,
+					Retries:    2,
+					RetryDelay: 5 * time.Second,
+				},
+				"google": {
+					Enabled:    true,
+					Timeout:    10 * time.Second,
+					Retries:    1,
+					RetryDelay: 2 * time.Second,
+					DailyQuota: 1000,
+				},
+				// The BAN allows 50 requests per second and IP. Its results scoring less than
+				// 0.5 are usually another street or city.
+				"ban": {
+					Enabled:    true,
+					Interval:   50 * time.Millisecond,
+					Timeout:    10 * time.Second,
+					Retries:    2,
+					RetryDelay: time.Second,
+					MinScore:   0.5,
+				},
+			},
+		},
+	}
 }
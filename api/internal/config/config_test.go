@@ -0,0 +1,183 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultsAreValid(t *testing.T) {
+	assert.NoError(t, Defaults().Validate())
+}
+
+func TestLoadLayersFileAndEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+server:
+  frontend_url: https://tournois-tt.fr
+refresh:
+  timeout: 10m
+  jitter: 5s
+rate_limit:
+  allowlist: [10.0.0.0/8]
+`), 0o600))
+	secret := filepath.Join(dir, "brevo")
+	require.NoError(t, os.WriteFile(secret, []byte("brevo-secret\n"), 0o600))
+
+	t.Setenv("REFRESH_TIMEOUT", "20m")
+	t.Setenv("ALERT_EMAIL_TO", "ops@example.org, dev@example.org")
+	t.Setenv("BREVO_API_KEY_FILE", secret)
+
+	c, err := Load(file)
+	require.NoError(t, err)
+	assert.Equal(t, "https://tournois-tt.fr", c.Server.FrontendURL)
+	assert.Equal(t, 20*time.Minute, c.Refresh.Timeout, "the environment overrides the file")
+	assert.Equal(t, 5*time.Second, c.Refresh.Jitter)
+	assert.Equal(t, []string{"10.0.0.0/8"}, c.RateLimit.Allowlist)
+	assert.Equal(t, []string{"ops@example.org", "dev@example.org"}, c.Alerting.EmailTo)
+	assert.Equal(t, "brevo-secret", c.Brevo.APIKey)
+	assert.Equal(t, 11, c.Brevo.NewsletterListID)
+	assert.Equal(t, file, c.File())
+
+	sources := make(map[string]Setting)
+	for _, setting := range c.Settings() {
+		sources[setting.Env] = setting
+	}
+	assert.Equal(t, SourceFile, sources["FRONTEND_URL"].Source)
+	assert.Equal(t, SourceEnv, sources["REFRESH_TIMEOUT"].Source)
+	assert.Equal(t, SourceSecretFile, sources["BREVO_API_KEY"].Source)
+	assert.Equal(t, SourceDefault, sources["READY_DOWN_AFTER"].Source)
+	assert.Equal(t, "[redacted]", sources["BREVO_API_KEY"].Value)
+	assert.Equal(t, "20m0s", sources["REFRESH_TIMEOUT"].Value)
+}
+
+func TestEmptyVariablesClearSettings(t *testing.T) {
+	require.NotEmpty(t, Defaults().Alerting.Channels.Info)
+
+	t.Setenv("ALERT_CHANNELS_INFO", "")
+	c, err := Load("")
+	require.NoError(t, err)
+	assert.Empty(t, c.Alerting.Channels.Info)
+	assert.Equal(t, Defaults().Alerting.Channels.Warning, c.Alerting.Channels.Warning, "unset variables keep their default")
+}
+
+func TestLoadReadsRenamedVariables(t *testing.T) {
+	t.Setenv("GEOCODING_GOOGLE_API_KEY", "")
+	t.Setenv("GOOGLE_GEOCODING_API_KEY", "former")
+
+	c, err := Load("")
+	require.NoError(t, err)
+	assert.Equal(t, "former", c.Geocoding.Policies["google"].APIKey)
+
+	t.Setenv("GEOCODING_GOOGLE_API_KEY", "key")
+	c, err = Load("")
+	require.NoError(t, err)
+	assert.Equal(t, "key", c.Geocoding.Policies["google"].APIKey, "the new variable takes precedence")
+}
+
+func TestLoadRejectsInvalidSettings(t *testing.T) {
+	dir := t.TempDir()
+
+	file := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("refresh:\n  timout: 10m\n"), 0o600))
+	_, err := Load(file)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timout", "unknown keys are reported")
+
+	t.Setenv("REFRESH_TIMEOUT", "soon")
+	_, err = Load("")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "REFRESH_TIMEOUT")
+
+	os.Unsetenv("REFRESH_TIMEOUT")
+	t.Setenv("ADMIN_TOKEN", "token")
+	t.Setenv("ADMIN_TOKEN_FILE", filepath.Join(dir, "token"))
+	_, err = Load("")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "both ADMIN_TOKEN and ADMIN_TOKEN_FILE are set")
+
+	t.Setenv("ADMIN_TOKEN_FILE", "")
+	t.Setenv("REFRESH_SCHEDULE", "every minute")
+	t.Setenv("ALERT_CHANNELS_CRITICAL", "log,telegram,pager")
+	t.Setenv("READY_DOWN_AFTER", "10m")
+	_, err = Load("")
+	require.Error(t, err)
+	for _, expected := range []string{
+		"REFRESH_SCHEDULE: invalid cron spec",
+		`ALERT_CHANNELS_CRITICAL: channel "telegram" is not configured`,
+		`ALERT_CHANNELS_CRITICAL: unknown channel "pager"`,
+		"READY_DOWN_AFTER: must be longer than READY_DEGRADED_AFTER",
+	} {
+		assert.Contains(t, err.Error(), expected)
+	}
+}
+
+func TestLoadGeocodingProviders(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+geocoding:
+  strategy: best-precision
+  policies:
+    google:
+      enabled: false
+`), 0o600))
+
+	t.Setenv("GEOCODING_PROVIDERS", "ban,google,nominatim")
+	t.Setenv("GEOCODING_BAN_URL", "http://addok:7878")
+	t.Setenv("GEOCODING_NOMINATIM_INTERVAL", "2s")
+	t.Setenv("GEOCODING_GOOGLE_DAILY_QUOTA", "50")
+	t.Setenv("GEOCODING_GOOGLE_API_KEY", "key")
+
+	c, err := Load(file)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ban", "google", "nominatim"}, c.Geocoding.Providers)
+	assert.Equal(t, "best-precision", c.Geocoding.Strategy)
+	assert.Equal(t, 2*time.Second, c.Geocoding.Policies["nominatim"].Interval)
+	assert.Equal(t, 10*time.Second, c.Geocoding.Policies["nominatim"].Timeout)
+	assert.False(t, c.Geocoding.Policies["google"].Enabled)
+	assert.Equal(t, 10*time.Second, c.Geocoding.Policies["google"].Timeout, "the file only changes the settings it lists")
+	assert.Equal(t, 50, c.Geocoding.Policies["google"].DailyQuota)
+
+	settings := c.Geocoding.ProviderSettings()
+	assert.Equal(t, 2*time.Second, settings["nominatim"].Policy.Interval)
+	assert.False(t, settings["google"].Policy.Enabled)
+	assert.Equal(t, "key", settings["google"].APIKey)
+	assert.Equal(t, "http://addok:7878", settings["ban"].URL)
+	assert.True(t, settings["ban"].Policy.Enabled)
+	assert.Equal(t, 0.5, settings["ban"].MinScore)
+
+	t.Setenv("GEOCODING_PROVIDERS", "nominatim,here")
+	t.Setenv("GEOCODING_GOOGLE_TIMEOUT", "0s")
+	t.Setenv("GEOCODING_BAN_MIN_SCORE", "2")
+	_, err = Load(file)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `GEOCODING_PROVIDERS: unknown provider "here"`)
+	assert.Contains(t, err.Error(), "GEOCODING_GOOGLE_TIMEOUT: must be a positive duration")
+	assert.Contains(t, err.Error(), "GEOCODING_BAN_MIN_SCORE: must be between 0 and 1")
+
+	require.NoError(t, os.WriteFile(file, []byte(`
+geocoding:
+  policies:
+    here:
+      enabled: true
+`), 0o600))
+	t.Setenv("GEOCODING_PROVIDERS", "nominatim")
+	os.Unsetenv("GEOCODING_GOOGLE_TIMEOUT")
+	_, err = Load(file)
+	assert.ErrorContains(t, err, `GEOCODING_HERE_*: unknown provider "here"`)
+
+	require.NoError(t, os.WriteFile(file, []byte(`
+geocoding:
+  policies:
+    google:
+      enable: true
+`), 0o600))
+	_, err = Load(file)
+	assert.ErrorContains(t, err, "field enable not found")
+}
@@ -0,0 +1,302 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"tournois-tt/api/pkg/logging"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Where a setting was read from
+const (
+	SourceDefault    = "default"
+	SourceFile       = "file"
+	SourceEnv        = "env"
+	SourceSecretFile = "secret_file"
+)
+
+// redacted replaces the value of secrets in Settings
+const redacted = "[redacted]"
+
+// renamed maps environment variables to their former names, still read when the new ones
+// are unset or empty
+var renamed = map[string]string{
+	"GEOCODING_GOOGLE_API_KEY": "GOOGLE_GEOCODING_API_KEY",
+}
+
+var logger = logging.For("config")
+
+// Setting is a configuration setting, as listed by Settings
+type Setting struct {
+	Env    string `json:"env"`
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	Secret bool   `json:"secret,omitempty"`
+}
+
+// field is a setting of a Config
+type field struct {
+	env    string
+	key    string
+	secret bool
+	value  reflect.Value
+}
+
+// Load reads the configuration from .env, the YAML file at path, or at CONFIG_FILE when
+// path is empty, and the environment, then validates it. Empty environment variables are
+// ignored.
+func Load(path string) (*Config, error) {
+	// Variables already set take precedence over .env
+	if err := godotenv.Load("../.env"); err != nil {
+		godotenv.Load("./.env")
+	}
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+
+	c := Defaults()
+	c.file = path
+	c.sources = make(map[string]string)
+	if path != "" {
+		if err := c.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	for _, f := range c.fields() {
+		raw, source, ok, err := lookup(f.env)
+		if former, found := renamed[f.env]; found && err == nil && raw == "" {
+			// The new variable may be left empty, as in .env.example
+			if value, formerSource, formerOK, formerErr := lookup(former); formerErr != nil || value != "" {
+				raw, source, ok, err = value, formerSource, formerOK, formerErr
+				logger.Warn("Deprecated environment variable, rename it", "variable", former, "new_name", f.env)
+			}
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if err := set(f.value, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", f.env, err))
+			continue
+		}
+		c.sources[f.env] = source
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	if c.Logging.Format == "" {
+		c.Logging.Format = "text"
+		if os.Getenv("GIN_MODE") == "release" {
+			c.Logging.Format = "json"
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// readFile reads the settings of the YAML file at path, rejecting unknown keys
+func (c *Config) readFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+
+	// The file can add settings, such as the policy of a provider without defaults
+	defaults := make(map[string]reflect.Value)
+	for _, f := range Defaults().fields() {
+		defaults[f.env] = f.value
+	}
+	for _, f := range c.fields() {
+		if d, ok := defaults[f.env]; !ok || !reflect.DeepEqual(f.value.Interface(), d.Interface()) {
+			c.sources[f.env] = SourceFile
+		}
+	}
+	return nil
+}
+
+// decodeKnownFields decodes node into v, rejecting unknown keys like the file decoder
+func decodeKnownFields(node *yaml.Node, v any) error {
+	content, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	return decoder.Decode(v)
+}
+
+// lookup returns the value of an environment variable, or the content of the file named
+// by the variable suffixed with _FILE. ok is false when neither is set: a variable set to
+// an empty value clears the setting.
+func lookup(env string) (value, source string, ok bool, err error) {
+	value, ok = os.LookupEnv(env)
+	path := os.Getenv(env + "_FILE")
+	if path == "" {
+		return value, SourceEnv, ok, nil
+	}
+	if value != "" {
+		return "", "", false, fmt.Errorf("%s: both %s and %s_FILE are set", env, env, env)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", false, fmt.Errorf("%s_FILE: %v", env, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), SourceSecretFile, true, nil
+}
+
+// fields returns the settings of the configuration, in declaration order. The environment
+// variables of a nested struct are prefixed with its envprefix tag, allowing a struct to be
+// reused for several settings groups. Those of a map of structs are prefixed with its
+// envprefix tag and the key, in key order.
+func (c *Config) fields() []field {
+	var fields []field
+	var walk func(v reflect.Value, prefix, envPrefix string)
+	walk = func(v reflect.Value, prefix, envPrefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			key := prefix + sf.Tag.Get("yaml")
+			if env := sf.Tag.Get("env"); env != "" {
+				fields = append(fields, field{
+					env:    envPrefix + env,
+					key:    key,
+					secret: sf.Tag.Get("secret") == "true",
+					value:  v.Field(i),
+				})
+			} else if sf.Type.Kind() == reflect.Struct {
+				walk(v.Field(i), key+".", envPrefix+sf.Tag.Get("envprefix"))
+			} else if sf.Type.Kind() == reflect.Map {
+				m := v.Field(i)
+				keys := m.MapKeys()
+				sort.Slice(keys, func(a, b int) bool { return keys[a].String() < keys[b].String() })
+				for _, k := range keys {
+					walk(m.MapIndex(k).Elem(), key+"."+k.String()+".",
+						envPrefix+sf.Tag.Get("envprefix")+strings.ToUpper(k.String())+"_")
+				}
+			}
+		}
+	}
+	walk(reflect.ValueOf(c).Elem(), "", "")
+	return fields
+}
+
+// set parses raw into a setting, the zero value when raw is empty
+func set(v reflect.Value, raw string) error {
+	if raw == "" {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		// Comma separated list
+		var values []string
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		v.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// format returns a setting as it would be set in the environment
+func format(v reflect.Value) string {
+	switch value := v.Interface().(type) {
+	case time.Duration:
+		return value.String()
+	case []string:
+		return strings.Join(value, ",")
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// File returns the YAML file the configuration was read from, if any
+func (c *Config) File() string {
+	return c.file
+}
+
+// Settings lists the settings with their source, secrets redacted
+func (c *Config) Settings() []Setting {
+	var settings []Setting
+	for _, f := range c.fields() {
+		setting := Setting{
+			Env:    f.env,
+			Key:    f.key,
+			Value:  format(f.value),
+			Source: SourceDefault,
+			Secret: f.secret,
+		}
+		if source, ok := c.sources[f.env]; ok {
+			setting.Source = source
+		}
+		if f.secret && setting.Value != "" {
+			setting.Value = redacted
+		}
+		settings = append(settings, setting)
+	}
+	return settings
+}
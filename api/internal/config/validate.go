@@ -0,0 +1,193 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"tournois-tt/api/pkg/geocoding"
+	"tournois-tt/api/pkg/geocoding/providers"
+	"tournois-tt/api/pkg/logging"
+	"tournois-tt/api/pkg/privacy"
+
+	"github.com/robfig/cron/v3"
+)
+
+// alertChannels are the known alert channels
+var alertChannels = []string{"log", "webhook", "email", "discord", "telegram"}
+
+// Validate checks the settings, returning an error listing each invalid one
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(env, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", env, fmt.Sprintf(format, args...)))
+	}
+	positive := func(env string, d time.Duration) {
+		if d <= 0 {
+			invalid(env, "must be a positive duration, got %s", d)
+		}
+	}
+	httpURL := func(env, value string) {
+		if value == "" {
+			return
+		}
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			invalid(env, "must be an http(s) URL")
+		}
+	}
+
+	if c.Server.Addr == "" {
+		invalid("HTTP_ADDR", "is required")
+	}
+	if c.Server.FrontendURL == "" {
+		invalid("FRONTEND_URL", "is required")
+	}
+	httpURL("FRONTEND_URL", c.Server.FrontendURL)
+	positive("SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout)
+	if _, err := privacy.ParseMode(c.Server.LogPrivacy); err != nil {
+		invalid("LOG_PRIVACY", "must be hash, truncate or off, got %q", c.Server.LogPrivacy)
+	}
+
+	if c.Logging.Format != "" && c.Logging.Format != "json" && c.Logging.Format != "text" {
+		invalid("LOG_FORMAT", "must be json or text, got %q", c.Logging.Format)
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Logging.Level)); err != nil {
+		invalid("LOG_LEVEL", "must be debug, info, warn or error, got %q", c.Logging.Level)
+	}
+	if _, err := logging.ParseLevels(c.Logging.Levels); err != nil {
+		invalid("LOG_LEVELS", "%v", err)
+	}
+	if c.Logging.RetentionDays < 1 {
+		invalid("LOG_RETENTION_DAYS", "must be at least 1")
+	}
+
+	if c.RateLimit.RequestsPerMinute < 1 {
+		invalid("RATE_LIMIT_PER_MINUTE", "must be at least 1")
+	}
+	if c.RateLimit.Burst < 1 {
+		invalid("RATE_LIMIT_BURST", "must be at least 1")
+	}
+	for _, entry := range c.RateLimit.Allowlist {
+		if _, err := netip.ParsePrefix(entry); err == nil {
+			continue
+		}
+		if _, err := netip.ParseAddr(entry); err != nil {
+			invalid("RATE_LIMIT_ALLOWLIST", "%q is neither an IP address nor a CIDR range", entry)
+		}
+	}
+
+	positive("READY_DEGRADED_AFTER", c.Readiness.DegradedAfter)
+	positive("READY_DOWN_AFTER", c.Readiness.DownAfter)
+	if c.Readiness.DownAfter <= c.Readiness.DegradedAfter {
+		invalid("READY_DOWN_AFTER", "must be longer than READY_DEGRADED_AFTER")
+	}
+
+	if _, err := cron.ParseStandard(c.Refresh.Schedule); err != nil {
+		invalid("REFRESH_SCHEDULE", "invalid cron spec: %v", err)
+	}
+	positive("REFRESH_TIMEOUT", c.Refresh.Timeout)
+	if c.Refresh.Jitter < 0 {
+		invalid("REFRESH_JITTER", "must not be negative")
+	}
+	if c.Refresh.DegradedAfterFailures < 1 {
+		invalid("DEGRADED_AFTER_FAILURES", "must be at least 1")
+	}
+	positive("DEGRADED_ESCALATE_AFTER", c.Refresh.DegradedEscalateAfter)
+
+	alerting := c.Alerting
+	httpURL("ALERT_WEBHOOK_URL", alerting.WebhookURL)
+	httpURL("ALERT_DISCORD_WEBHOOK_URL", alerting.DiscordWebhookURL)
+	positive("ALERT_REPEAT_INTERVAL", alerting.RepeatInterval)
+	if alerting.MaxPerHour < 1 {
+		invalid("ALERT_MAX_PER_HOUR", "must be at least 1")
+	}
+	configured := map[string]bool{
+		"log":      true,
+		"webhook":  alerting.WebhookURL != "",
+		"email":    c.Brevo.APIKey != "" && alerting.EmailFrom != "" && len(alerting.EmailTo) > 0,
+		"discord":  alerting.DiscordWebhookURL != "",
+		"telegram": alerting.TelegramBotToken != "" && alerting.TelegramChatID != "",
+	}
+	for severity, channels := range alerting.Channels.BySeverity() {
+		env := "ALERT_CHANNELS_" + strings.ToUpper(severity)
+		for _, channel := range channels {
+			switch {
+			case !slices.Contains(alertChannels, channel):
+				invalid(env, "unknown channel %q, expected one of %v", channel, alertChannels)
+			case !configured[channel]:
+				invalid(env, "channel %q is not configured", channel)
+			}
+		}
+	}
+
+	if c.Admin.TLSAddr != "" {
+		required := func(env, value string) {
+			if value == "" {
+				invalid(env, "is required with ADMIN_TLS_ADDR")
+			}
+		}
+		required("ADMIN_TLS_CERT", c.Admin.TLSCert)
+		required("ADMIN_TLS_KEY", c.Admin.TLSKey)
+		required("ADMIN_CLIENT_CA", c.Admin.ClientCA)
+	}
+
+	if c.Brevo.NewsletterListID < 1 {
+		invalid("BREVO_NEWSLETTER_LIST_ID", "must be a Brevo list ID")
+	}
+	if c.Brevo.CampaignID < 0 {
+		invalid("BREVO_CAMPAIGN_ID", "must be a Brevo campaign ID")
+	}
+
+	if (c.Analytics.MeasurementID == "") != (c.Analytics.APISecret == "") {
+		invalid("GA_API_SECRET", "GA_MEASUREMENT_ID and GA_API_SECRET must be set together")
+	}
+
+	geocoder := c.Geocoding
+	if !slices.Contains(geocoding.Strategies, geocoding.Strategy(geocoder.Strategy)) {
+		invalid("GEOCODING_STRATEGY", "must be first-success or best-precision, got %q", geocoder.Strategy)
+	}
+	geocodingProviders := providers.Registry().Names()
+	for _, provider := range geocoder.Providers {
+		if !slices.Contains(geocodingProviders, provider) {
+			invalid("GEOCODING_PROVIDERS", "unknown provider %q, expected one of %v", provider, geocodingProviders)
+		}
+	}
+	geocodingPolicy := func(prefix string, p GeocodingProviderConfig) {
+		httpURL(prefix+"URL", p.URL)
+		if p.Interval < 0 {
+			invalid(prefix+"INTERVAL", "must not be negative")
+		}
+		positive(prefix+"TIMEOUT", p.Timeout)
+		if p.Retries < 0 {
+			invalid(prefix+"RETRIES", "must not be negative")
+		}
+		if p.RetryDelay < 0 {
+			invalid(prefix+"RETRY_DELAY", "must not be negative")
+		}
+		if p.DailyQuota < 0 {
+			invalid(prefix+"DAILY_QUOTA", "must not be negative, 0 being unlimited")
+		}
+		if p.MinScore < 0 || p.MinScore > 1 {
+			invalid(prefix+"MIN_SCORE", "must be between 0 and 1")
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(geocoder.Policies)) {
+		policy := geocoder.Policies[name]
+		prefix := "GEOCODING_" + strings.ToUpper(name) + "_"
+		if !slices.Contains(geocodingProviders, name) {
+			invalid(prefix+"*", "unknown provider %q, expected one of %v", name, geocodingProviders)
+			continue
+		}
+		geocodingPolicy(prefix, *policy)
+	}
+
+	return errors.Join(errs...)
+}
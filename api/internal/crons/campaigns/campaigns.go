@@ -1,28 +1,59 @@
 package campaigns
 
 import (
-	"log"
-	"os"
-	"strconv"
-	"tournois-tt/api/pkg/brevo"
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"tournois-tt/api/internal/app"
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/logging"
 )
 
-func sendCurrentCampaign() {
-	log.Println("Sending campaign now.")
+var logger = logging.For("crons")
 
-	cl := brevo.NewBrevoClient(os.Getenv("BREVO_API_KEY"))
+// ErrNoCampaign is returned when BREVO_CAMPAIGN_ID is not set
+var ErrNoCampaign = errors.New("BREVO_CAMPAIGN_ID is not set")
 
-	campaignID, err := strconv.Atoi(os.Getenv("BREVO_CAMPAIGN_ID"))
-	if err != nil {
-		log.Println(err)
-		return
+// Send sends the configured Brevo campaign now
+func Send(ctx context.Context, a *app.App) error {
+	campaignID := a.Config.Brevo.CampaignID
+	if campaignID == 0 {
+		return ErrNoCampaign
 	}
 
-	err = brevo.SendCampaign(cl, campaignID)
-	if err != nil {
-		log.Println(err)
-		return
+	logger.InfoContext(ctx, "Sending campaign", "campaign_id", campaignID)
+	if err := a.Brevo.SendCampaign(ctx, campaignID); err != nil {
+		return err
 	}
 
-	log.Println("Campaign sent successfully.")
+	logger.InfoContext(ctx, "Campaign sent", "campaign_id", campaignID)
+	return nil
+}
+
+// Digest returns the tournaments that are not cancelled and start within days of now,
+// sorted by start date
+func Digest(tournaments map[string]cache.TournamentCache, now time.Time, days int) []cache.TournamentCache {
+	from := now.Format("2006-01-02")
+	until := now.AddDate(0, 0, days).Format("2006-01-02")
+
+	var digest []cache.TournamentCache
+	for _, tournament := range tournaments {
+		start := tournament.StartDate
+		if len(start) > len(from) {
+			start = start[:len(from)]
+		}
+		if tournament.Cancelled || start < from || start > until {
+			continue
+		}
+		digest = append(digest, tournament)
+	}
+	sort.Slice(digest, func(i, j int) bool {
+		if digest[i].StartDate != digest[j].StartDate {
+			return digest[i].StartDate < digest[j].StartDate
+		}
+		return digest[i].ID < digest[j].ID
+	})
+	return digest
 }
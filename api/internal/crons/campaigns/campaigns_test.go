@@ -0,0 +1,28 @@
+package campaigns
+
+import (
+	"testing"
+	"time"
+
+	"tournois-tt/api/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigest(t *testing.T) {
+	now := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	tournaments := map[string]cache.TournamentCache{
+		"1": {ID: 1, StartDate: "2025-10-04T00:00:00"},
+		"2": {ID: 2, StartDate: "2025-10-02T00:00:00"},
+		"3": {ID: 3, StartDate: "2025-10-03T00:00:00", Cancelled: true},
+		"4": {ID: 4, StartDate: "2025-09-30T00:00:00"},
+		"5": {ID: 5, StartDate: "2025-10-15T00:00:00"},
+		"6": {ID: 6, StartDate: "2025-10-01T00:00:00"},
+	}
+
+	var ids []int
+	for _, tournament := range Digest(tournaments, now, 7) {
+		ids = append(ids, tournament.ID)
+	}
+	assert.Equal(t, []int{6, 2, 1}, ids)
+}
@@ -0,0 +1,48 @@
+// Package counters lets jobs report how much work a run did, e.g. the number of tournaments
+// fetched or geocoded. The counts are kept in the run history of the job.
+package counters
+
+import (
+	"context"
+	"sync"
+)
+
+type contextKey struct{}
+
+// Set holds the counters of a run
+type Set struct {
+	mu     sync.Mutex
+	values map[string]int64
+}
+
+// New returns a context carrying a new set of counters
+func New(ctx context.Context) (context.Context, *Set) {
+	set := &Set{values: make(map[string]int64)}
+	return context.WithValue(ctx, contextKey{}, set), set
+}
+
+// Add adds delta to a counter of the run of ctx, if any
+func Add(ctx context.Context, name string, delta int64) {
+	set, ok := ctx.Value(contextKey{}).(*Set)
+	if !ok {
+		return
+	}
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	set.values[name] += delta
+}
+
+// Values returns a copy of the counters, nil when there are none
+func (s *Set) Values() map[string]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.values) == 0 {
+		return nil
+	}
+	values := make(map[string]int64, len(s.values))
+	for name, value := range s.values {
+		values[name] = value
+	}
+	return values
+}
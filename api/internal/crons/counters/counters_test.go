@@ -0,0 +1,20 @@
+package counters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddCountsOnlyWithinARun(t *testing.T) {
+	Add(context.Background(), "ignored", 1)
+
+	ctx, set := New(context.Background())
+	assert.Nil(t, set.Values())
+
+	Add(ctx, "fetched", 10)
+	Add(ctx, "fetched", 5)
+	Add(ctx, "geocoded", 2)
+	assert.Equal(t, map[string]int64{"fetched": 15, "geocoded": 2}, set.Values())
+}
@@ -1,45 +1,178 @@
 package crons
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
 	"time"
-	"tournois-tt/api/internal/crons/tournaments"
+	"tournois-tt/api/pkg/alerting"
+	"tournois-tt/api/pkg/logging"
+	"tournois-tt/api/pkg/metrics"
 
 	_ "time/tzdata"
 
 	"github.com/robfig/cron/v3"
 )
 
-func Schedule() {
+var logger = logging.For("crons")
+
+var (
+	jobRuns     = metrics.NewCounterVec("tournois_cron_job_runs_total", "Cron job runs", "job")
+	jobOutcomes = metrics.NewCounterVec("tournois_cron_job_outcomes_total",
+		"Cron job runs by outcome: ok, error, timeout, cancelled, panic or skipped", "job", "outcome")
+	jobDuration = metrics.NewHistogramVec("tournois_cron_job_duration_seconds", "Duration of cron job runs",
+		[]float64{1, 5, 15, 30, 60, 120, 300, 600}, "job")
+)
+
+// RefreshTournaments is the job refreshing the tournaments of the last and current seasons
+const RefreshTournaments = "refresh-tournaments"
+
+// Scheduler runs the cron jobs, and the jobs triggered on demand. Jobs get a context that is
+// only cancelled when a shutdown deadline is reached, or their timeout, so that they can
+// finish or checkpoint their work.
+type Scheduler struct {
+	cron    *cron.Cron
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+	// stopping is closed when shutdown starts, ending the jitter of pending runs
+	stopping chan struct{}
+	stopOnce sync.Once
+
+	alerts  *alerting.Dispatcher
+	history *runHistory
+	// active holds the held locks and the names of the jobs holding them
+	activeMu sync.Mutex
+	active   map[string]string
+
+	mu      sync.Mutex
+	jobs    map[string]Job
+	started bool
+}
+
+// New returns a scheduler in the Paris time zone, keeping the history of the runs in dir
+// and alerting through alerts. Jobs are run with Run right away, and in the background
+// once Schedule is called.
+func New(dir string, alerts *alerting.Dispatcher) (*Scheduler, error) {
 	location, err := time.LoadLocation("Europe/Paris")
 	if err != nil {
-		log.Fatal("Error loading Europe/Paris time zone:", err)
+		return nil, fmt.Errorf("failed to load the Europe/Paris time zone: %v", err)
 	}
 
-	// Initialize a new cron scheduler with the Paris time zone
-	c := cron.New(cron.WithLocation(location))
+	history, err := openHistory(filepath.Join(dir, "job_runs.json"))
+	if err != nil {
+		logger.Warn("Previous job runs were not loaded", "error", err)
+	}
 
-	// Schedule the cron job to run every day at 1 PM
-	// _, err = c.AddFunc("0 13 * * *", sendCurrentCampaign)
-	// if err != nil {
-	// 	log.Fatal("Error adding cron job:", err)
-	// }
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		cron:     cron.New(cron.WithLocation(location)),
+		ctx:      ctx,
+		cancel:   cancel,
+		stopping: make(chan struct{}),
+		alerts:   alerts,
+		history:  history,
+		active:   make(map[string]string),
+		jobs:     make(map[string]Job),
+	}, nil
+}
 
-	// Schedule the cron job to run every 5 minutes
-	_, err = c.AddFunc("*/5 * * * *", tournaments.RefreshListWithGeocoding)
-	if err != nil {
-		log.Fatal("Error adding cron job:", err)
+// Schedule starts running the jobs at their schedule, and the jobs started in the background
+func (s *Scheduler) Schedule() {
+	s.mu.Lock()
+	s.started = true
+	s.mu.Unlock()
+	s.cron.Start()
+	logger.Info("All cron jobs started")
+}
+
+// Add registers a job, scheduled when it has a Spec
+func (s *Scheduler) Add(job Job) error {
+	if job.Spec != "" {
+		if _, err := s.cron.AddFunc(job.Spec, func() { s.scheduled(job) }); err != nil {
+			return fmt.Errorf("invalid schedule of job %s: %v", job.Name, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.Name] = job
+	return nil
+}
+
+// Trigger runs a registered job now, in the background
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	job, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown job %q", name)
+	}
+	return s.Start(job)
+}
+
+// Start runs a job now in the background, waited for on shutdown like scheduled runs. It
+// returns ErrAlreadyRunning without starting the job when a run of the same name is going,
+// and ErrStopped when the scheduler is not running.
+func (s *Scheduler) Start(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.started {
+		return ErrStopped
+	}
+	if !s.acquire(job) {
+		return ErrAlreadyRunning
+	}
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		defer s.release(job)
+		s.execute(s.ctx, job, TriggerManual)
+	}()
+	return nil
+}
+
+// scheduled runs a job at its schedule, after its jitter, unless it is still running
+func (s *Scheduler) scheduled(job Job) {
+	select {
+	case <-time.After(jitter(job.Jitter)):
+	case <-s.stopping:
+		return
+	}
+
+	if !s.acquire(job) {
+		s.skipped(job)
+		return
 	}
+	defer s.release(job)
+	s.execute(s.ctx, job, TriggerSchedule)
+}
 
-	// Schedule the cron job to run every day at 1 AM
-	// _, err = c.AddFunc("0 1 * * *", tournaments.RefreshSignupURLs)
-	// if err != nil {
-	// 	log.Fatal("Error adding cron job:", err)
-	// }
+// Shutdown stops scheduling jobs and waits for the running ones. When ctx is done first,
+// running jobs are cancelled and waited for, and ctx's error is returned.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.started = false
+	s.mu.Unlock()
+	s.stopOnce.Do(func() { close(s.stopping) })
+	stopped := s.cron.Stop()
 
-	// Start the cron scheduler in a separate goroutine
+	done := make(chan struct{})
 	go func() {
-		c.Start()
-		log.Println("✅ All cron jobs started successfully")
+		<-stopped.Done()
+		s.running.Wait()
+		close(done)
 	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		logger.Warn("Cancelling running cron jobs")
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
 }
@@ -0,0 +1,162 @@
+package crons
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tournois-tt/api/internal/crons/counters"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newScheduler returns a scheduler keeping its run history in a temporary directory
+func newScheduler(t *testing.T) *Scheduler {
+	t.Helper()
+
+	s, err := New(t.TempDir(), nil)
+	require.NoError(t, err)
+	return s
+}
+
+func TestShutdownWaitsForRunningJobs(t *testing.T) {
+	s := newScheduler(t)
+
+	var finished atomic.Bool
+	job := Job{Name: "quick", Run: func(ctx context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		finished.Store(true)
+		return nil
+	}}
+	assert.ErrorIs(t, s.Start(job), ErrStopped, "background jobs wait for the scheduler")
+	s.Schedule()
+	require.NoError(t, s.Start(job))
+
+	assert.NoError(t, s.Shutdown(context.Background()))
+	assert.True(t, finished.Load())
+	assert.ErrorIs(t, s.Start(job), ErrStopped)
+}
+
+func TestShutdownCancelsJobsAtDeadline(t *testing.T) {
+	s := newScheduler(t)
+	s.Schedule()
+
+	var checkpointed atomic.Bool
+	require.NoError(t, s.Start(Job{Name: "slow", Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		checkpointed.Store(true)
+		return ctx.Err()
+	}}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, s.Shutdown(ctx), context.DeadlineExceeded)
+	assert.True(t, checkpointed.Load())
+	assert.Equal(t, OutcomeCancelled, s.RecentRuns("slow", 1)[0].Outcome)
+}
+
+func TestRunsDoNotOverlap(t *testing.T) {
+	s := newScheduler(t)
+	s.Schedule()
+	defer s.Shutdown(context.Background())
+
+	release := make(chan struct{})
+	job := Job{Name: "overlap", Run: func(ctx context.Context) error {
+		<-release
+		return nil
+	}}
+	require.NoError(t, s.Start(job))
+	assert.ErrorIs(t, s.Start(job), ErrAlreadyRunning)
+	assert.ErrorIs(t, s.Run(context.Background(), job), ErrAlreadyRunning)
+	assert.Contains(t, s.Running(), "overlap")
+
+	s.scheduled(job)
+	runs := s.RecentRuns("overlap", 0)
+	require.Len(t, runs, 1)
+	assert.Equal(t, OutcomeSkipped, runs[0].Outcome)
+	assert.Equal(t, TriggerSchedule, runs[0].Trigger)
+
+	close(release)
+	require.NoError(t, s.Shutdown(context.Background()))
+	assert.NotContains(t, s.Running(), "overlap")
+	assert.NoError(t, s.Run(context.Background(), job))
+}
+
+func TestJobsSharingALockDontOverlap(t *testing.T) {
+	release := make(chan struct{})
+	refresh := Job{Name: "refresh", Run: func(ctx context.Context) error {
+		<-release
+		return nil
+	}}
+	geocode := Job{Name: "geocode", Lock: "refresh", Run: func(ctx context.Context) error { return nil }}
+
+	s := newScheduler(t)
+	done := make(chan error)
+	go func() { done <- s.Run(context.Background(), refresh) }()
+	require.Eventually(t, func() bool { return slices.Contains(s.Running(), "refresh") }, time.Second, time.Millisecond)
+
+	assert.ErrorIs(t, s.Run(context.Background(), geocode), ErrAlreadyRunning)
+	assert.NoError(t, s.Run(context.Background(), Job{Name: "other", Run: geocode.Run}))
+
+	close(release)
+	require.NoError(t, <-done)
+	assert.NoError(t, s.Run(context.Background(), geocode))
+}
+
+func TestRunOutcomes(t *testing.T) {
+	s := newScheduler(t)
+
+	assert.NoError(t, s.Run(context.Background(), Job{Name: "counted", Run: func(ctx context.Context) error {
+		counters.Add(ctx, "fetched", 12)
+		return nil
+	}}))
+	assert.Error(t, s.Run(context.Background(), Job{Name: "failing", Run: func(ctx context.Context) error {
+		return errors.New("FFTT is down")
+	}}))
+	assert.ErrorIs(t, s.Run(context.Background(), Job{Name: "timeout", Timeout: 10 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}}), context.DeadlineExceeded)
+	assert.EqualError(t, s.Run(context.Background(), Job{Name: "panicking", Run: func(ctx context.Context) error {
+		panic("nil map")
+	}}), "panic: nil map")
+	assert.NotContains(t, s.Running(), "panicking")
+
+	runs := s.RecentRuns("", 0)
+	require.Len(t, runs, 4)
+	assert.Equal(t, OutcomePanic, runs[0].Outcome)
+	assert.Equal(t, OutcomeTimeout, runs[1].Outcome)
+	assert.Equal(t, OutcomeError, runs[2].Outcome)
+	assert.Equal(t, "FFTT is down", runs[2].Error)
+	assert.Equal(t, OutcomeOK, runs[3].Outcome)
+	assert.Equal(t, map[string]int64{"fetched": 12}, runs[3].Counters)
+	assert.Equal(t, TriggerManual, runs[3].Trigger)
+	assert.False(t, runs[3].End.Before(runs[3].Start))
+}
+
+func TestHistoryIsPersisted(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(dir, nil)
+	require.NoError(t, err)
+	require.NoError(t, s.Run(context.Background(), Job{Name: "persisted", Run: func(ctx context.Context) error { return nil }}))
+
+	reopened, err := New(dir, nil)
+	require.NoError(t, err)
+	runs := reopened.RecentRuns("persisted", 0)
+	require.Len(t, runs, 1)
+	assert.Equal(t, OutcomeOK, runs[0].Outcome)
+}
+
+func TestJitterStaysBelowMax(t *testing.T) {
+	assert.Zero(t, jitter(0))
+	for i := 0; i < 100; i++ {
+		d := jitter(time.Second)
+		assert.True(t, d >= 0 && d < time.Second)
+	}
+}
@@ -0,0 +1,106 @@
+package crons
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"tournois-tt/api/pkg/cache"
+)
+
+// historySize is the number of job runs kept
+const historySize = 500
+
+// JobRun is a finished or skipped run of a job
+type JobRun struct {
+	Job      string           `json:"job"`
+	Trigger  string           `json:"trigger"`
+	Start    time.Time        `json:"start"`
+	End      time.Time        `json:"end"`
+	Duration time.Duration    `json:"duration"`
+	Outcome  string           `json:"outcome"`
+	Error    string           `json:"error,omitempty"`
+	Counters map[string]int64 `json:"counters,omitempty"`
+}
+
+// runHistory keeps the most recent job runs, persisted to path
+type runHistory struct {
+	mu   sync.Mutex
+	path string
+	runs []JobRun
+}
+
+// openHistory loads the runs persisted at path and saves the next runs to it. The history
+// is returned empty with the error when the file cannot be read.
+func openHistory(path string) (*runHistory, error) {
+	h := &runHistory{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return h, fmt.Errorf("failed to read job runs: %v", err)
+	}
+	if err := json.Unmarshal(data, &h.runs); err != nil {
+		h.runs = nil
+		return h, fmt.Errorf("failed to parse job runs: %v", err)
+	}
+	h.trim()
+	return h, nil
+}
+
+// add records a run
+func (h *runHistory) add(run JobRun) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.runs = append(h.runs, run)
+	h.trim()
+	if err := h.save(); err != nil {
+		logger.Error("Failed to save job runs", "error", err)
+	}
+}
+
+// trim drops the oldest runs. Callers hold h.mu.
+func (h *runHistory) trim() {
+	if len(h.runs) > historySize {
+		h.runs = h.runs[len(h.runs)-historySize:]
+	}
+}
+
+// save writes the runs atomically. Callers hold h.mu.
+func (h *runHistory) save() error {
+	data, err := json.Marshal(h.runs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job runs: %v", err)
+	}
+	return cache.WriteFileAtomic(h.path, data, 0644)
+}
+
+// RecentRuns returns up to limit runs of a job, or of all jobs when job is empty, most
+// recent first
+func (s *Scheduler) RecentRuns(job string, limit int) []JobRun {
+	h := s.history
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	runs := make([]JobRun, 0)
+	for i := len(h.runs) - 1; i >= 0; i-- {
+		if limit > 0 && len(runs) == limit {
+			break
+		}
+		if job == "" || h.runs[i].Job == job {
+			runs = append(runs, h.runs[i])
+		}
+	}
+	return runs
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,191 @@
+package crons
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"runtime/debug"
+	"time"
+
+	"tournois-tt/api/internal/crons/counters"
+	"tournois-tt/api/pkg/alerting"
+	"tournois-tt/api/pkg/logging"
+)
+
+// ErrAlreadyRunning is returned when a job is started while a run holding the same lock is
+// going
+var ErrAlreadyRunning = errors.New("job is already running")
+
+// ErrStopped is returned when a job is started in the background before the scheduler runs
+// or once it shuts down
+var ErrStopped = errors.New("background jobs are not running")
+
+// Job is a unit of background work. Runs holding the same lock never overlap.
+type Job struct {
+	Name string
+	// Lock is held by the runs of the job, Name when empty. Jobs writing the same data share
+	// a lock.
+	Lock string
+	// Spec is the cron schedule of the job, empty for jobs only run on demand
+	Spec string
+	// Timeout cancels the context of a run, zero for no timeout
+	Timeout time.Duration
+	// Jitter delays scheduled runs by a random duration up to this value
+	Jitter time.Duration
+	Run    func(ctx context.Context) error
+}
+
+// Run outcomes
+const (
+	OutcomeOK        = "ok"
+	OutcomeError     = "error"
+	OutcomeTimeout   = "timeout"
+	OutcomeCancelled = "cancelled"
+	OutcomePanic     = "panic"
+	OutcomeSkipped   = "skipped"
+)
+
+// Run triggers
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// lock returns the lock held by the runs of the job
+func (job Job) lock() string {
+	if job.Lock != "" {
+		return job.Lock
+	}
+	return job.Name
+}
+
+func (s *Scheduler) acquire(job Job) bool {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+
+	if _, held := s.active[job.lock()]; held {
+		return false
+	}
+	s.active[job.lock()] = job.Name
+	return true
+}
+
+func (s *Scheduler) release(job Job) {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+	delete(s.active, job.lock())
+}
+
+// Running returns the names of the jobs currently running
+func (s *Scheduler) Running() []string {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+
+	names := make([]string, 0, len(s.active))
+	for _, name := range s.active {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Run runs a job now and returns its error, or ErrAlreadyRunning
+func (s *Scheduler) Run(ctx context.Context, job Job) error {
+	if !s.acquire(job) {
+		return ErrAlreadyRunning
+	}
+	defer s.release(job)
+	return s.execute(ctx, job, TriggerManual)
+}
+
+// execute runs a job that was acquired, with its own request ID, timeout, panic recovery,
+// logs, metrics and run history
+func (s *Scheduler) execute(parent context.Context, job Job, trigger string) (err error) {
+	ctx := logging.WithRequestID(parent, "cron-"+logging.NewRequestID())
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+	ctx, counts := counters.New(ctx)
+
+	start := time.Now()
+	logger.InfoContext(ctx, "Cron job started", "job", job.Name, "trigger", trigger)
+
+	result := OutcomeOK
+	defer func() {
+		if r := recover(); r != nil {
+			result = OutcomePanic
+			err = fmt.Errorf("panic: %v", r)
+			logger.ErrorContext(ctx, "Cron job panicked", "job", job.Name, "panic", r, "stack", string(debug.Stack()))
+		}
+
+		end := time.Now()
+		duration := end.Sub(start)
+		jobRuns.With(job.Name).Inc()
+		jobDuration.With(job.Name).Observe(duration.Seconds())
+		if err != nil && result == OutcomeOK {
+			switch {
+			case errors.Is(ctx.Err(), context.DeadlineExceeded):
+				result = OutcomeTimeout
+			case ctx.Err() != nil:
+				result = OutcomeCancelled
+			default:
+				result = OutcomeError
+			}
+		}
+		jobOutcomes.With(job.Name, result).Inc()
+		s.history.add(JobRun{
+			Job:      job.Name,
+			Trigger:  trigger,
+			Start:    start,
+			End:      end,
+			Duration: duration,
+			Outcome:  result,
+			Error:    errorString(err),
+			Counters: counts.Values(),
+		})
+
+		if err != nil {
+			logger.ErrorContext(ctx, "Cron job failed", "job", job.Name, "outcome", result, "duration", duration, "error", err)
+		} else {
+			logger.InfoContext(ctx, "Cron job finished", "job", job.Name, "duration", duration)
+		}
+		s.alertOutcome(job.Name, result, err)
+	}()
+
+	return job.Run(ctx)
+}
+
+// alertOutcome alerts on runs that panicked or timed out, which plain failures of the jobs
+// themselves do not cover, and resolves the alert on the next successful run
+func (s *Scheduler) alertOutcome(name, result string, err error) {
+	key := "job." + name
+	switch result {
+	case OutcomePanic, OutcomeTimeout:
+		s.alerts.Fire(alerting.Alert{
+			Key:      key,
+			Severity: alerting.SeverityWarning,
+			Title:    "Job " + name + " " + map[string]string{OutcomePanic: "panicked", OutcomeTimeout: "timed out"}[result],
+			Message:  errorString(err),
+		})
+	case OutcomeOK:
+		s.alerts.Resolve(key, "Job "+name+" succeeded again", "")
+	}
+}
+
+// skipped records a scheduled run that did not start because the previous one is running
+func (s *Scheduler) skipped(job Job) {
+	now := time.Now()
+	logger.Warn("Cron job skipped, previous run still running", "job", job.Name)
+	jobOutcomes.With(job.Name, OutcomeSkipped).Inc()
+	s.history.add(JobRun{Job: job.Name, Trigger: TriggerSchedule, Start: now, End: now, Outcome: OutcomeSkipped})
+}
+
+// jitter returns a random delay up to max
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return rand.N(max)
+}
@@ -1,23 +1,54 @@
 package tournaments
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"tournois-tt/api/internal/app"
+	"tournois-tt/api/internal/crons"
 	"tournois-tt/api/internal/crons/tournaments/geocoding"
+	"tournois-tt/api/pkg/logging"
 	"tournois-tt/api/pkg/utils"
 )
 
-func RefreshListWithGeocoding() {
+var logger = logging.For("crons")
+
+// Job returns the job refreshing the tournaments at the schedule of the configuration,
+// every 5 minutes by default
+func Job(a *app.App) crons.Job {
+	return crons.Job{
+		Name:    crons.RefreshTournaments,
+		Spec:    a.Config.Refresh.Schedule,
+		Timeout: a.Config.Refresh.Timeout,
+		Jitter:  a.Config.Refresh.Jitter,
+		Run: func(ctx context.Context) error {
+			return RefreshListWithGeocoding(ctx, a)
+		},
+	}
+}
+
+// RefreshListWithGeocoding refreshes the tournaments of the last and current seasons. A failed
+// current season refresh puts the API in degraded mode instead of stopping it.
+func RefreshListWithGeocoding(ctx context.Context, a *app.App) error {
 	lastSeasonStart, _ := utils.GetLastFinishedSeason()
 	currentSeasonStart, currentSeasonEnd := utils.GetCurrentSeason()
 
 	// First refresh historical tournaments (non-critical operation)
-	if err := geocoding.RefreshGeocoding(&lastSeasonStart, &currentSeasonStart); err != nil {
-		log.Printf("Warning: Failed to refresh historical tournament geocoding data: %v", err)
+	if err := geocoding.RefreshGeocoding(ctx, a, &lastSeasonStart, &currentSeasonStart); err != nil {
+		logger.WarnContext(ctx, "Failed to refresh historical tournament geocoding data", "error", err)
 	}
 
 	// Then refresh current season tournaments (critical operation)
-	if err := geocoding.RefreshGeocoding(&currentSeasonStart, &currentSeasonEnd); err != nil {
-		// Fatal error after multiple retry attempts
-		log.Fatalf("Critical error: Failed to refresh current season tournament geocoding data after multiple attempts: %v", err)
+	if err := geocoding.RefreshGeocoding(ctx, a, &currentSeasonStart, &currentSeasonEnd); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("current season refresh interrupted: %v", err)
+		}
+		// Keep serving the cached tournaments, marked stale, until the FFTT is back
+		a.Degraded.Failed(err)
+		return fmt.Errorf("current season refresh failed: %v", err)
+	}
+	if err := a.Store.MarkCurrentSeasonRefreshed(a.Now()); err != nil {
+		logger.WarnContext(ctx, "Failed to save the refresh time", "error", err)
 	}
+	a.Degraded.Succeeded()
+	return nil
 }
@@ -1,24 +1,19 @@
 package geocoding
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"time"
+	"tournois-tt/api/internal/app"
+	"tournois-tt/api/internal/crons/counters"
 	"tournois-tt/api/pkg/cache"
 	"tournois-tt/api/pkg/fftt"
 	"tournois-tt/api/pkg/geocoding"
+	"tournois-tt/api/pkg/logging"
 	"tournois-tt/api/pkg/utils"
 )
 
-// Debug flag to control verbose logging
-var Debug = false
-
-// debugLog logs a message only if Debug is true
-func debugLog(format string, args ...interface{}) {
-	if Debug {
-		log.Printf(format, args...)
-	}
-}
+var logger = logging.For("crons")
 
 // IsCurrentSeasonQuery checks if the query date range is part of the current season
 func IsCurrentSeasonQuery(startDateAfter time.Time, startDateBefore *time.Time) bool {
@@ -36,7 +31,7 @@ func IsCurrentSeasonQuery(startDateAfter time.Time, startDateBefore *time.Time)
 }
 
 // fetchAndValidateTournaments fetches tournaments and validates the response based on season context
-func fetchAndValidateTournaments(startDateAfter time.Time, startDateBefore *time.Time, isCurrentSeason bool) ([]fftt.Tournament, error) {
+func fetchAndValidateTournaments(ctx context.Context, client fftt.FFTTClientInterface, startDateAfter time.Time, startDateBefore *time.Time, isCurrentSeason bool) ([]fftt.Tournament, error) {
 	// Configure retry parameters
 	maxRetries := 3
 	if !isCurrentSeason {
@@ -44,7 +39,7 @@ func fetchAndValidateTournaments(startDateAfter time.Time, startDateBefore *time
 	}
 
 	// Fetch tournaments from FFTT with retries
-	tournaments, err := FetchTournamentsWithRetries(startDateAfter, startDateBefore, maxRetries)
+	tournaments, err := FetchTournamentsWithRetries(ctx, client, startDateAfter, startDateBefore, maxRetries)
 
 	// Handle errors based on whether it's current season or historical data
 	if err != nil {
@@ -52,7 +47,7 @@ func fetchAndValidateTournaments(startDateAfter time.Time, startDateBefore *time
 			return nil, fmt.Errorf("failed to fetch current season tournaments after %d attempts: %v", maxRetries, err)
 		} else {
 			// For historical data, just log a warning
-			log.Printf("Warning: Failed to fetch historical tournaments: %v", err)
+			logger.WarnContext(ctx, "Failed to fetch historical tournaments", "error", err)
 			return nil, err
 		}
 	}
@@ -64,9 +59,9 @@ func fetchAndValidateTournaments(startDateAfter time.Time, startDateBefore *time
 			return nil, fmt.Errorf("no tournaments found in current season date range")
 		} else {
 			// Empty response for historical data is just a warning
-			log.Printf("No tournaments found in specified date range from %v to %v",
-				startDateAfter.Format("2006-01-02"),
-				startDateBefore.Format("2006-01-02"))
+			logger.WarnContext(ctx, "No tournaments found in date range",
+				"start_after", startDateAfter.Format("2006-01-02"),
+				"start_before", startDateBefore.Format("2006-01-02"))
 		}
 	}
 
@@ -74,12 +69,8 @@ func fetchAndValidateTournaments(startDateAfter time.Time, startDateBefore *time
 }
 
 // prepareTournamentsForGeocoding processes tournaments and identifies those needing geocoding
-func prepareTournamentsForGeocoding(tournaments []fftt.Tournament) ([]cache.TournamentCache, []geocoding.Address, []int, error) {
-	// Load existing cache
-	cachedTournaments, err := cache.LoadTournaments()
-	if err != nil {
-		log.Printf("Warning: Failed to load tournament cache: %v", err)
-	}
+func prepareTournamentsForGeocoding(store *cache.Store, tournaments []fftt.Tournament) ([]cache.TournamentCache, []geocoding.Address, []int) {
+	cachedTournaments := store.All()
 
 	// Prepare for processing
 	addressesToGeocode := make([]geocoding.Address, 0)
@@ -100,48 +91,57 @@ func prepareTournamentsForGeocoding(tournaments []fftt.Tournament) ([]cache.Tour
 		}
 	}
 
-	return newTournamentCacheEntries, addressesToGeocode, tournamentsNeedingGeocoding, nil
+	return newTournamentCacheEntries, addressesToGeocode, tournamentsNeedingGeocoding
 }
 
 // performGeocoding executes geocoding for addresses that need it
 func performGeocoding(
+	ctx context.Context,
+	geocoder geocoding.Geocoder,
 	tournamentCacheEntries []cache.TournamentCache,
 	addressesToGeocode []geocoding.Address,
 	tournamentsNeedingGeocoding []int,
 ) ([]cache.TournamentCache, error) {
 	if len(addressesToGeocode) > 0 {
+		start := time.Now()
 		updatedEntries, successCount, failureCount := GeocodeAddresses(
+			ctx,
+			geocoder,
 			addressesToGeocode,
 			tournamentsNeedingGeocoding,
 			tournamentCacheEntries,
 		)
 
+		counters.Add(ctx, "geocoded", int64(successCount))
+		counters.Add(ctx, "geocoding_failed", int64(failureCount))
+
 		// Log completion statistics
-		log.Printf("Geocoding refresh completed: %d successful, %d failed", successCount, failureCount)
+		logger.InfoContext(ctx, "Geocoding refresh completed",
+			"succeeded", successCount, "failed", failureCount, "duration", time.Since(start))
 
 		return updatedEntries, nil
 	}
 
-	log.Printf("Geocoding refresh completed: no new addresses to geocode")
+	logger.InfoContext(ctx, "Geocoding refresh completed, no new addresses to geocode")
 	return tournamentCacheEntries, nil
 }
 
 // saveTournamentCache saves the updated tournaments to cache
-func saveTournamentCache(tournamentCacheEntries []cache.TournamentCache) error {
-	err := cache.SaveTournamentsToCache(tournamentCacheEntries)
+func saveTournamentCache(ctx context.Context, store *cache.Store, tournamentCacheEntries []cache.TournamentCache) error {
+	err := store.Save(tournamentCacheEntries)
 	if err != nil {
-		log.Printf("Warning: Failed to save all geocoded tournaments to cache: %v", err)
+		logger.WarnContext(ctx, "Failed to save geocoded tournaments to cache", "error", err)
 		return err
 	}
 
-	debugLog("Saved all %d geocoded tournaments to cache", len(tournamentCacheEntries))
+	logger.DebugContext(ctx, "Saved geocoded tournaments to cache", "count", len(tournamentCacheEntries))
 	return nil
 }
 
 // RefreshGeocoding fetches and updates tournament geocoding data
-func RefreshGeocoding(startDateAfter, startDateBefore *time.Time) error {
+func RefreshGeocoding(ctx context.Context, a *app.App, startDateAfter, startDateBefore *time.Time) error {
 	if startDateAfter == nil {
-		now := time.Now()
+		now := a.Now()
 		startDateAfter = &now
 	}
 
@@ -149,7 +149,7 @@ func RefreshGeocoding(startDateAfter, startDateBefore *time.Time) error {
 	isCurrentSeason := IsCurrentSeasonQuery(*startDateAfter, startDateBefore)
 
 	// Fetch and validate tournaments
-	tournaments, err := fetchAndValidateTournaments(*startDateAfter, startDateBefore, isCurrentSeason)
+	tournaments, err := fetchAndValidateTournaments(ctx, a.FFTT, *startDateAfter, startDateBefore, isCurrentSeason)
 	if err != nil {
 		return err
 	}
@@ -159,24 +159,23 @@ func RefreshGeocoding(startDateAfter, startDateBefore *time.Time) error {
 		return nil
 	}
 
-	log.Printf("Fetched %d tournaments for processing", len(tournaments))
+	logger.InfoContext(ctx, "Fetched tournaments for processing", "count", len(tournaments))
+	counters.Add(ctx, "fetched", int64(len(tournaments)))
 
 	// Prepare tournaments for geocoding
-	tournamentCacheEntries, addressesToGeocode, tournamentsNeedingGeocoding, err := prepareTournamentsForGeocoding(tournaments)
-	if err != nil {
-		return fmt.Errorf("error preparing tournaments for geocoding: %v", err)
-	}
+	tournamentCacheEntries, addressesToGeocode, tournamentsNeedingGeocoding := prepareTournamentsForGeocoding(a.Store, tournaments)
 
-	log.Printf("Found %d tournaments needing geocoding out of %d total tournaments", len(addressesToGeocode), len(tournamentCacheEntries))
+	logger.InfoContext(ctx, "Found tournaments needing geocoding",
+		"count", len(addressesToGeocode), "total", len(tournamentCacheEntries))
 
 	// Perform geocoding for addresses that need it
-	updatedEntries, err := performGeocoding(tournamentCacheEntries, addressesToGeocode, tournamentsNeedingGeocoding)
+	updatedEntries, err := performGeocoding(ctx, a.Geocoder, tournamentCacheEntries, addressesToGeocode, tournamentsNeedingGeocoding)
 	if err != nil {
 		return fmt.Errorf("error during geocoding: %v", err)
 	}
 
 	// Save all tournaments to cache
-	if err := saveTournamentCache(updatedEntries); err != nil {
+	if err := saveTournamentCache(ctx, a.Store, updatedEntries); err != nil {
 		return fmt.Errorf("error saving tournaments to cache: %v", err)
 	}
 
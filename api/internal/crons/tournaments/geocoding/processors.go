@@ -1,8 +1,9 @@
 package geocoding
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
 	"net/url"
 	"time"
 	"tournois-tt/api/pkg/cache"
@@ -11,7 +12,7 @@ import (
 )
 
 // FetchTournamentsWithRetries attempts to fetch tournaments with configurable retries
-func FetchTournamentsWithRetries(startDateAfter time.Time, startDateBefore *time.Time, maxRetries int) ([]fftt.Tournament, error) {
+func FetchTournamentsWithRetries(ctx context.Context, client fftt.FFTTClientInterface, startDateAfter time.Time, startDateBefore *time.Time, maxRetries int) ([]fftt.Tournament, error) {
 	var tournaments []fftt.Tournament
 	var err error
 
@@ -19,8 +20,12 @@ func FetchTournamentsWithRetries(startDateAfter time.Time, startDateBefore *time
 		if attempt > 1 {
 			// Calculate exponential backoff delay: 5s, 20s, 60s
 			delay := time.Duration(attempt*attempt) * 5 * time.Second
-			log.Printf("Retry attempt %d/%d after %v delay", attempt, maxRetries, delay)
-			time.Sleep(delay)
+			logger.InfoContext(ctx, "Retrying FFTT tournaments fetch", "attempt", attempt, "max_attempts", maxRetries, "delay", delay)
+			select {
+			case <-ctx.Done():
+				return tournaments, ctx.Err()
+			case <-time.After(delay):
+			}
 		}
 
 		// Create query parameters
@@ -33,7 +38,7 @@ func FetchTournamentsWithRetries(startDateAfter time.Time, startDateBefore *time
 		params.Set("order[startDate]", "asc")
 
 		// Try to fetch tournaments
-		tournaments, err = fftt.FetchTournaments(params)
+		tournaments, err = fftt.FetchTournaments(ctx, client, params)
 		if err == nil && len(tournaments) > 0 {
 			return tournaments, nil
 		}
@@ -94,6 +99,18 @@ func ProcessTournamentForCache(t fftt.Tournament, cachedTournaments map[string]c
 		}
 	}
 
+	// Add tables if available
+	for _, table := range t.Tables {
+		newCacheEntry.Tables = append(newCacheEntry.Tables, cache.Table{
+			Name:        table.Name,
+			Description: table.Description,
+			Date:        table.Date,
+			Time:        table.Time,
+			Fee:         table.Fee,
+			Endowment:   table.Endowment,
+		})
+	}
+
 	// Add Page (signup link) if available
 	if t.Page != "" {
 		newCacheEntry.Page = t.Page
@@ -137,29 +154,44 @@ func ProcessTournamentForCache(t fftt.Tournament, cachedTournaments map[string]c
 }
 
 // GeocodeAddresses processes a batch of addresses that need geocoding
-func GeocodeAddresses(addressesToGeocode []geocoding.Address, tournamentsToUpdate []int, tournamentCacheEntries []cache.TournamentCache) ([]cache.TournamentCache, int, int) {
+func GeocodeAddresses(ctx context.Context, geocoder geocoding.Geocoder, addressesToGeocode []geocoding.Address, tournamentsToUpdate []int, tournamentCacheEntries []cache.TournamentCache) ([]cache.TournamentCache, int, int) {
 	var successCount, failureCount int
 
-	// Process each address
-	for i, addrIndex := range tournamentsToUpdate {
+	// Skip geocoding addresses we've already determined are invalid
+	var valid []int
+	var addresses []geocoding.Address
+	for i := range tournamentsToUpdate {
 		address := addressesToGeocode[i]
-
-		// Skip geocoding if we've already determined this address is invalid
 		if !geocoding.IsAddressValid(address) {
 			failureCount++
 			continue
 		}
+		valid = append(valid, i)
+		addresses = append(addresses, address)
+	}
 
-		// Get geocoding coordinates
-		location, err := geocoding.GetCoordinates(address)
-		if err != nil {
-			log.Printf("Error geocoding address %s, %s %s: %v",
-				address.StreetAddress, address.PostalCode, address.AddressLocality, err)
+	interrupted := 0
+	for j, result := range geocoder.GetCoordinatesBatch(ctx, addresses) {
+		i := valid[j]
+		addrIndex := tournamentsToUpdate[i]
+
+		// On shutdown, the addresses left keep no coordinates and are geocoded by the next
+		// refresh, while those geocoded so far are saved
+		if ctx.Err() != nil && errors.Is(result.Err, ctx.Err()) {
+			interrupted++
+			continue
+		}
+		if result.Err != nil {
+			logger.WarnContext(ctx, "Failed to geocode tournament address",
+				"tournament_id", tournamentCacheEntries[addrIndex].ID,
+				"address", geocoding.ConstructFullAddress(addressesToGeocode[i]),
+				"error", result.Err)
 			failureCount++
 			continue
 		}
 
 		// Update cache entry with geocoding results
+		location := result.Location
 		cacheEntry := tournamentCacheEntries[addrIndex]
 		cacheEntry.Address.Latitude = location.Lat
 		cacheEntry.Address.Longitude = location.Lon
@@ -173,6 +205,9 @@ func GeocodeAddresses(addressesToGeocode []geocoding.Address, tournamentsToUpdat
 
 		tournamentCacheEntries[addrIndex] = cacheEntry
 	}
+	if interrupted > 0 {
+		logger.WarnContext(ctx, "Geocoding interrupted", "remaining", interrupted)
+	}
 
 	return tournamentCacheEntries, successCount, failureCount
 }
@@ -0,0 +1,81 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tournois-tt/api/internal/app"
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/geocoding"
+)
+
+// Regeocode geocodes the address of a stored tournament again and saves the result
+func Regeocode(ctx context.Context, a *app.App, id int) (cache.TournamentCache, error) {
+	tournament, ok := a.Store.Get(id)
+	if !ok {
+		return cache.TournamentCache{}, cache.ErrTournamentNotFound
+	}
+
+	location, err := a.Geocoder.GetCoordinates(ctx, tournament.Address)
+	if err != nil {
+		return cache.TournamentCache{}, fmt.Errorf("geocoding failed: %v", err)
+	}
+
+	tournament.Address.Latitude = location.Lat
+	tournament.Address.Longitude = location.Lon
+	tournament.Address.Failed = location.Failed
+	tournament.Timestamp = a.Now()
+	a.Store.Update(tournament)
+
+	logger.InfoContext(ctx, "Geocoded tournament again", "tournament_id", id, "failed", location.Failed)
+	return tournament, a.Store.Flush()
+}
+
+// RegeocodeFailed geocodes again the stored tournaments without usable coordinates, in
+// batches for the providers supporting them, until ctx is done, and saves the results
+func RegeocodeFailed(ctx context.Context, a *app.App) (succeeded, failed int, err error) {
+	var pending []cache.TournamentCache
+	for _, tournament := range a.Store.All() {
+		if tournament.NeedsGeocoding() {
+			pending = append(pending, tournament)
+		}
+	}
+	logger.InfoContext(ctx, "Found tournaments with failed geocoding", "count", len(pending))
+
+	addresses := make([]geocoding.Address, len(pending))
+	for i, tournament := range pending {
+		addresses[i] = tournament.Address
+	}
+
+	var geocoded []cache.TournamentCache
+	interrupted := 0
+	for i, result := range a.Geocoder.GetCoordinatesBatch(ctx, addresses) {
+		tournament := pending[i]
+		if ctx.Err() != nil && errors.Is(result.Err, ctx.Err()) {
+			interrupted++
+			continue
+		}
+		if result.Err != nil || result.Location.Failed {
+			logger.WarnContext(ctx, "Failed to geocode tournament address",
+				"tournament_id", tournament.ID,
+				"address", geocoding.ConstructFullAddress(tournament.Address),
+				"error", result.Err)
+			failed++
+			continue
+		}
+
+		tournament.Address.Latitude = result.Location.Lat
+		tournament.Address.Longitude = result.Location.Lon
+		tournament.Address.Failed = false
+		tournament.Timestamp = a.Now()
+		geocoded = append(geocoded, tournament)
+	}
+	succeeded = len(geocoded)
+	a.Store.Update(geocoded...)
+	if interrupted > 0 {
+		logger.WarnContext(ctx, "Geocoding interrupted", "remaining", interrupted)
+	}
+
+	logger.InfoContext(ctx, "Geocoding of failed tournaments completed", "succeeded", succeeded, "failed", failed)
+	return succeeded, failed, a.Store.Flush()
+}
@@ -0,0 +1,62 @@
+package geocoding_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tournois-tt/api/internal/app"
+	tournamentgeocoding "tournois-tt/api/internal/crons/tournaments/geocoding"
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/geocoding"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rennesProvider only geocodes addresses in Rennes
+type rennesProvider struct{}
+
+func (rennesProvider) GetCoordinates(_ context.Context, address geocoding.Address) (geocoding.Location, error) {
+	if address.AddressLocality != "Rennes" {
+		return geocoding.Location{Failed: true}, geocoding.ErrNotFound
+	}
+	return geocoding.Location{Lat: 48.11, Lon: -1.68, Precision: geocoding.PrecisionLocality}, nil
+}
+
+func (rennesProvider) Name() string {
+	return "Rennes"
+}
+
+func TestRegeocodeNotifiesChanges(t *testing.T) {
+	store := cache.NewStore(t.TempDir())
+	for i, city := range []string{"Rennes", "Vannes", "Rennes"} {
+		tournament := cache.TournamentCache{ID: i + 1, Name: "Open de " + city}
+		tournament.Address.AddressLocality = city
+		tournament.Address.Failed = true
+		store.Set(tournament)
+	}
+	var changes []cache.TournamentChange
+	store.OnTournamentsChanged(func(c []cache.TournamentChange) { changes = append(changes, c...) })
+	a := &app.App{
+		Store:    store,
+		Geocoder: &geocoding.Chain{Strategy: geocoding.FirstSuccess, Providers: []geocoding.Provider{rennesProvider{}}},
+		Now:      time.Now,
+	}
+
+	succeeded, failed, err := tournamentgeocoding.RegeocodeFailed(context.Background(), a)
+	require.NoError(t, err)
+	assert.Equal(t, 2, succeeded)
+	assert.Equal(t, 1, failed)
+	require.Len(t, changes, 2, "only geocoded tournaments changed")
+	assert.Equal(t, cache.ChangeUpdated, changes[0].Kind)
+	assert.True(t, changes[0].Before.NeedsGeocoding())
+	assert.Equal(t, 48.11, changes[0].After.Address.Latitude)
+
+	changes = nil
+	tournament, err := tournamentgeocoding.Regeocode(context.Background(), a, 1)
+	require.NoError(t, err)
+	assert.Equal(t, -1.68, tournament.Address.Longitude)
+	require.Len(t, changes, 1)
+	assert.Equal(t, 1, changes[0].After.ID)
+}
@@ -0,0 +1,113 @@
+// Package graphql exposes the tournament cache through a GraphQL schema with cursor
+// pagination, query depth and complexity limits, and persisted queries.
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"tournois-tt/api/internal/app"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// Request is a GraphQL request as sent over HTTP
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+	Extensions    Extensions     `json:"extensions"`
+}
+
+// Extensions holds the request extensions understood by the server
+type Extensions struct {
+	PersistedQuery *PersistedQuery `json:"persistedQuery,omitempty"`
+}
+
+// PersistedQuery references a query by the SHA-256 hash of its text
+type PersistedQuery struct {
+	Version    int    `json:"version"`
+	SHA256Hash string `json:"sha256Hash"`
+}
+
+// Error codes reported in error extensions
+const (
+	codePersistedQueryNotFound    = "PERSISTED_QUERY_NOT_FOUND"
+	codePersistedQueryUnsupported = "PERSISTED_QUERY_NOT_SUPPORTED"
+	codePersistedQueryMismatch    = "PERSISTED_QUERY_HASH_MISMATCH"
+	codeQueryTooComplex           = "QUERY_TOO_COMPLEX"
+	codeBadRequest                = "BAD_REQUEST"
+)
+
+// Execute runs a GraphQL request against the tournaments of the application a. Queries are
+// parsed, validated and checked against the depth and complexity limits before being
+// executed.
+func Execute(ctx context.Context, a *app.App, req Request) *gql.Result {
+	s, err := Schema()
+	if err != nil {
+		return errorResult(err.Error(), "")
+	}
+
+	query := req.Query
+	if pq := req.Extensions.PersistedQuery; pq != nil {
+		if pq.Version != 1 {
+			return errorResult("unsupported persisted query version", codePersistedQueryUnsupported)
+		}
+		if query == "" {
+			stored, ok := persisted.get(pq.SHA256Hash)
+			if !ok {
+				return errorResult("PersistedQueryNotFound", codePersistedQueryNotFound)
+			}
+			query = stored
+		} else if hashQuery(query) != pq.SHA256Hash {
+			return errorResult("provided sha256Hash does not match query", codePersistedQueryMismatch)
+		}
+	}
+	if query == "" {
+		return errorResult("must provide a query", codeBadRequest)
+	}
+
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return &gql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	if validation := gql.ValidateDocument(&s, document, nil); !validation.IsValid {
+		return &gql.Result{Errors: validation.Errors}
+	}
+
+	if err := checkLimits(document, req.Variables); err != nil {
+		return errorResult(err.Error(), codeQueryTooComplex)
+	}
+
+	// Only register queries that are valid and within limits
+	if pq := req.Extensions.PersistedQuery; pq != nil && req.Query != "" {
+		persisted.put(pq.SHA256Hash, query)
+	}
+
+	return gql.Execute(gql.ExecuteParams{
+		Schema:        s,
+		AST:           document,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       context.WithValue(ctx, appKey{}, a),
+	})
+}
+
+// hashQuery returns the hex encoded SHA-256 hash identifying a persisted query
+func hashQuery(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// errorResult builds a result holding a single error with an optional extension code
+func errorResult(message, code string) *gql.Result {
+	err := gqlerrors.NewFormattedError(message)
+	if code != "" {
+		err.Extensions = map[string]any{"code": code}
+	}
+	return &gql.Result{Errors: []gqlerrors.FormattedError{err}}
+}
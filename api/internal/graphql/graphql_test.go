@@ -0,0 +1,184 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"tournois-tt/api/internal/app"
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/geocoding"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedStore returns a store holding a small fixture
+func seedStore(t *testing.T) *cache.Store {
+	t.Helper()
+
+	upcoming := time.Now().AddDate(0, 1, 0).Format("2006-01-02T15:04:05")
+
+	store := cache.NewStore(t.TempDir())
+	for _, tournament := range []cache.TournamentCache{
+		{
+			ID: 1, Name: "Tournoi de Rennes", Type: "P", StartDate: upcoming, EndDate: upcoming,
+			Address: geocoding.Address{PostalCode: "35000", AddressLocality: "Rennes", Latitude: 48.11, Longitude: -1.67},
+			Club:    cache.Club{ID: 42, Name: "TT Rennes"},
+			Tables:  []cache.Table{{Name: "A", Fee: 8, Endowment: 100}},
+		},
+		{
+			ID: 2, Name: "Tournoi de Cesson", Type: "P", StartDate: upcoming, EndDate: upcoming,
+			Address: geocoding.Address{PostalCode: "35510", AddressLocality: "Cesson-Sévigné", Latitude: 48.12, Longitude: -1.60},
+			Club:    cache.Club{ID: 43, Name: "Cesson TT"},
+		},
+		{
+			ID: 3, Name: "Tournoi de Brest", Type: "R", StartDate: upcoming, EndDate: upcoming,
+			Address: geocoding.Address{PostalCode: "29200", AddressLocality: "Brest", Latitude: 48.39, Longitude: -4.49},
+			Club:    cache.Club{ID: 42, Name: "TT Rennes"},
+		},
+		{
+			ID: 4, Name: "Ancien tournoi", Type: "P", StartDate: "2020-10-04T00:00:00", EndDate: "2020-10-04T00:00:00",
+			Address: geocoding.Address{PostalCode: "35000", AddressLocality: "Rennes", Latitude: 48.11, Longitude: -1.67},
+			Club:    cache.Club{ID: 42, Name: "TT Rennes"},
+		},
+	} {
+		store.Set(tournament)
+	}
+	return store
+}
+
+// run executes a request and returns its data decoded as a generic JSON value
+func run(t *testing.T, store *cache.Store, req Request) (map[string]any, *gql.Result) {
+	t.Helper()
+
+	result := Execute(context.Background(), &app.App{Store: store, Now: time.Now}, req)
+	raw, err := json.Marshal(result.Data)
+	require.NoError(t, err)
+
+	var data map[string]any
+	require.NoError(t, json.Unmarshal(raw, &data))
+	return data, result
+}
+
+func TestTournamentsFiltersAndPagination(t *testing.T) {
+	store := seedStore(t)
+
+	query := `query($after: String) {
+		tournaments(postalCode: "35", first: 1, after: $after) {
+			totalCount
+			edges { cursor node { id } }
+			pageInfo { hasNextPage endCursor }
+		}
+	}`
+
+	data, result := run(t, store, Request{Query: query})
+	require.Empty(t, result.Errors)
+
+	connection := data["tournaments"].(map[string]any)
+	assert.EqualValues(t, 3, connection["totalCount"])
+	edges := connection["edges"].([]any)
+	require.Len(t, edges, 1)
+	assert.EqualValues(t, 4, edges[0].(map[string]any)["node"].(map[string]any)["id"])
+
+	pageInfo := connection["pageInfo"].(map[string]any)
+	assert.Equal(t, true, pageInfo["hasNextPage"])
+
+	data, result = run(t, store, Request{Query: query, Variables: map[string]any{"after": pageInfo["endCursor"]}})
+	require.Empty(t, result.Errors)
+	edges = data["tournaments"].(map[string]any)["edges"].([]any)
+	require.Len(t, edges, 1)
+	assert.EqualValues(t, 1, edges[0].(map[string]any)["node"].(map[string]any)["id"])
+
+	_, result = run(t, store, Request{Query: `{ tournaments(after: "nope") { totalCount } }`})
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0].Message, "invalid cursor")
+
+	_, result = run(t, store, Request{Query: `{ tournaments(first: 1000) { totalCount } }`})
+	require.Len(t, result.Errors, 1)
+}
+
+func TestTournamentRelations(t *testing.T) {
+	store := seedStore(t)
+
+	data, result := run(t, store, Request{Query: `{
+		tournament(id: 1) {
+			tables { fee }
+			club { name tournaments(upcoming: true) { totalCount } }
+			nearby(radiusKm: 20) { distanceKm tournament { id } }
+		}
+	}`})
+	require.Empty(t, result.Errors)
+
+	tournament := data["tournament"].(map[string]any)
+	assert.EqualValues(t, 8, tournament["tables"].([]any)[0].(map[string]any)["fee"])
+
+	club := tournament["club"].(map[string]any)
+	assert.Equal(t, "TT Rennes", club["name"])
+	assert.EqualValues(t, 2, club["tournaments"].(map[string]any)["totalCount"])
+
+	// Brest is too far and the old Rennes tournament has ended
+	nearby := tournament["nearby"].([]any)
+	require.Len(t, nearby, 1)
+	assert.EqualValues(t, 2, nearby[0].(map[string]any)["tournament"].(map[string]any)["id"])
+}
+
+func TestResolversUseTheAppClock(t *testing.T) {
+	a := &app.App{Store: seedStore(t), Now: func() time.Time { return time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC) }}
+
+	result := Execute(context.Background(), a, Request{Query: `{
+		season { label }
+		club(id: 42) { tournaments(upcoming: true) { totalCount } }
+	}`})
+	require.Empty(t, result.Errors)
+
+	data := result.Data.(map[string]any)
+	assert.Equal(t, "2020-2021", data["season"].(map[string]any)["label"])
+	assert.EqualValues(t, 3, data["club"].(map[string]any)["tournaments"].(map[string]any)["totalCount"],
+		"the tournament of October 2020 is upcoming in September")
+}
+
+func TestLimits(t *testing.T) {
+	store := seedStore(t)
+
+	deep := `{ tournaments { edges { node { club { tournaments { edges { node { club { tournaments { edges { node { id } } } } } } } } } } } }`
+	_, result := run(t, store, Request{Query: deep})
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0].Message, "depth")
+	assert.Equal(t, codeQueryTooComplex, result.Errors[0].Extensions["code"])
+
+	wide := `query($n: Int) { tournaments(first: 100) { edges { node { nearby(first: $n) { tournament { id name } } } } } }`
+	_, result = run(t, store, Request{Query: wide, Variables: map[string]any{"n": float64(50)}})
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0].Message, "complexity")
+
+	_, result = run(t, store, Request{Query: wide, Variables: map[string]any{"n": float64(5)}})
+	assert.Empty(t, result.Errors)
+}
+
+func TestPersistedQueries(t *testing.T) {
+	store := seedStore(t)
+
+	query := `{ tournament(id: 2) { name } }`
+	extensions := Extensions{PersistedQuery: &PersistedQuery{Version: 1, SHA256Hash: hashQuery(query)}}
+
+	_, result := run(t, store, Request{Extensions: extensions})
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, codePersistedQueryNotFound, result.Errors[0].Extensions["code"])
+
+	mismatch := Extensions{PersistedQuery: &PersistedQuery{Version: 1, SHA256Hash: strings.Repeat("0", 64)}}
+	_, result = run(t, store, Request{Query: query, Extensions: mismatch})
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, codePersistedQueryMismatch, result.Errors[0].Extensions["code"])
+
+	data, result := run(t, store, Request{Query: query, Extensions: extensions})
+	require.Empty(t, result.Errors)
+	assert.Equal(t, "Tournoi de Cesson", data["tournament"].(map[string]any)["name"])
+
+	data, result = run(t, store, Request{Extensions: extensions})
+	require.Empty(t, result.Errors)
+	assert.Equal(t, "Tournoi de Cesson", data["tournament"].(map[string]any)["name"])
+}
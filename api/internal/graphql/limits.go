@@ -0,0 +1,134 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Query limits
+const (
+	// MaxDepth is the maximum nesting of fields in a query
+	MaxDepth = 10
+	// MaxComplexity is the maximum number of fields a query may resolve, counting
+	// each field of a list once per requested item
+	MaxComplexity = 5000
+)
+
+// listFieldSizes are the default number of items returned by list fields accepting "first"
+var listFieldSizes = map[string]int{
+	"tournaments": defaultPageSize,
+	"clubs":       defaultPageSize,
+	"nearby":      defaultNearbyCount,
+}
+
+// analyzer measures the depth and complexity of the operations of a document
+type analyzer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+}
+
+// checkLimits rejects documents with an operation exceeding MaxDepth or MaxComplexity.
+// Introspection fields are not counted as the schema is finite.
+func checkLimits(document *ast.Document, variables map[string]any) error {
+	a := analyzer{fragments: make(map[string]*ast.FragmentDefinition), variables: variables}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			a.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		complexity, depth := a.measure(operation.SelectionSet, 1)
+		if depth > MaxDepth {
+			return fmt.Errorf("query depth %d exceeds the maximum of %d", depth, MaxDepth)
+		}
+		if complexity > MaxComplexity {
+			return fmt.Errorf("query complexity %d exceeds the maximum of %d", complexity, MaxComplexity)
+		}
+	}
+	return nil
+}
+
+// measure returns the complexity and maximum depth of a selection set found at depth
+func (a analyzer) measure(set *ast.SelectionSet, depth int) (int, int) {
+	if set == nil {
+		return 0, depth - 1
+	}
+
+	complexity, maxDepth := 0, depth
+	for _, selection := range set.Selections {
+		var (
+			cost      int
+			reached   int
+			nestedSet *ast.SelectionSet
+			field     *ast.Field
+		)
+
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name.Value, "__") {
+				continue
+			}
+			field = s
+			nestedSet = s.SelectionSet
+		case *ast.InlineFragment:
+			nestedSet = s.SelectionSet
+		case *ast.FragmentSpread:
+			fragment, ok := a.fragments[s.Name.Value]
+			if !ok {
+				continue
+			}
+			nestedSet = fragment.SelectionSet
+		}
+
+		if field != nil {
+			childCost, childDepth := a.measure(nestedSet, depth+1)
+			cost = 1 + a.multiplier(field)*childCost
+			reached = childDepth
+		} else {
+			// Fragments are inlined at the current depth
+			cost, reached = a.measure(nestedSet, depth)
+		}
+
+		complexity += cost
+		if reached > maxDepth {
+			maxDepth = reached
+		}
+	}
+	return complexity, maxDepth
+}
+
+// multiplier returns the number of items a list field may return
+func (a analyzer) multiplier(field *ast.Field) int {
+	size, isList := listFieldSizes[field.Name.Value]
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "first" {
+			continue
+		}
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(value.Value); err == nil {
+				size, isList = n, true
+			}
+		case *ast.Variable:
+			switch n := a.variables[value.Name.Value].(type) {
+			case float64:
+				size, isList = int(n), true
+			case int:
+				size, isList = n, true
+			}
+		}
+	}
+
+	if !isList || size < 1 {
+		return 1
+	}
+	return size
+}
@@ -0,0 +1,110 @@
+package graphql
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	gql "github.com/graphql-go/graphql"
+)
+
+// Pagination defaults for connections
+const (
+	defaultPageSize = 30
+	maxPageSize     = 100
+)
+
+// cursorPrefix is prepended to offsets before encoding them as opaque cursors
+const cursorPrefix = "offset:"
+
+// connection is the source value of the *Connection types
+type connection struct {
+	Edges      []edge   `json:"edges"`
+	PageInfo   pageInfo `json:"pageInfo"`
+	TotalCount int      `json:"totalCount"`
+}
+
+type edge struct {
+	Cursor string `json:"cursor"`
+	Node   any    `json:"node"`
+}
+
+type pageInfo struct {
+	HasNextPage     bool   `json:"hasNextPage"`
+	HasPreviousPage bool   `json:"hasPreviousPage"`
+	StartCursor     string `json:"startCursor,omitempty"`
+	EndCursor       string `json:"endCursor,omitempty"`
+}
+
+// withPagination adds the first/after connection arguments
+func withPagination(args gql.FieldConfigArgument) gql.FieldConfigArgument {
+	args["first"] = &gql.ArgumentConfig{
+		Type:         gql.Int,
+		DefaultValue: defaultPageSize,
+		Description:  fmt.Sprintf("Page size, at most %d", maxPageSize),
+	}
+	args["after"] = &gql.ArgumentConfig{
+		Type:        gql.String,
+		Description: "Cursor of the last edge of the previous page",
+	}
+	return args
+}
+
+// paginate returns the page of items selected by the first/after arguments
+func paginate[T any](items []T, args map[string]any) (connection, error) {
+	first, _ := args["first"].(int)
+	if first < 1 || first > maxPageSize {
+		return connection{}, fmt.Errorf("first must be between 1 and %d", maxPageSize)
+	}
+
+	start := 0
+	if after, _ := args["after"].(string); after != "" {
+		offset, err := decodeCursor(after)
+		if err != nil {
+			return connection{}, err
+		}
+		start = offset + 1
+	}
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + first
+	if end > len(items) {
+		end = len(items)
+	}
+
+	result := connection{
+		Edges:      make([]edge, 0, end-start),
+		TotalCount: len(items),
+		PageInfo: pageInfo{
+			HasNextPage:     end < len(items),
+			HasPreviousPage: start > 0,
+		},
+	}
+	for i := start; i < end; i++ {
+		result.Edges = append(result.Edges, edge{Cursor: encodeCursor(i), Node: items[i]})
+	}
+	if len(result.Edges) > 0 {
+		result.PageInfo.StartCursor = result.Edges[0].Cursor
+		result.PageInfo.EndCursor = result.Edges[len(result.Edges)-1].Cursor
+	}
+
+	return result, nil
+}
+
+func encodeCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return offset, nil
+}
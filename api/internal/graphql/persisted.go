@@ -0,0 +1,37 @@
+package graphql
+
+import "sync"
+
+// maxPersistedQueries bounds the number of registered persisted queries
+const maxPersistedQueries = 1000
+
+// persistedQueries stores registered queries by hash, evicting the oldest ones first
+type persistedQueries struct {
+	sync.Mutex
+	queries map[string]string
+	order   []string
+}
+
+var persisted = &persistedQueries{queries: make(map[string]string)}
+
+func (p *persistedQueries) get(hash string) (string, bool) {
+	p.Lock()
+	defer p.Unlock()
+	query, ok := p.queries[hash]
+	return query, ok
+}
+
+func (p *persistedQueries) put(hash, query string) {
+	p.Lock()
+	defer p.Unlock()
+
+	if _, ok := p.queries[hash]; ok {
+		return
+	}
+	if len(p.order) >= maxPersistedQueries {
+		delete(p.queries, p.order[0])
+		p.order = p.order[1:]
+	}
+	p.queries[hash] = query
+	p.order = append(p.order, hash)
+}
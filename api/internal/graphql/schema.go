@@ -0,0 +1,552 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"tournois-tt/api/internal/app"
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/clubs"
+	"tournois-tt/api/pkg/geocoding"
+	"tournois-tt/api/pkg/stats"
+	"tournois-tt/api/pkg/utils"
+
+	gql "github.com/graphql-go/graphql"
+)
+
+// Nearby search defaults
+const (
+	defaultNearbyRadiusKm = 30.0
+	defaultNearbyCount    = 10
+	maxNearbyCount        = 50
+)
+
+// season is the source value of the Season type
+type season struct {
+	Label string
+	Start time.Time
+	End   time.Time
+}
+
+// nearbyTournament is the source value of the NearbyTournament type
+type nearbyTournament struct {
+	DistanceKm float64               `json:"distanceKm"`
+	Tournament cache.TournamentCache `json:"tournament"`
+}
+
+// seasonCount is the source value of the SeasonCount type
+type seasonCount struct {
+	Season string `json:"season"`
+	Count  int    `json:"count"`
+}
+
+var (
+	schema     gql.Schema
+	schemaErr  error
+	schemaOnce sync.Once
+)
+
+// appKey is the context key of the application queried by the resolvers
+type appKey struct{}
+
+// storeOf returns the store of the request being resolved
+func storeOf(p gql.ResolveParams) *cache.Store {
+	return p.Context.Value(appKey{}).(*app.App).Store
+}
+
+// nowOf returns the time of the application of the request being resolved
+func nowOf(p gql.ResolveParams) time.Time {
+	return p.Context.Value(appKey{}).(*app.App).Now()
+}
+
+// Schema returns the GraphQL schema over the tournaments. It is shared by all the
+// applications, which resolvers find in the context.
+func Schema() (gql.Schema, error) {
+	schemaOnce.Do(func() {
+		schema, schemaErr = newSchema()
+	})
+	return schema, schemaErr
+}
+
+func newSchema() (gql.Schema, error) {
+	addressType := gql.NewObject(gql.ObjectConfig{
+		Name:        "Address",
+		Description: "Postal address of a tournament venue, with its coordinates when geocoded",
+		Fields: gql.Fields{
+			"streetAddress":             &gql.Field{Type: gql.String},
+			"postalCode":                &gql.Field{Type: gql.String},
+			"addressLocality":           &gql.Field{Type: gql.String},
+			"disambiguatingDescription": &gql.Field{Type: gql.String},
+			"latitude": &gql.Field{
+				Type: gql.Float,
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					if address := p.Source.(geocoding.Address); isGeocoded(address) {
+						return address.Latitude, nil
+					}
+					return nil, nil
+				},
+			},
+			"longitude": &gql.Field{
+				Type: gql.Float,
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					if address := p.Source.(geocoding.Address); isGeocoded(address) {
+						return address.Longitude, nil
+					}
+					return nil, nil
+				},
+			},
+			"geocoded": &gql.Field{
+				Type: gql.NewNonNull(gql.Boolean),
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					return isGeocoded(p.Source.(geocoding.Address)), nil
+				},
+			},
+		},
+	})
+
+	tableType := gql.NewObject(gql.ObjectConfig{
+		Name:        "Table",
+		Description: "A table (event) of a tournament",
+		Fields: gql.Fields{
+			"name":        &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"description": &gql.Field{Type: gql.String},
+			"date":        &gql.Field{Type: gql.String},
+			"time":        &gql.Field{Type: gql.String},
+			"fee":         &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			"endowment":   &gql.Field{Type: gql.NewNonNull(gql.Int)},
+		},
+	})
+
+	rulesType := gql.NewObject(gql.ObjectConfig{
+		Name:        "Rules",
+		Description: "Eligibility rules of a tournament",
+		Fields: gql.Fields{
+			"ageMin":  &gql.Field{Type: gql.Int},
+			"ageMax":  &gql.Field{Type: gql.Int},
+			"points":  &gql.Field{Type: gql.Int},
+			"ranking": &gql.Field{Type: gql.Int},
+			"url":     &gql.Field{Type: gql.String},
+		},
+	})
+
+	figuresType := gql.NewObject(gql.ObjectConfig{
+		Name:        "Figures",
+		Description: "Aggregated figures of a group of tournaments",
+		Fields: gql.Fields{
+			"count":           &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			"totalEndowment":  &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			"medianEndowment": &gql.Field{Type: gql.NewNonNull(gql.Float)},
+			"averageTableFee": &gql.Field{Type: gql.NewNonNull(gql.Float)},
+			"clubs":           &gql.Field{Type: gql.NewNonNull(gql.Int)},
+		},
+	})
+
+	venueType := gql.NewObject(gql.ObjectConfig{
+		Name:        "Venue",
+		Description: "Usual location of a club's tournaments",
+		Fields: gql.Fields{
+			"latitude":    &gql.Field{Type: gql.NewNonNull(gql.Float)},
+			"longitude":   &gql.Field{Type: gql.NewNonNull(gql.Float)},
+			"tournaments": &gql.Field{Type: gql.NewNonNull(gql.Int)},
+		},
+	})
+
+	seasonCountType := gql.NewObject(gql.ObjectConfig{
+		Name: "SeasonCount",
+		Fields: gql.Fields{
+			"season": &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"count":  &gql.Field{Type: gql.NewNonNull(gql.Int)},
+		},
+	})
+
+	orderType := gql.NewEnum(gql.EnumConfig{
+		Name: "SortOrder",
+		Values: gql.EnumValueConfigMap{
+			"ASC":  &gql.EnumValueConfig{Value: "asc"},
+			"DESC": &gql.EnumValueConfig{Value: "desc"},
+		},
+	})
+
+	var (
+		tournamentType           *gql.Object
+		clubType                 *gql.Object
+		seasonType               *gql.Object
+		tournamentConnectionType *gql.Object
+		clubConnectionType       *gql.Object
+	)
+
+	pageInfoType := gql.NewObject(gql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: gql.Fields{
+			"hasNextPage":     &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+			"hasPreviousPage": &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+			"startCursor":     &gql.Field{Type: gql.String},
+			"endCursor":       &gql.Field{Type: gql.String},
+		},
+	})
+
+	newConnectionType := func(name string, node func() *gql.Object) *gql.Object {
+		edgeType := gql.NewObject(gql.ObjectConfig{
+			Name: name + "Edge",
+			Fields: gql.FieldsThunk(func() gql.Fields {
+				return gql.Fields{
+					"cursor": &gql.Field{Type: gql.NewNonNull(gql.String)},
+					"node":   &gql.Field{Type: gql.NewNonNull(node())},
+				}
+			}),
+		})
+		return gql.NewObject(gql.ObjectConfig{
+			Name: name + "Connection",
+			Fields: gql.Fields{
+				"edges":      &gql.Field{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(edgeType)))},
+				"pageInfo":   &gql.Field{Type: gql.NewNonNull(pageInfoType)},
+				"totalCount": &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			},
+		})
+	}
+	tournamentConnectionType = newConnectionType("Tournament", func() *gql.Object { return tournamentType })
+	clubConnectionType = newConnectionType("Club", func() *gql.Object { return clubType })
+
+	// tournamentsField lists cached tournaments matching the REST filters, optionally
+	// restricted by the parent value (a season or a club)
+	tournamentsField := func(restrict func(p gql.ResolveParams, f *cache.TournamentFilter)) *gql.Field {
+		return &gql.Field{
+			Type:        gql.NewNonNull(tournamentConnectionType),
+			Description: "Tournaments sorted by start date, then id",
+			Args: withPagination(gql.FieldConfigArgument{
+				"postalCode":      &gql.ArgumentConfig{Type: gql.String, Description: "Postal code prefix"},
+				"addressLocality": &gql.ArgumentConfig{Type: gql.String},
+				"type":            &gql.ArgumentConfig{Type: gql.String},
+				"department":      &gql.ArgumentConfig{Type: gql.String, Description: "INSEE department code"},
+				"region":          &gql.ArgumentConfig{Type: gql.String, Description: "Official region name"},
+				"startDateAfter":  &gql.ArgumentConfig{Type: gql.String, Description: "Inclusive lower bound (YYYY-MM-DD)"},
+				"startDateBefore": &gql.ArgumentConfig{Type: gql.String, Description: "Inclusive upper bound (YYYY-MM-DD)"},
+				"upcoming":        &gql.ArgumentConfig{Type: gql.Boolean, Description: "Only tournaments that have not ended yet"},
+				"order":           &gql.ArgumentConfig{Type: orderType, DefaultValue: "asc"},
+			}),
+			Resolve: func(p gql.ResolveParams) (any, error) {
+				filter, err := tournamentFilter(p.Args, nowOf(p))
+				if err != nil {
+					return nil, err
+				}
+				if restrict != nil {
+					restrict(p, &filter)
+				}
+
+				order, _ := p.Args["order"].(string)
+				return paginate(cache.FilterTournaments(storeOf(p).All(), filter, order == "desc"), p.Args)
+			},
+		}
+	}
+
+	seasonType = gql.NewObject(gql.ObjectConfig{
+		Name:        "Season",
+		Description: "A sports season, from July 1st to June 30th",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"label": &gql.Field{
+					Type: gql.NewNonNull(gql.String),
+					Resolve: func(p gql.ResolveParams) (any, error) {
+						return p.Source.(season).Label, nil
+					},
+				},
+				"startDate": &gql.Field{
+					Type: gql.NewNonNull(gql.String),
+					Resolve: func(p gql.ResolveParams) (any, error) {
+						return p.Source.(season).Start.Format("2006-01-02"), nil
+					},
+				},
+				"endDate": &gql.Field{
+					Type: gql.NewNonNull(gql.String),
+					Resolve: func(p gql.ResolveParams) (any, error) {
+						return p.Source.(season).End.Format("2006-01-02"), nil
+					},
+				},
+				"figures": &gql.Field{
+					Type: gql.NewNonNull(figuresType),
+					Resolve: func(p gql.ResolveParams) (any, error) {
+						seasonStats, err := stats.ForSeason(storeOf(p), p.Source.(season).Label)
+						if err != nil {
+							return nil, fmt.Errorf("failed to compute stats")
+						}
+						return seasonStats.Total, nil
+					},
+				},
+				"tournaments": tournamentsField(func(p gql.ResolveParams, f *cache.TournamentFilter) {
+					s := p.Source.(season)
+					if f.StartAfter.Before(s.Start) {
+						f.StartAfter = s.Start
+					}
+					if f.StartBefore.IsZero() || f.StartBefore.After(s.End) {
+						f.StartBefore = s.End
+					}
+				}),
+			}
+		}),
+	})
+
+	nearbyType := gql.NewObject(gql.ObjectConfig{
+		Name:        "NearbyTournament",
+		Description: "A tournament close to another one",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"distanceKm": &gql.Field{Type: gql.NewNonNull(gql.Float)},
+				"tournament": &gql.Field{Type: gql.NewNonNull(tournamentType)},
+			}
+		}),
+	})
+
+	tournamentType = gql.NewObject(gql.ObjectConfig{
+		Name:        "Tournament",
+		Description: "A table tennis tournament",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"id":        &gql.Field{Type: gql.NewNonNull(gql.Int)},
+				"name":      &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"type":      &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"startDate": &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"endDate":   &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"page":      &gql.Field{Type: gql.String},
+				"endowment": &gql.Field{Type: gql.NewNonNull(gql.Int)},
+				"cancelled": &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+				"address":   &gql.Field{Type: gql.NewNonNull(addressType)},
+				"rules":     &gql.Field{Type: rulesType},
+				"tables": &gql.Field{
+					Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(tableType))),
+					Resolve: func(p gql.ResolveParams) (any, error) {
+						tables := p.Source.(cache.TournamentCache).Tables
+						if tables == nil {
+							tables = []cache.Table{}
+						}
+						return tables, nil
+					},
+				},
+				"club": &gql.Field{
+					Type: clubType,
+					Resolve: func(p gql.ResolveParams) (any, error) {
+						return resolveClub(storeOf(p), p.Source.(cache.TournamentCache).Club.ID, nowOf(p))
+					},
+				},
+				"season": &gql.Field{
+					Type: seasonType,
+					Resolve: func(p gql.ResolveParams) (any, error) {
+						startDate, ok := utils.ParseTournamentDate(p.Source.(cache.TournamentCache).StartDate)
+						if !ok {
+							return nil, nil
+						}
+						return seasonOf(utils.SeasonLabel(startDate))
+					},
+				},
+				"nearby": &gql.Field{
+					Type:        gql.NewNonNull(gql.NewList(gql.NewNonNull(nearbyType))),
+					Description: "Upcoming geocoded tournaments within a radius, closest first",
+					Args: gql.FieldConfigArgument{
+						"radiusKm": &gql.ArgumentConfig{Type: gql.Float, DefaultValue: defaultNearbyRadiusKm},
+						"first":    &gql.ArgumentConfig{Type: gql.Int, DefaultValue: defaultNearbyCount},
+					},
+					Resolve: func(p gql.ResolveParams) (any, error) {
+						radius, _ := p.Args["radiusKm"].(float64)
+						first, _ := p.Args["first"].(int)
+						if first < 1 || first > maxNearbyCount {
+							return nil, fmt.Errorf("first must be between 1 and %d", maxNearbyCount)
+						}
+						return nearby(storeOf(p), p.Source.(cache.TournamentCache), radius, first, nowOf(p))
+					},
+				},
+			}
+		}),
+	})
+
+	clubType = gql.NewObject(gql.ObjectConfig{
+		Name:        "Club",
+		Description: "A club organizing tournaments",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"id":         &gql.Field{Type: gql.NewNonNull(gql.Int)},
+				"name":       &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"code":       &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"identifier": &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"department": &gql.Field{Type: gql.String},
+				"region":     &gql.Field{Type: gql.String},
+				"venue":      &gql.Field{Type: venueType},
+				"tournamentsBySeason": &gql.Field{
+					Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(seasonCountType))),
+					Resolve: func(p gql.ResolveParams) (any, error) {
+						counts := p.Source.(clubs.Club).TournamentsBySeason
+						result := make([]seasonCount, 0, len(counts))
+						for label, count := range counts {
+							result = append(result, seasonCount{Season: label, Count: count})
+						}
+						sort.Slice(result, func(i, j int) bool { return result[i].Season < result[j].Season })
+						return result, nil
+					},
+				},
+				"tournaments": tournamentsField(func(p gql.ResolveParams, f *cache.TournamentFilter) {
+					f.ClubID = p.Source.(clubs.Club).ID
+				}),
+			}
+		}),
+	})
+
+	queryType := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"tournament": &gql.Field{
+				Type: tournamentType,
+				Args: gql.FieldConfigArgument{
+					"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.Int)},
+				},
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					if t, found := storeOf(p).Get(p.Args["id"].(int)); found {
+						return t, nil
+					}
+					return nil, nil
+				},
+			},
+			"tournaments": tournamentsField(nil),
+			"club": &gql.Field{
+				Type: clubType,
+				Args: gql.FieldConfigArgument{
+					"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.Int)},
+				},
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					return resolveClub(storeOf(p), p.Args["id"].(int), nowOf(p))
+				},
+			},
+			"clubs": &gql.Field{
+				Type:        gql.NewNonNull(clubConnectionType),
+				Description: "Organizing clubs sorted by name",
+				Args: withPagination(gql.FieldConfigArgument{
+					"department": &gql.ArgumentConfig{Type: gql.String, Description: "INSEE department code"},
+					"region":     &gql.ArgumentConfig{Type: gql.String, Description: "Official region name"},
+				}),
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					allClubs := clubs.List(storeOf(p), nowOf(p))
+
+					department, _ := p.Args["department"].(string)
+					region, _ := p.Args["region"].(string)
+
+					filtered := make([]clubs.Club, 0, len(allClubs))
+					for _, club := range allClubs {
+						if department != "" && club.Department != department {
+							continue
+						}
+						if region != "" && club.Region != region {
+							continue
+						}
+						filtered = append(filtered, club)
+					}
+					return paginate(filtered, p.Args)
+				},
+			},
+			"season": &gql.Field{
+				Type:        gql.NewNonNull(seasonType),
+				Description: "A season by label (e.g. \"2025-2026\"), defaulting to the current one",
+				Args: gql.FieldConfigArgument{
+					"label": &gql.ArgumentConfig{Type: gql.String},
+				},
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					label, _ := p.Args["label"].(string)
+					if label == "" {
+						label = utils.SeasonLabel(nowOf(p))
+					}
+					return seasonOf(label)
+				},
+			},
+		},
+	})
+
+	return gql.NewSchema(gql.SchemaConfig{Query: queryType})
+}
+
+// tournamentFilter converts the tournament filter arguments, upcoming tournaments ending
+// after the day of now
+func tournamentFilter(args map[string]any, now time.Time) (cache.TournamentFilter, error) {
+	var filter cache.TournamentFilter
+	filter.PostalCode, _ = args["postalCode"].(string)
+	filter.Locality, _ = args["addressLocality"].(string)
+	filter.Type, _ = args["type"].(string)
+	filter.Department, _ = args["department"].(string)
+	filter.Region, _ = args["region"].(string)
+
+	for name, bound := range map[string]*time.Time{
+		"startDateAfter":  &filter.StartAfter,
+		"startDateBefore": &filter.StartBefore,
+	} {
+		raw, _ := args[name].(string)
+		if raw == "" {
+			continue
+		}
+		date, ok := utils.ParseTournamentDate(raw)
+		if !ok {
+			return filter, fmt.Errorf("%s must be a date (YYYY-MM-DD)", name)
+		}
+		*bound = date
+	}
+
+	if upcoming, _ := args["upcoming"].(bool); upcoming {
+		filter.EndsAfter = day(now)
+	}
+
+	return filter, nil
+}
+
+// resolveClub returns a club by id as of now, or nil when it does not organize any stored
+// tournament
+func resolveClub(store *cache.Store, id int, now time.Time) (any, error) {
+	if id == 0 {
+		return nil, nil
+	}
+	club, found := clubs.Get(store, id, now)
+	if !found {
+		return nil, nil
+	}
+	club.History = nil
+	return club, nil
+}
+
+// seasonOf returns the season with the given label
+func seasonOf(label string) (any, error) {
+	start, end, err := utils.ParseSeason(label)
+	if err != nil {
+		return nil, err
+	}
+	return season{Label: label, Start: start, End: end}, nil
+}
+
+// nearby returns the geocoded tournaments within radius km of t and upcoming at now, closest
+// first
+func nearby(store *cache.Store, t cache.TournamentCache, radius float64, limit int, now time.Time) ([]nearbyTournament, error) {
+	result := []nearbyTournament{}
+	if !isGeocoded(t.Address) {
+		return result, nil
+	}
+
+	for _, candidate := range cache.FilterTournaments(store.All(), cache.TournamentFilter{EndsAfter: day(now)}, false) {
+		if candidate.ID == t.ID || !isGeocoded(candidate.Address) {
+			continue
+		}
+		distance := utils.DistanceKm(t.Address.Latitude, t.Address.Longitude, candidate.Address.Latitude, candidate.Address.Longitude)
+		if distance <= radius {
+			result = append(result, nearbyTournament{DistanceKm: distance, Tournament: candidate})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].DistanceKm < result[j].DistanceKm })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// isGeocoded reports whether an address has usable coordinates
+func isGeocoded(address geocoding.Address) bool {
+	return !address.Failed && (address.Latitude != 0 || address.Longitude != 0)
+}
+
+// day returns the start of the day of now in UTC, matching how tournament dates are parsed
+func day(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tournois-tt/api/internal/crons"
+	"tournois-tt/api/internal/crons/counters"
+	"tournois-tt/api/internal/crons/tournaments/geocoding"
+	"tournois-tt/api/internal/middleware"
+	"tournois-tt/api/pkg/audit"
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Names of the admin jobs writing tournaments, which hold the lock of the refresh
+const (
+	// regeocodeFailedJob geocodes failed tournaments again
+	regeocodeFailedJob = "regeocode-failed"
+	// regeocodeJob geocodes a tournament again
+	regeocodeJob = "regeocode"
+	// coordinatesJob overrides the coordinates of a tournament
+	coordinatesJob = "set-coordinates"
+)
+
+type adminRefreshRequest struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type coordinatesRequest struct {
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+}
+
+// AdminStatsHandler returns statistics about the tournament store and the geocoders
+func (h *Handlers) AdminStatsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"cache":     h.app.Store.Stats(),
+		"geocoding": h.app.Geocoder.Status(),
+	})
+}
+
+// AdminRefreshHandler starts a refresh of the tournaments starting between two dates
+func (h *Handlers) AdminRefreshHandler(c *gin.Context) {
+	var req adminRefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.From == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from is required"})
+		return
+	}
+	from, err := time.Parse("2006-01-02", req.From)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a YYYY-MM-DD date"})
+		return
+	}
+	var to *time.Time
+	if req.To != "" {
+		parsed, err := time.Parse("2006-01-02", req.To)
+		if err != nil || !parsed.After(from) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a YYYY-MM-DD date after from"})
+			return
+		}
+		to = &parsed
+	}
+
+	// Shares the name of the scheduled refresh so that both never write the cache at once
+	details := map[string]any{"from": req.From, "to": req.To}
+	job := crons.Job{
+		Name:    crons.RefreshTournaments,
+		Timeout: h.app.Config.Refresh.Timeout,
+		Run: func(ctx context.Context) error {
+			return geocoding.RefreshGeocoding(ctx, h.app, &from, to)
+		},
+	}
+	if err := h.runAdminJob(c, job, "refresh", "", details); err != nil {
+		writeJobError(c, err, "a refresh is already running")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"status": audit.OutcomeStarted})
+}
+
+// AdminRegeocodeHandler geocodes the address of a tournament again
+func (h *Handlers) AdminRegeocodeHandler(c *gin.Context) {
+	id, ok := tournamentIDParam(c)
+	if !ok {
+		return
+	}
+
+	var tournament cache.TournamentCache
+	err := h.app.Jobs.Run(c.Request.Context(), crons.Job{
+		Name: regeocodeJob,
+		Lock: crons.RefreshTournaments,
+		Run: func(ctx context.Context) (err error) {
+			tournament, err = geocoding.Regeocode(ctx, h.app, id)
+			return err
+		},
+	})
+	if errors.Is(err, crons.ErrAlreadyRunning) {
+		writeJobError(c, err, "a refresh or geocoding is running, try again later")
+		return
+	}
+	h.auditAction(c, "tournament.geocode", strconv.Itoa(id), nil, outcome(err), err)
+	if err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tournament)
+}
+
+// AdminRegeocodeFailedHandler starts geocoding again every tournament without coordinates
+func (h *Handlers) AdminRegeocodeFailedHandler(c *gin.Context) {
+	// Holds the lock of the refresh, which geocodes with the same providers and writes the
+	// same tournaments
+	job := crons.Job{
+		Name: regeocodeFailedJob,
+		Lock: crons.RefreshTournaments,
+		Run: func(ctx context.Context) error {
+			succeeded, failed, err := geocoding.RegeocodeFailed(ctx, h.app)
+			counters.Add(ctx, "succeeded", int64(succeeded))
+			counters.Add(ctx, "failed", int64(failed))
+			logger.InfoContext(ctx, "Geocoded failed tournaments again", "succeeded", succeeded, "failed", failed)
+			return err
+		},
+	}
+	if err := h.runAdminJob(c, job, "geocode.failed", "", nil); err != nil {
+		writeJobError(c, err, "a refresh or geocoding is already running")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"status": audit.OutcomeStarted})
+}
+
+// AdminCoordinatesHandler overrides the coordinates of a tournament
+func (h *Handlers) AdminCoordinatesHandler(c *gin.Context) {
+	id, ok := tournamentIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req coordinatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Latitude == nil || req.Longitude == nil ||
+		*req.Latitude < -90 || *req.Latitude > 90 || *req.Longitude < -180 || *req.Longitude > 180 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "latitude and longitude are required"})
+		return
+	}
+
+	// Under the lock of the refresh, which would overwrite the coordinates it geocoded
+	var tournament cache.TournamentCache
+	err := h.app.Jobs.Run(c.Request.Context(), crons.Job{
+		Name: coordinatesJob,
+		Lock: crons.RefreshTournaments,
+		Run: func(context.Context) (err error) {
+			tournament, err = h.app.Store.SetCoordinates(id, *req.Latitude, *req.Longitude)
+			return err
+		},
+	})
+	if errors.Is(err, crons.ErrAlreadyRunning) {
+		writeJobError(c, err, "a refresh or geocoding is running, try again later")
+		return
+	}
+	details := map[string]any{"latitude": *req.Latitude, "longitude": *req.Longitude}
+	h.auditAction(c, "tournament.coordinates", strconv.Itoa(id), details, outcome(err), err)
+	if err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tournament)
+}
+
+// AdminPurgeHandler removes a tournament from the store until it is restored
+func (h *Handlers) AdminPurgeHandler(c *gin.Context) {
+	id, ok := tournamentIDParam(c)
+	if !ok {
+		return
+	}
+
+	tournament, err := h.app.Store.Purge(id)
+	h.auditAction(c, "tournament.purge", strconv.Itoa(id), nil, outcome(err), err)
+	if err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tournament)
+}
+
+// AdminRestoreHandler puts a purged tournament back in the store
+func (h *Handlers) AdminRestoreHandler(c *gin.Context) {
+	id, ok := tournamentIDParam(c)
+	if !ok {
+		return
+	}
+
+	tournament, err := h.app.Store.Restore(id)
+	h.auditAction(c, "tournament.restore", strconv.Itoa(id), nil, outcome(err), err)
+	if err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tournament)
+}
+
+// AdminPurgedHandler lists the purged tournaments
+func (h *Handlers) AdminPurgedHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, h.app.Store.Purged())
+}
+
+// AdminAuditHandler returns the most recent audit log entries
+func (h *Handlers) AdminAuditHandler(c *gin.Context) {
+	limit := 100
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 1000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+			return
+		}
+		limit = parsed
+	}
+
+	entries, err := h.app.Audit.Recent(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load audit log"})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// runAdminJob starts a job in the background, through the scheduler so that shutdown waits
+// for it, and records its start and outcome. It returns crons.ErrAlreadyRunning when a run
+// of the same job is going.
+func (h *Handlers) runAdminJob(c *gin.Context, job crons.Job, action, target string, details map[string]any) error {
+	actor := middleware.AdminActorFromContext(c)
+	requestID := logging.RequestID(c.Request.Context())
+
+	run := job.Run
+	job.Run = func(ctx context.Context) error {
+		err := run(ctx)
+		h.record(audit.Entry{Actor: actor, Action: action + ".finished", Target: target, Details: details,
+			Outcome: outcome(err), Error: errorString(err), RequestID: requestID})
+		return err
+	}
+
+	if err := h.app.Jobs.Start(job); err != nil {
+		return err
+	}
+	h.auditAction(c, action, target, details, audit.OutcomeStarted, nil)
+	return nil
+}
+
+// writeJobError responds to a job that could not be started
+func writeJobError(c *gin.Context, err error, conflict string) {
+	if errors.Is(err, crons.ErrAlreadyRunning) {
+		c.JSON(http.StatusConflict, gin.H{"error": conflict})
+		return
+	}
+	c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+}
+
+// AdminJobsHandler returns the recent runs of the background jobs, optionally of one job
+func (h *Handlers) AdminJobsHandler(c *gin.Context) {
+	limit := 100
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 500 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+		limit = parsed
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"running": h.app.Jobs.Running(),
+		"runs":    h.app.Jobs.RecentRuns(c.Query("job"), limit),
+	})
+}
+
+// AdminConfigHandler returns the configuration settings and where they were read from,
+// secrets redacted
+func (h *Handlers) AdminConfigHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"file":     h.app.Config.File(),
+		"settings": h.app.Config.Settings(),
+	})
+}
+
+// auditAction records an admin action in the audit log
+func (h *Handlers) auditAction(c *gin.Context, action, target string, details map[string]any, result string, err error) {
+	h.record(audit.Entry{
+		Actor:     middleware.AdminActorFromContext(c),
+		Action:    action,
+		Target:    target,
+		Details:   details,
+		Outcome:   result,
+		Error:     errorString(err),
+		RequestID: logging.RequestID(c.Request.Context()),
+	})
+}
+
+func (h *Handlers) record(entry audit.Entry) {
+	if err := h.app.Audit.Record(entry); err != nil {
+		logger.Error("Failed to record admin action", "action", entry.Action, "error", err)
+	}
+}
+
+func tournamentIDParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tournament id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func writeAdminError(c *gin.Context, err error) {
+	if errors.Is(err, cache.ErrTournamentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return audit.OutcomeError
+	}
+	return audit.OutcomeOK
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"tournois-tt/api/pkg/clubs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClubsHandler lists organizing clubs, optionally filtered by department or region
+func (h *Handlers) ClubsHandler(c *gin.Context) {
+	allClubs := clubs.List(h.app.Store, h.app.Now())
+
+	department := c.Query("department")
+	region := c.Query("region")
+
+	filtered := make([]clubs.Club, 0, len(allClubs))
+	for _, club := range allClubs {
+		if department != "" && club.Department != department {
+			continue
+		}
+		if region != "" && club.Region != region {
+			continue
+		}
+		filtered = append(filtered, club)
+	}
+
+	c.JSON(http.StatusOK, filtered)
+}
+
+// ClubHandler returns a single organizing club with its tournament history
+func (h *Handlers) ClubHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid club id"})
+		return
+	}
+
+	club, ok := clubs.Get(h.app.Store, id, h.app.Now())
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "club not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, club)
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	_ "embed"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"tournois-tt/api/internal/crons"
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/events"
+	"tournois-tt/api/pkg/tally"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed templates/dashboard.html
+var dashboardTemplateText string
+
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"duration": func(d time.Duration) string { return d.Round(time.Millisecond).String() },
+	"datetime": func(t time.Time) string { return t.Local().Format("02/01/2006 15:04:05") },
+	"percent":  func(v, total int64) int64 { return v * 100 / total },
+}).Parse(dashboardTemplateText))
+
+// Sizes of the dashboard sections
+const (
+	dashboardRuns      = 20
+	dashboardFailed    = 100
+	dashboardEvents    = 30
+	dashboardDays      = 30
+	dashboardRedirects = 10
+)
+
+// failedTournament is a tournament without coordinates, placed on the map near the other
+// tournaments of its club or postal code when possible
+type failedTournament struct {
+	cache.TournamentCache
+	Latitude    float64 `json:"lat"`
+	Longitude   float64 `json:"lng"`
+	Approximate bool    `json:"approximate"`
+}
+
+type redirectClicks struct {
+	ID     string
+	Name   string
+	Clicks int64
+}
+
+type dashboardData struct {
+	Generated  time.Time
+	Runs       []crons.JobRun
+	Failed     []failedTournament
+	Events     []events.Event
+	Signups    []tally.DayCount
+	MaxSignups int64
+	Redirects  []redirectClicks
+	Days       int
+}
+
+// AdminDashboardHandler renders an HTML overview of jobs and data quality for operators
+func (h *Handlers) AdminDashboardHandler(c *gin.Context) {
+	now := h.app.Now()
+	data := dashboardData{
+		Generated: now,
+		Runs:      h.app.Jobs.RecentRuns("", dashboardRuns),
+		Failed:    failedTournaments(h.app.Store.All(), now),
+		Events:    h.app.Events.Recent(dashboardEvents),
+		Days:      dashboardDays,
+	}
+	if h.app.Tally != nil {
+		data.Signups = h.app.Tally.Daily(tally.NewsletterSignups, dashboardDays)
+		for _, day := range data.Signups {
+			data.MaxSignups = max(data.MaxSignups, day.Count)
+		}
+		for _, top := range h.app.Tally.Top(tally.RulesRedirects, dashboardDays, dashboardRedirects) {
+			clicks := redirectClicks{ID: top.Key, Clicks: top.Count}
+			if id, err := strconv.Atoi(top.Key); err == nil {
+				if t, ok := h.app.Store.Get(id); ok {
+					clicks.Name = t.Name
+				}
+			}
+			data.Redirects = append(data.Redirects, clicks)
+		}
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := dashboardTemplate.Execute(c.Writer, data); err != nil {
+		logger.Error("Failed to render admin dashboard", "error", err)
+	}
+}
+
+// failedTournaments returns the tournaments not ended by now that need geocoding, soonest first
+func failedTournaments(tournaments map[string]cache.TournamentCache, now time.Time) []failedTournament {
+	byClub := make(map[int][2]float64)
+	byPostalCode := make(map[string][2]float64)
+	for _, t := range tournaments {
+		if t.NeedsGeocoding() {
+			continue
+		}
+		position := [2]float64{t.Address.Latitude, t.Address.Longitude}
+		if t.Club.ID != 0 {
+			byClub[t.Club.ID] = position
+		}
+		if t.Address.PostalCode != "" {
+			byPostalCode[t.Address.PostalCode] = position
+		}
+	}
+
+	today := now.Format("2006-01-02")
+	var failed []failedTournament
+	for _, t := range tournaments {
+		end := t.EndDate
+		if end == "" {
+			end = t.StartDate
+		}
+		if !t.NeedsGeocoding() || t.Cancelled || end < today {
+			continue
+		}
+		entry := failedTournament{TournamentCache: t}
+		position, ok := byClub[t.Club.ID]
+		if !ok {
+			position, ok = byPostalCode[t.Address.PostalCode]
+		}
+		if ok {
+			entry.Latitude, entry.Longitude, entry.Approximate = position[0], position[1], true
+		}
+		failed = append(failed, entry)
+	}
+
+	sort.Slice(failed, func(i, j int) bool {
+		if failed[i].StartDate != failed[j].StartDate {
+			return failed[i].StartDate < failed[j].StartDate
+		}
+		return failed[i].ID < failed[j].ID
+	})
+	if len(failed) > dashboardFailed {
+		failed = failed[:dashboardFailed]
+	}
+	return failed
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"tournois-tt/api/pkg/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventsHeartbeat is the interval of the comments keeping idle streams open through proxies
+const eventsHeartbeat = 25 * time.Second
+
+// EventsHandler streams tournament changes as Server-Sent Events, optionally filtered by
+// department and type. Clients resume with the Last-Event-ID header (or lastEventId query
+// parameter); a "reset" event tells them to reload when the missed events are no longer
+// buffered, or were sent before the API restarted.
+func (h *Handlers) EventsHandler(c *gin.Context) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+	var epoch string
+	var since uint64
+	if lastEventID != "" {
+		var err error
+		if epoch, since, err = events.ParseStreamID(lastEventID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid Last-Event-ID"})
+			return
+		}
+	}
+
+	department := c.Query("department")
+	tournamentType := c.Query("type")
+	matches := func(event events.Event) bool {
+		return (department == "" || event.Tournament.Club.Department == department) &&
+			(tournamentType == "" || event.Tournament.Type == tournamentType)
+	}
+
+	replay, subscription, complete := h.app.Events.Subscribe(epoch, since)
+	defer h.app.Events.Unsubscribe(subscription)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // disable nginx buffering
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	fmt.Fprint(w, "retry: 5000\n\n")
+	if !complete {
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, event := range replay {
+		if matches(event) {
+			h.writeEvent(w, event)
+		}
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			w.Flush()
+		case event, ok := <-subscription.Events:
+			if !ok {
+				// Dropped for lagging behind, the client reconnects with its Last-Event-ID
+				return
+			}
+			if matches(event) {
+				h.writeEvent(w, event)
+				w.Flush()
+			}
+		}
+	}
+}
+
+// writeEvent writes an event in the SSE wire format
+func (h *Handlers) writeEvent(w gin.ResponseWriter, event events.Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", h.app.Events.StreamID(event), event.Type, data)
+}
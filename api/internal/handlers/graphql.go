@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"tournois-tt/api/internal/graphql"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GraphQLHandler executes GraphQL queries sent as a JSON body (POST) or as query
+// parameters (GET, which lets persisted queries be cached by HTTP caches)
+func (h *Handlers) GraphQLHandler(c *gin.Context) {
+	var req graphql.Request
+
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if raw := c.Query("variables"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &req.Variables); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "variables must be a JSON object"})
+				return
+			}
+		}
+		if raw := c.Query("extensions"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &req.Extensions); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "extensions must be a JSON object"})
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid GraphQL request body"})
+		return
+	}
+
+	c.JSON(http.StatusOK, graphql.Execute(c.Request.Context(), h.app, req))
+}
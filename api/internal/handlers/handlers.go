@@ -0,0 +1,14 @@
+package handlers
+
+import "tournois-tt/api/internal/app"
+
+// Handlers serves the routes that depend on the application: its configuration, store and
+// clients. Routes that don't are plain functions.
+type Handlers struct {
+	app *app.App
+}
+
+// New returns the handlers of the application a
+func New(a *app.App) *Handlers {
+	return &Handlers{app: a}
+}
@@ -1,38 +1,43 @@
 package handlers
 
 import (
-	"bytes"
 	"encoding/json"
-	"io"
-	"log"
+	"errors"
 	"net/http"
-	"os"
+	"tournois-tt/api/pkg/brevo"
+	"tournois-tt/api/pkg/metrics"
+	"tournois-tt/api/pkg/tally"
 
 	"github.com/gin-gonic/gin"
 )
 
+var newsletterSubscriptions = metrics.NewCounterVec("tournois_newsletter_subscriptions_total",
+	"Newsletter subscriptions by result: created, updated, ok, invalid, rejected, upstream_error or config_error",
+	"result")
+
 type newsletterRequest struct {
 	Email string `json:"email"`
 	Scope string `json:"scope"` // optional: all | region | departement
 	Area  string `json:"area"`  // optional: region name or departement code
 }
 
-// NewsletterHandler subscribes an email to Brevo list ID 11
-func NewsletterHandler(c *gin.Context) {
+// NewsletterHandler subscribes an email to the Brevo newsletter list
+func (h *Handlers) NewsletterHandler(c *gin.Context) {
 	var req newsletterRequest
 	if err := c.ShouldBindJSON(&req); err != nil || req.Email == "" {
+		newsletterSubscriptions.With("invalid").Inc()
 		c.JSON(http.StatusBadRequest, gin.H{"error": "email requis"})
 		return
 	}
 
-	apiKey := os.Getenv("BREVO_API_KEY")
-	if apiKey == "" {
-		log.Printf("BREVO_API_KEY manquant")
+	if !h.app.Brevo.Configured() {
+		logger.ErrorContext(c.Request.Context(), "BREVO_API_KEY is not set")
+		newsletterSubscriptions.With("config_error").Inc()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "configuration manquante"})
 		return
 	}
 
-	// Brevo add contact to list 11
+	// Brevo add contact to the newsletter list
 	attrs := map[string]any{}
 	if req.Scope != "" {
 		attrs["SCOPE"] = req.Scope
@@ -41,41 +46,28 @@ func NewsletterHandler(c *gin.Context) {
 		attrs["AREA"] = req.Area
 	}
 
-	payload := map[string]any{
-		"email":         req.Email,
-		"listIds":       []int{11},
-		"updateEnabled": true,
-		"attributes":    attrs,
-	}
-	body, _ := json.Marshal(payload)
-
-	reqHTTP, _ := http.NewRequest("POST", "https://api.brevo.com/v3/contacts", bytes.NewReader(body))
-	reqHTTP.Header.Set("api-key", apiKey)
-	reqHTTP.Header.Set("Content-Type", "application/json")
-
-	resp, err := http.DefaultClient.Do(reqHTTP)
-	if err != nil {
-		log.Printf("brevo error: %v", err)
-		c.JSON(http.StatusBadGateway, gin.H{"error": "erreur d'inscription"})
-		return
-	}
-	defer resp.Body.Close()
-
-	// Read response body (can be empty on 204)
-	respBody, _ := io.ReadAll(resp.Body)
-
-	if resp.StatusCode >= 300 {
+	status, err := h.app.Brevo.AddContact(c.Request.Context(), req.Email, h.app.Config.Brevo.NewsletterListID, attrs)
+	var rejected *brevo.APIError
+	if errors.As(err, &rejected) {
 		// Try to decode Brevo error
 		var be map[string]any
-		_ = json.Unmarshal(respBody, &be)
-		log.Printf("brevo error: status=%d body=%s", resp.StatusCode, string(respBody))
+		_ = json.Unmarshal(rejected.Body, &be)
+		logger.ErrorContext(c.Request.Context(), "Brevo rejected the subscription",
+			"provider", "brevo", "status", rejected.StatusCode, "body", string(rejected.Body))
+		newsletterSubscriptions.With("rejected").Inc()
 		c.JSON(http.StatusBadGateway, gin.H{"error": be})
 		return
 	}
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), "Brevo request failed", "provider", "brevo", "error", err)
+		newsletterSubscriptions.With("upstream_error").Inc()
+		c.JSON(http.StatusBadGateway, gin.H{"error": "erreur d'inscription"})
+		return
+	}
 
 	// 201 Created => contact created; 204 No Content => updated existing
 	result := "ok"
-	switch resp.StatusCode {
+	switch status {
 	case http.StatusCreated:
 		result = "created"
 	case http.StatusNoContent:
@@ -85,5 +77,9 @@ func NewsletterHandler(c *gin.Context) {
 		result = "ok"
 	}
 
+	newsletterSubscriptions.With(result).Inc()
+	if h.app.Tally != nil {
+		h.app.Tally.Add(tally.NewsletterSignups, result)
+	}
 	c.JSON(http.StatusOK, gin.H{"ok": true, "result": result})
 }
@@ -0,0 +1,13 @@
+package handlers
+
+import (
+	"net/http"
+	"tournois-tt/api/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPIHandler serves the OpenAPI 3 document describing the API
+func OpenAPIHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openapi.Raw())
+}
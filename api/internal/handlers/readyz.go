@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"tournois-tt/api/internal/app"
+	"tournois-tt/api/pkg/degraded"
+	"tournois-tt/api/pkg/fftt"
+	"tournois-tt/api/pkg/geocoding"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Readiness statuses, from best to worst
+const (
+	statusOK       = "ok"
+	statusDegraded = "degraded"
+	statusDown     = "down"
+)
+
+// ReadinessResponse reports the overall status and the state of each dependency
+type ReadinessResponse struct {
+	Status    string          `json:"status"`
+	Cache     CacheCheck      `json:"cache"`
+	Refresh   RefreshCheck    `json:"refresh"`
+	FFTT      FFTTCheck       `json:"fftt"`
+	Geocoding GeocodingCheck  `json:"geocoding"`
+	Brevo     ConfiguredCheck `json:"brevo"`
+}
+
+// CacheCheck reports whether the tournament cache is loaded
+type CacheCheck struct {
+	Status      string `json:"status"`
+	Loaded      bool   `json:"loaded"`
+	Tournaments int    `json:"tournaments"`
+	Error       string `json:"error,omitempty"`
+}
+
+// RefreshCheck reports the age of the last successful current season refresh, and whether
+// failing refreshes put the API in degraded mode
+type RefreshCheck struct {
+	Status      string        `json:"status"`
+	LastSuccess *time.Time    `json:"lastSuccess,omitempty"`
+	AgeSeconds  *int64        `json:"ageSeconds,omitempty"`
+	Mode        degraded.Mode `json:"mode"`
+	Failures    int           `json:"failures"`
+}
+
+// FFTTCheck reports the state of the FFTT circuit breaker
+type FFTTCheck struct {
+	Status  string            `json:"status"`
+	Circuit fftt.CircuitState `json:"circuit"`
+}
+
+// GeocodingCheck reports the availability of the geocoding providers
+type GeocodingCheck struct {
+	Status    string                     `json:"status"`
+	Providers []geocoding.ProviderStatus `json:"providers"`
+}
+
+// ConfiguredCheck reports whether an optional service is configured
+type ConfiguredCheck struct {
+	Status     string `json:"status"`
+	Configured bool   `json:"configured"`
+}
+
+// ReadyzHandler reports whether the API serves fresh data. Degraded responses are still
+// 200 so that only a down API fails health checks.
+func (h *Handlers) ReadyzHandler(c *gin.Context) {
+	report := readiness(h.app, h.app.Now())
+
+	if report.Status != statusOK {
+		logger.WarnContext(c.Request.Context(), "Readiness check not ok", "status", report.Status,
+			"cache", report.Cache.Status, "refresh", report.Refresh.Status, "fftt", report.FFTT.Status,
+			"geocoding", report.Geocoding.Status, "brevo", report.Brevo.Status)
+	}
+
+	code := http.StatusOK
+	if report.Status == statusDown {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, report)
+}
+
+func readiness(a *app.App, now time.Time) ReadinessResponse {
+	var report ReadinessResponse
+
+	// The store is loaded at start up, an empty one has nothing to serve
+	report.Cache = CacheCheck{Status: statusOK, Loaded: true, Tournaments: a.Store.Size()}
+	if report.Cache.Tournaments == 0 {
+		report.Cache.Status = statusDown
+	}
+
+	report.Refresh.Status = statusDown
+	if last := a.Store.LastCurrentSeasonRefresh(); !last.IsZero() {
+		age := now.Sub(last)
+		seconds := int64(age.Seconds())
+		report.Refresh.LastSuccess = &last
+		report.Refresh.AgeSeconds = &seconds
+		switch {
+		case age > a.Config.Readiness.DownAfter:
+			report.Refresh.Status = statusDown
+		case age > a.Config.Readiness.DegradedAfter:
+			report.Refresh.Status = statusDegraded
+		default:
+			report.Refresh.Status = statusOK
+		}
+	}
+
+	// In degraded mode the last good data is served on purpose: FFTT outages must not take
+	// the API down
+	status := a.Degraded.Status()
+	report.Refresh.Mode, report.Refresh.Failures = status.Mode, status.Failures
+	if status.Mode == degraded.Degraded && report.Refresh.LastSuccess != nil {
+		report.Refresh.Status = statusDegraded
+	}
+
+	report.FFTT = FFTTCheck{Status: statusOK, Circuit: a.Breaker.State()}
+	if report.FFTT.Circuit != fftt.CircuitClosed {
+		report.FFTT.Status = statusDegraded
+	}
+
+	// Without a geocoder, new tournaments are listed but not placed on the map
+	report.Geocoding = GeocodingCheck{Status: statusDegraded, Providers: a.Geocoder.Status()}
+	for _, provider := range report.Geocoding.Providers {
+		if provider.Available {
+			report.Geocoding.Status = statusOK
+			break
+		}
+	}
+
+	// Brevo is optional: it is reported but doesn't change the overall status
+	report.Brevo = ConfiguredCheck{Status: statusDegraded}
+	if a.Brevo.Configured() {
+		report.Brevo = ConfiguredCheck{Status: statusOK, Configured: true}
+	}
+
+	report.Status = worst(report.Cache.Status, report.Refresh.Status, report.FFTT.Status,
+		report.Geocoding.Status)
+	return report
+}
+
+// worst returns the worst of the statuses
+func worst(statuses ...string) string {
+	rank := map[string]int{statusOK: 0, statusDegraded: 1, statusDown: 2}
+	result := statusOK
+	for _, status := range statuses {
+		if rank[status] > rank[result] {
+			result = status
+		}
+	}
+	return result
+}
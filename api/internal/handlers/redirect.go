@@ -4,19 +4,16 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
-	"os"
 	"strconv"
-	"time"
-	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/tally"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 // RedirectRulesHandler redirects /:id to the rules PDF URL if available, else to /
-func RedirectRulesHandler(c *gin.Context) {
+func (h *Handlers) RedirectRulesHandler(c *gin.Context) {
 	idStr := c.Param("id")
 	// Only allow numeric ids
 	if _, err := strconv.Atoi(idStr); err != nil {
@@ -24,11 +21,14 @@ func RedirectRulesHandler(c *gin.Context) {
 		return
 	}
 
-	// Read directly from in-memory cache to avoid any season filters
-	if t, ok := cache.GetCachedTournament(mustAtoi(idStr)); ok {
+	// Read directly from the store to avoid any season filters
+	if t, ok := h.app.Store.Get(mustAtoi(idStr)); ok {
 		if t.Rules != nil && t.Rules.URL != "" {
 			// Track the redirect in GA4
-			go trackRedirect(c, t.ID, t.Name, t.Rules.URL)
+			go h.trackRedirect(t.ID, t.Name, t.Rules.URL)
+			if h.app.Tally != nil {
+				h.app.Tally.Add(tally.RulesRedirects, idStr)
+			}
 
 			c.Redirect(http.StatusFound, t.Rules.URL)
 			return
@@ -45,9 +45,9 @@ func mustAtoi(s string) int {
 }
 
 // trackRedirect sends a server-side event to GA4 Measurement Protocol
-func trackRedirect(c *gin.Context, tournamentID int, tournamentName, rulesURL string) {
-	measurementID := os.Getenv("GA_MEASUREMENT_ID")
-	apiSecret := os.Getenv("GA_API_SECRET")
+func (h *Handlers) trackRedirect(tournamentID int, tournamentName, rulesURL string) {
+	measurementID := h.app.Config.Analytics.MeasurementID
+	apiSecret := h.app.Config.Analytics.APISecret
 
 	if measurementID == "" || apiSecret == "" {
 		return // Skip tracking if not configured
@@ -71,7 +71,7 @@ func trackRedirect(c *gin.Context, tournamentID int, tournamentName, rulesURL st
 					"content_group":        "redirect",
 					"tournament_id":        tournamentID,
 					"tournament_name":      tournamentName,
-					"session_id":           time.Now().Unix(),
+					"session_id":           h.app.Now().Unix(),
 					"engagement_time_msec": 100,
 				},
 			},
@@ -84,14 +84,14 @@ func trackRedirect(c *gin.Context, tournamentID int, tournamentName, rulesURL st
 	req, _ := http.NewRequest("POST", url, bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := h.app.HTTP.Do(req)
 	if err != nil {
-		log.Printf("GA4 tracking error: %v", err)
+		logger.Warn("GA4 tracking failed", "tournament_id", tournamentID, "error", err)
 		return
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 300 {
-		log.Printf("GA4 tracking failed: status=%d", resp.StatusCode)
+		logger.Warn("GA4 tracking failed", "tournament_id", tournamentID, "status", resp.StatusCode)
 	}
 }
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/refdata"
+	"tournois-tt/api/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegionResponse is a region with its departments and live tournament count
+type RegionResponse struct {
+	refdata.Region
+	Departments []string `json:"departments"`
+	Tournaments int      `json:"tournaments"`
+}
+
+// DepartmentResponse is a department with its region and live tournament count
+type DepartmentResponse struct {
+	refdata.Department
+	RegionName  string `json:"regionName"`
+	Tournaments int    `json:"tournaments"`
+}
+
+// RegionsHandler lists French regions with the number of upcoming tournaments in each
+func (h *Handlers) RegionsHandler(c *gin.Context) {
+	counts := liveTournamentsByDepartment(h.app.Store.All(), h.app.Now())
+
+	regions := refdata.Regions()
+	response := make([]RegionResponse, 0, len(regions))
+	indexes := make(map[string]int, len(regions))
+	for i, region := range regions {
+		response = append(response, RegionResponse{Region: region, Departments: []string{}})
+		indexes[region.Code] = i
+	}
+
+	for _, department := range refdata.Departments() {
+		r := &response[indexes[department.RegionCode]]
+		r.Departments = append(r.Departments, department.Code)
+		r.Tournaments += counts[department.Code]
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DepartmentsHandler lists French departments with the number of upcoming tournaments in each
+func (h *Handlers) DepartmentsHandler(c *gin.Context) {
+	counts := liveTournamentsByDepartment(h.app.Store.All(), h.app.Now())
+
+	region := c.Query("region")
+
+	departments := refdata.Departments()
+	response := make([]DepartmentResponse, 0, len(departments))
+	for _, department := range departments {
+		if region != "" && department.RegionCode != region {
+			continue
+		}
+		response = append(response, DepartmentResponse{
+			Department:  department,
+			RegionName:  refdata.RegionOfDepartment(department).Name,
+			Tournaments: counts[department.Code],
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// liveTournamentsByDepartment counts the tournaments that have not ended by now per department code
+func liveTournamentsByDepartment(tournaments map[string]cache.TournamentCache, now time.Time) map[string]int {
+	today := utils.Today(now)
+	counts := make(map[string]int)
+	for _, t := range tournaments {
+		endDate := t.EndDate
+		if endDate == "" {
+			endDate = t.StartDate
+		}
+		if end, ok := utils.ParseTournamentDate(endDate); !ok || end.Before(today) {
+			continue
+		}
+		counts[t.Club.Department]++
+	}
+
+	return counts
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+	"tournois-tt/api/pkg/stats"
+	"tournois-tt/api/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatsHandler returns aggregated tournament figures for a season (defaults to the current one)
+func (h *Handlers) StatsHandler(c *gin.Context) {
+	season := c.DefaultQuery("season", utils.SeasonLabel(h.app.Now()))
+
+	if _, _, err := utils.ParseSeason(season); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	seasonStats, err := stats.ForSeason(h.app.Store, season)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, seasonStats)
+}
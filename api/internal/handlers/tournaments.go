@@ -1,16 +1,17 @@
 package handlers
 
 import (
-	"log"
 	"net/http"
 	"strings"
-	"tournois-tt/api/pkg/cache"
 	"tournois-tt/api/pkg/fftt"
 	"tournois-tt/api/pkg/geocoding"
+	"tournois-tt/api/pkg/logging"
 
 	"github.com/gin-gonic/gin"
 )
 
+var logger = logging.For("handlers")
+
 // TournamentResponse represents the data to return to API clients
 type TournamentResponse struct {
 	ID        int               `json:"id"`
@@ -21,20 +22,22 @@ type TournamentResponse struct {
 	Address   geocoding.Address `json:"address"`
 	Club      fftt.Club         `json:"club"`
 	Rules     *fftt.Rules       `json:"rules,omitempty"`
+	Tables    []fftt.Table      `json:"tables,omitempty"`
 	Page      string            `json:"page,omitempty"`
 	Endowment int               `json:"endowment"`
+	Cancelled bool              `json:"cancelled,omitempty"`
+	// Stale is set while refreshes fail and the last good data is served
+	Stale bool `json:"stale,omitempty"`
 }
 
 // TournamentsHandler handles tournament requests by retrieving data from the cache
-func TournamentsHandler(c *gin.Context) {
-	cachedTournaments, err := cache.LoadTournaments()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load tournaments from cache"})
-		return
-	}
+func (h *Handlers) TournamentsHandler(c *gin.Context) {
+	cachedTournaments := h.app.Store.All()
+
+	stale := h.app.Degraded.Active()
 
 	// Convert to response format with only needed fields
-	var tournamentsResponse []TournamentResponse
+	tournamentsResponse := make([]TournamentResponse, 0, len(cachedTournaments))
 	for _, cachedTournament := range cachedTournaments {
 		tournamentsResponse = append(tournamentsResponse, TournamentResponse{
 			ID:        cachedTournament.ID,
@@ -61,6 +64,8 @@ func TournamentsHandler(c *gin.Context) {
 			},
 			Page:      cachedTournament.Page,
 			Endowment: cachedTournament.Endowment,
+			Cancelled: cachedTournament.Cancelled,
+			Stale:     stale,
 		})
 
 		// Add rules if available
@@ -73,12 +78,24 @@ func TournamentsHandler(c *gin.Context) {
 				URL:     cachedTournament.Rules.URL,
 			}
 		}
+
+		// Add tables if available
+		for _, table := range cachedTournament.Tables {
+			tournamentsResponse[len(tournamentsResponse)-1].Tables = append(tournamentsResponse[len(tournamentsResponse)-1].Tables, fftt.Table{
+				Name:        table.Name,
+				Description: table.Description,
+				Date:        table.Date,
+				Time:        table.Time,
+				Fee:         table.Fee,
+				Endowment:   table.Endowment,
+			})
+		}
 	}
 
 	// Filter by postal code if provided
 	postalCode := c.Query("postalCode")
 	if postalCode != "" {
-		var filteredTournaments []TournamentResponse
+		filteredTournaments := make([]TournamentResponse, 0, len(tournamentsResponse))
 		for _, t := range tournamentsResponse {
 			if strings.HasPrefix(t.Address.PostalCode, postalCode) {
 				filteredTournaments = append(filteredTournaments, t)
@@ -87,9 +104,8 @@ func TournamentsHandler(c *gin.Context) {
 		tournamentsResponse = filteredTournaments
 	}
 
-	// Log response data
-	log.Printf("Returned %d tournaments (filtered by postal code: %s)",
-		len(tournamentsResponse), postalCode)
+	logger.DebugContext(c.Request.Context(), "Returned tournaments",
+		"count", len(tournamentsResponse), "postal_code", postalCode)
 
 	c.JSON(http.StatusOK, tournamentsResponse)
 }
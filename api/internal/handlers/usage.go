@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"tournois-tt/api/internal/middleware"
+	"tournois-tt/api/pkg/apikeys"
+
+	"github.com/gin-gonic/gin"
+)
+
+type usageResponse struct {
+	ID    string        `json:"id"`
+	Name  string        `json:"name"`
+	Tier  apikeys.Tier  `json:"tier"`
+	Usage apikeys.Usage `json:"usage"`
+}
+
+// UsageHandler returns the tier and usage counters of the API key making the request
+func (h *Handlers) UsageHandler(c *gin.Context) {
+	key, ok := middleware.APIKeyFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "an API key is required"})
+		return
+	}
+
+	c.JSON(http.StatusOK, usageResponse{
+		ID:    key.ID,
+		Name:  key.Name,
+		Tier:  key.TierInfo(),
+		Usage: h.app.APIKeys.Usage(key.ID),
+	})
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"tournois-tt/api/internal/jsonld"
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/clubs"
+	"tournois-tt/api/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Media types served by the /v2 API
+const (
+	mediaTypeJSONLD = "application/ld+json"
+	mediaTypeJSON   = "application/json"
+)
+
+// Pagination defaults for /v2 collections
+const (
+	defaultItemsPerPage = 30
+	maxItemsPerPage     = 1000
+)
+
+// TournamentsV2Handler returns tournaments as a paginated Hydra collection of SportsEvent
+func (h *Handlers) TournamentsV2Handler(c *gin.Context) {
+	format, ok := negotiateLinkedData(c)
+	if !ok {
+		return
+	}
+
+	tournaments := cache.FilterTournaments(h.app.Store.All(), tournamentFilter(c), c.Query("order[startDate]") == "desc")
+
+	page, itemsPerPage := pagination(c)
+	start, end := pageBounds(page, itemsPerPage, len(tournaments))
+
+	members := make([]jsonld.Event, 0, end-start)
+	for _, t := range tournaments[start:end] {
+		members = append(members, jsonld.NewEvent(t))
+	}
+
+	h.renderCollection(c, format, "/v2/tournaments", members, len(tournaments), page, itemsPerPage)
+}
+
+// TournamentV2Handler returns a single tournament as a SportsEvent
+func (h *Handlers) TournamentV2Handler(c *gin.Context) {
+	format, ok := negotiateLinkedData(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tournament id"})
+		return
+	}
+
+	t, found := h.app.Store.Get(id)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "tournament not found"})
+		return
+	}
+
+	event := jsonld.NewEvent(t)
+	if format == mediaTypeJSONLD {
+		event.Context = jsonld.Context
+	}
+	renderLinkedData(c, format, event)
+}
+
+// ClubsV2Handler returns organizing clubs as a paginated Hydra collection of SportsOrganization
+func (h *Handlers) ClubsV2Handler(c *gin.Context) {
+	format, ok := negotiateLinkedData(c)
+	if !ok {
+		return
+	}
+
+	allClubs := clubs.List(h.app.Store, h.app.Now())
+
+	department := c.Query("department")
+	region := c.Query("region")
+
+	filtered := make([]clubs.Club, 0, len(allClubs))
+	for _, club := range allClubs {
+		if department != "" && club.Department != department {
+			continue
+		}
+		if region != "" && club.Region != region {
+			continue
+		}
+		filtered = append(filtered, club)
+	}
+
+	page, itemsPerPage := pagination(c)
+	start, end := pageBounds(page, itemsPerPage, len(filtered))
+
+	members := make([]jsonld.Club, 0, end-start)
+	for _, club := range filtered[start:end] {
+		members = append(members, jsonld.NewClub(club))
+	}
+
+	h.renderCollection(c, format, "/v2/clubs", members, len(filtered), page, itemsPerPage)
+}
+
+// ClubV2Handler returns a single organizing club as a SportsOrganization with its history
+func (h *Handlers) ClubV2Handler(c *gin.Context) {
+	format, ok := negotiateLinkedData(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid club id"})
+		return
+	}
+
+	club, found := clubs.Get(h.app.Store, id, h.app.Now())
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "club not found"})
+		return
+	}
+
+	document := jsonld.NewClub(club)
+	if format == mediaTypeJSONLD {
+		document.Context = jsonld.Context
+	}
+	renderLinkedData(c, format, document)
+}
+
+// tournamentFilter reads the /v2 collection filters, which mirror the FFTT API ones
+func tournamentFilter(c *gin.Context) cache.TournamentFilter {
+	filter := cache.TournamentFilter{
+		PostalCode: c.Query("address.postalCode"),
+		Locality:   c.Query("address.addressLocality"),
+		Type:       c.Query("type"),
+		Department: c.Query("department"),
+		Region:     c.Query("region"),
+	}
+	if filter.PostalCode == "" {
+		filter.PostalCode = c.Query("postalCode")
+	}
+	if after, ok := utils.ParseTournamentDate(c.Query("startDate[after]")); ok {
+		filter.StartAfter = after
+	}
+	if before, ok := utils.ParseTournamentDate(c.Query("startDate[before]")); ok {
+		filter.StartBefore = before
+	}
+	return filter
+}
+
+// negotiateLinkedData picks the response media type from the Accept header,
+// answering 406 Not Acceptable when neither JSON-LD nor JSON is accepted
+func negotiateLinkedData(c *gin.Context) (string, bool) {
+	format := c.NegotiateFormat(mediaTypeJSONLD, mediaTypeJSON)
+	if format == "" {
+		c.JSON(http.StatusNotAcceptable, gin.H{"error": "supported media types: " + mediaTypeJSONLD + ", " + mediaTypeJSON})
+		return "", false
+	}
+	c.Header("Vary", "Accept")
+	return format, true
+}
+
+// renderLinkedData writes a document with the negotiated content type
+func renderLinkedData(c *gin.Context, format string, document any) {
+	c.Header("Content-Type", format+"; charset=utf-8")
+	c.JSON(http.StatusOK, document)
+}
+
+// renderCollection writes a Hydra collection for JSON-LD clients, or the bare members for JSON ones
+func (h *Handlers) renderCollection(c *gin.Context, format, path string, members any, totalItems, page, itemsPerPage int) {
+	if format == mediaTypeJSON {
+		c.Header("X-Total-Count", strconv.Itoa(totalItems))
+		renderLinkedData(c, format, members)
+		return
+	}
+
+	renderLinkedData(c, format, jsonld.Collection{
+		Context:    jsonld.Context,
+		ID:         path,
+		Type:       "hydra:Collection",
+		Member:     members,
+		TotalItems: totalItems,
+		View:       jsonld.NewView(path, c.Request.URL.Query(), page, itemsPerPage, totalItems),
+		Stale:      h.app.Degraded.Active(),
+	})
+}
+
+// pagination reads the page and itemsPerPage query parameters
+func pagination(c *gin.Context) (int, int) {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	itemsPerPage, err := strconv.Atoi(c.Query("itemsPerPage"))
+	if err != nil || itemsPerPage < 1 {
+		itemsPerPage = defaultItemsPerPage
+	}
+	if itemsPerPage > maxItemsPerPage {
+		itemsPerPage = maxItemsPerPage
+	}
+
+	return page, itemsPerPage
+}
+
+// pageBounds returns the slice bounds of a page within total items
+func pageBounds(page, itemsPerPage, total int) (int, int) {
+	start := (page - 1) * itemsPerPage
+	if start > total {
+		start = total
+	}
+	end := start + itemsPerPage
+	if end > total {
+		end = total
+	}
+	return start, end
+}
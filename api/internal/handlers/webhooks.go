@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"tournois-tt/api/internal/middleware"
+	"tournois-tt/api/pkg/events"
+	"tournois-tt/api/pkg/webhooks"
+
+	"github.com/gin-gonic/gin"
+)
+
+type webhookRequest struct {
+	URL        string        `json:"url"`
+	Department string        `json:"department"`
+	Region     string        `json:"region"`
+	Type       string        `json:"type"`
+	Events     []events.Type `json:"events"`
+}
+
+// CreateWebhookHandler registers a webhook subscription of the calling API key. The signing
+// secret is only returned in this response and authenticates later calls for the subscription.
+func (h *Handlers) CreateWebhookHandler(c *gin.Context) {
+	key, ok := middleware.APIKeyFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "an API key is required"})
+		return
+	}
+
+	if h.app.Webhooks == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load webhooks"})
+		return
+	}
+
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.URL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url is required"})
+		return
+	}
+
+	subscription, err := h.app.Webhooks.Subscribe(webhooks.Subscription{
+		URL:        req.URL,
+		Department: req.Department,
+		Region:     req.Region,
+		Type:       req.Type,
+		Events:     req.Events,
+		Owner:      key.ID,
+	})
+	if err != nil {
+		logger.InfoContext(c.Request.Context(), "Webhook subscription rejected", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// WebhookHandler returns a webhook subscription
+func (h *Handlers) WebhookHandler(c *gin.Context) {
+	subscription, ok := h.authenticateWebhook(c)
+	if !ok {
+		return
+	}
+
+	subscription.Secret = ""
+	c.JSON(http.StatusOK, subscription)
+}
+
+// DeleteWebhookHandler removes a webhook subscription
+func (h *Handlers) DeleteWebhookHandler(c *gin.Context) {
+	subscription, ok := h.authenticateWebhook(c)
+	if !ok {
+		return
+	}
+
+	if err := h.app.Webhooks.Unsubscribe(subscription.ID); err != nil {
+		logger.ErrorContext(c.Request.Context(), "Failed to delete webhook", "subscription_id", subscription.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// WebhookDeliveriesHandler returns the delivery log of a webhook subscription, newest first
+func (h *Handlers) WebhookDeliveriesHandler(c *gin.Context) {
+	subscription, ok := h.authenticateWebhook(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, h.app.Webhooks.Deliveries(subscription.ID, c.Query("status")))
+}
+
+// authenticateWebhook loads the subscription of the request and checks its bearer secret
+func (h *Handlers) authenticateWebhook(c *gin.Context) (webhooks.Subscription, bool) {
+	if h.app.Webhooks == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load webhooks"})
+		return webhooks.Subscription{}, false
+	}
+
+	subscription, found := h.app.Webhooks.Subscription(c.Param("id"))
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return webhooks.Subscription{}, false
+	}
+
+	secret := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(subscription.Secret)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid webhook secret"})
+		return webhooks.Subscription{}, false
+	}
+
+	return subscription, true
+}
@@ -0,0 +1,304 @@
+// Package jsonld builds JSON-LD representations of tournaments and clubs typed with
+// schema.org vocabulary, and Hydra collections to page through them.
+package jsonld
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/clubs"
+)
+
+// Context is the JSON-LD context shared by every /v2 document. Terms that have no
+// schema.org equivalent are mapped to the site vocabulary.
+var Context = map[string]any{
+	"@vocab":              "https://schema.org/",
+	"hydra":               "http://www.w3.org/ns/hydra/core#",
+	"tt":                  "https://tournois-tt.fr/vocab#",
+	"id":                  "tt:id",
+	"type":                "tt:tournamentType",
+	"endowment":           "tt:endowment",
+	"rules":               "tt:rules",
+	"tables":              "tt:tables",
+	"department":          "tt:department",
+	"region":              "tt:region",
+	"tournamentsBySeason": "tt:tournamentsBySeason",
+	"history":             "tt:history",
+	"eventStatus":         map[string]any{"@type": "@vocab"},
+}
+
+// PostalAddress is a schema.org PostalAddress
+type PostalAddress struct {
+	Type            string `json:"@type"`
+	StreetAddress   string `json:"streetAddress"`
+	PostalCode      string `json:"postalCode"`
+	AddressLocality string `json:"addressLocality"`
+	AddressRegion   string `json:"addressRegion,omitempty"`
+	AddressCountry  string `json:"addressCountry"`
+}
+
+// Place is a schema.org Place
+type Place struct {
+	Type      string         `json:"@type"`
+	Name      string         `json:"name,omitempty"`
+	Address   *PostalAddress `json:"address,omitempty"`
+	Latitude  float64        `json:"latitude,omitempty"`
+	Longitude float64        `json:"longitude,omitempty"`
+}
+
+// Organization is a schema.org SportsOrganization referencing a club
+type Organization struct {
+	ID         string `json:"@id"`
+	Type       string `json:"@type"`
+	ClubID     int    `json:"id"`
+	Name       string `json:"name"`
+	Identifier string `json:"identifier"`
+	Department string `json:"department,omitempty"`
+	Region     string `json:"region,omitempty"`
+}
+
+// Rules are the tournament eligibility rules
+type Rules struct {
+	AgeMin  int    `json:"ageMin,omitempty"`
+	AgeMax  int    `json:"ageMax,omitempty"`
+	Points  int    `json:"points,omitempty"`
+	Ranking int    `json:"ranking,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+// Table is a tournament table
+type Table struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Date        string `json:"date,omitempty"`
+	Time        string `json:"time,omitempty"`
+	Fee         int    `json:"fee"`
+	Endowment   int    `json:"endowment"`
+}
+
+// Event is a schema.org SportsEvent describing a tournament
+type Event struct {
+	Context      any           `json:"@context,omitempty"`
+	ID           string        `json:"@id"`
+	Type         string        `json:"@type"`
+	TournamentID int           `json:"id"`
+	Name         string        `json:"name"`
+	Sport        string        `json:"sport"`
+	Kind         string        `json:"type"`
+	StartDate    string        `json:"startDate"`
+	EndDate      string        `json:"endDate"`
+	EventStatus  string        `json:"eventStatus,omitempty"`
+	URL          string        `json:"url,omitempty"`
+	Location     Place         `json:"location"`
+	Organizer    *Organization `json:"organizer,omitempty"`
+	Endowment    int           `json:"endowment"`
+	Rules        *Rules        `json:"rules,omitempty"`
+	Tables       []Table       `json:"tables,omitempty"`
+}
+
+// EventReference is a lightweight link to a SportsEvent
+type EventReference struct {
+	ID        string `json:"@id"`
+	Type      string `json:"@type"`
+	Name      string `json:"name"`
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+}
+
+// Club is a schema.org SportsOrganization describing an organizing club
+type Club struct {
+	Context             any              `json:"@context,omitempty"`
+	ID                  string           `json:"@id"`
+	Type                string           `json:"@type"`
+	ClubID              int              `json:"id"`
+	Name                string           `json:"name"`
+	Identifier          string           `json:"identifier"`
+	Department          string           `json:"department,omitempty"`
+	Region              string           `json:"region,omitempty"`
+	Location            *Place           `json:"location,omitempty"`
+	TournamentsBySeason map[string]int   `json:"tournamentsBySeason"`
+	Event               []EventReference `json:"event"`
+	History             []EventReference `json:"history,omitempty"`
+}
+
+// View is a Hydra PartialCollectionView with links to neighbouring pages
+type View struct {
+	ID       string `json:"@id"`
+	Type     string `json:"@type"`
+	First    string `json:"hydra:first,omitempty"`
+	Last     string `json:"hydra:last,omitempty"`
+	Previous string `json:"hydra:previous,omitempty"`
+	Next     string `json:"hydra:next,omitempty"`
+}
+
+// Collection is a Hydra collection of members
+type Collection struct {
+	Context    any    `json:"@context"`
+	ID         string `json:"@id"`
+	Type       string `json:"@type"`
+	Member     any    `json:"hydra:member"`
+	TotalItems int    `json:"hydra:totalItems"`
+	View       *View  `json:"hydra:view,omitempty"`
+	// Stale is set while refreshes fail and the last good data is served
+	Stale bool `json:"stale,omitempty"`
+}
+
+// TournamentIRI returns the IRI of a tournament
+func TournamentIRI(id int) string {
+	return fmt.Sprintf("/v2/tournaments/%d", id)
+}
+
+// ClubIRI returns the IRI of a club
+func ClubIRI(id int) string {
+	return fmt.Sprintf("/v2/clubs/%d", id)
+}
+
+// NewEvent converts a cached tournament to a SportsEvent
+func NewEvent(t cache.TournamentCache) Event {
+	event := Event{
+		ID:           TournamentIRI(t.ID),
+		Type:         "SportsEvent",
+		TournamentID: t.ID,
+		Name:         t.Name,
+		Sport:        "Tennis de table",
+		Kind:         t.Type,
+		StartDate:    t.StartDate,
+		EndDate:      t.EndDate,
+		URL:          t.Page,
+		Location: Place{
+			Type: "Place",
+			Name: t.Address.DisambiguatingDescription,
+			Address: &PostalAddress{
+				Type:            "PostalAddress",
+				StreetAddress:   t.Address.StreetAddress,
+				PostalCode:      t.Address.PostalCode,
+				AddressLocality: t.Address.AddressLocality,
+				AddressRegion:   t.Club.Region,
+				AddressCountry:  "FR",
+			},
+		},
+		Endowment: t.Endowment,
+	}
+
+	if t.Cancelled {
+		event.EventStatus = "EventCancelled"
+	}
+
+	if !t.Address.Failed {
+		event.Location.Latitude = t.Address.Latitude
+		event.Location.Longitude = t.Address.Longitude
+	}
+
+	if t.Club.ID != 0 {
+		event.Organizer = &Organization{
+			ID:         ClubIRI(t.Club.ID),
+			Type:       "SportsOrganization",
+			ClubID:     t.Club.ID,
+			Name:       t.Club.Name,
+			Identifier: t.Club.Identifier,
+			Department: t.Club.Department,
+			Region:     t.Club.Region,
+		}
+	}
+
+	if t.Rules != nil {
+		event.Rules = &Rules{
+			AgeMin:  t.Rules.AgeMin,
+			AgeMax:  t.Rules.AgeMax,
+			Points:  t.Rules.Points,
+			Ranking: t.Rules.Ranking,
+			URL:     t.Rules.URL,
+		}
+	}
+
+	for _, table := range t.Tables {
+		event.Tables = append(event.Tables, Table{
+			Name:        table.Name,
+			Description: table.Description,
+			Date:        table.Date,
+			Time:        table.Time,
+			Fee:         table.Fee,
+			Endowment:   table.Endowment,
+		})
+	}
+
+	return event
+}
+
+// NewClub converts an organizing club to a SportsOrganization
+func NewClub(c clubs.Club) Club {
+	club := Club{
+		ID:                  ClubIRI(c.ID),
+		Type:                "SportsOrganization",
+		ClubID:              c.ID,
+		Name:                c.Name,
+		Identifier:          c.Identifier,
+		Department:          c.Department,
+		Region:              c.Region,
+		TournamentsBySeason: c.TournamentsBySeason,
+		Event:               eventReferences(c.Upcoming),
+	}
+
+	if c.Venue != nil {
+		club.Location = &Place{
+			Type:      "Place",
+			Latitude:  c.Venue.Latitude,
+			Longitude: c.Venue.Longitude,
+		}
+	}
+
+	if len(c.History) > 0 {
+		club.History = eventReferences(c.History)
+	}
+
+	return club
+}
+
+func eventReferences(editions []clubs.Edition) []EventReference {
+	references := make([]EventReference, 0, len(editions))
+	for _, edition := range editions {
+		references = append(references, EventReference{
+			ID:        TournamentIRI(edition.ID),
+			Type:      "SportsEvent",
+			Name:      edition.Name,
+			StartDate: edition.StartDate,
+			EndDate:   edition.EndDate,
+		})
+	}
+	return references
+}
+
+// NewView builds the Hydra view of a page. query holds the request query parameters,
+// which are preserved in the page links.
+func NewView(path string, query url.Values, page, itemsPerPage, totalItems int) *View {
+	lastPage := (totalItems + itemsPerPage - 1) / itemsPerPage
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	pageIRI := func(p int) string {
+		q := url.Values{}
+		for key, values := range query {
+			q[key] = values
+		}
+		q.Set("page", strconv.Itoa(p))
+		return path + "?" + q.Encode()
+	}
+
+	view := &View{
+		ID:    pageIRI(page),
+		Type:  "hydra:PartialCollectionView",
+		First: pageIRI(1),
+		Last:  pageIRI(lastPage),
+	}
+	if page > 1 {
+		view.Previous = pageIRI(page - 1)
+	}
+	if page < lastPage {
+		view.Next = pageIRI(page + 1)
+	}
+
+	return view
+}
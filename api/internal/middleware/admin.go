@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"tournois-tt/api/internal/config"
+	"tournois-tt/api/pkg/audit"
+	"tournois-tt/api/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+const adminActorContextKey = "adminActor"
+
+// AdminAuth returns a middleware admitting requests that carry the admin bearer token, or
+// that come through the admin mutual TLS listener with a verified client certificate.
+// Browsers can use Basic authentication with the token as password. Rejected requests are
+// recorded in log.
+func AdminAuth(admin config.AdminConfig, log *audit.Log) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if admin.Token == "" && admin.TLSAddr == "" {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "admin API is disabled"})
+			return
+		}
+
+		actor, ok := adminActor(c, admin.Token)
+		if !ok {
+			log.Record(audit.Entry{
+				Actor:     "anonymous",
+				Action:    "auth",
+				Target:    c.Request.Method + " " + c.Request.URL.Path,
+				Outcome:   audit.OutcomeDenied,
+				RequestID: logging.RequestID(c.Request.Context()),
+			})
+			c.Header("WWW-Authenticate", `Basic realm="tournois-tt admin"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		c.Set(adminActorContextKey, actor)
+		c.Next()
+	}
+}
+
+// AdminActorFromContext returns who was authenticated by AdminAuth
+func AdminActorFromContext(c *gin.Context) string {
+	return c.GetString(adminActorContextKey)
+}
+
+// adminActor identifies the administrator making a request
+func adminActor(c *gin.Context, token string) (string, bool) {
+	// The admin TLS listener only accepts verified client certificates
+	if tls := c.Request.TLS; tls != nil && len(tls.VerifiedChains) > 0 {
+		return "cert:" + tls.VerifiedChains[0][0].Subject.CommonName, true
+	}
+	if token != "" && bearerMatches(c, token) {
+		return "token", true
+	}
+	if user, password, ok := c.Request.BasicAuth(); ok && token != "" &&
+		subtle.ConstantTimeCompare([]byte(password), []byte(token)) == 1 {
+		return "basic:" + user, true
+	}
+	return "", false
+}
+
+// bearerMatches reports whether the request carries token as a bearer token
+func bearerMatches(c *gin.Context, token string) bool {
+	given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+
+	"tournois-tt/api/pkg/apikeys"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries the API key of third-party consumers
+const APIKeyHeader = "X-API-Key"
+
+const (
+	apiKeyContextKey        = "apiKey"
+	invalidAPIKeyContextKey = "invalidApiKey"
+)
+
+// APIKey returns a middleware that authenticates requests carrying an API key of keys.
+// Requests without a key stay anonymous. Requests with an invalid or revoked key are
+// rejected by RateLimiter once counted against their IP, so that guessing keys is rate
+// limited. Keys are rejected with an error when keys is nil, as it failed to load.
+func APIKey(keys *apikeys.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(APIKeyHeader)
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		if keys == nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to load API keys"})
+			return
+		}
+
+		key, err := keys.Authenticate(token)
+		if err != nil {
+			c.Set(invalidAPIKeyContextKey, true)
+			c.Next()
+			return
+		}
+
+		c.Set(apiKeyContextKey, key)
+		c.Next()
+	}
+}
+
+// APIKeyFromContext returns the key authenticated by the APIKey middleware, if any
+func APIKeyFromContext(c *gin.Context) (apikeys.Key, bool) {
+	value, ok := c.Get(apiKeyContextKey)
+	if !ok {
+		return apikeys.Key{}, false
+	}
+	key, ok := value.(apikeys.Key)
+	return key, ok
+}
+
+// rejectInvalidAPIKey aborts requests whose API key failed authentication
+func rejectInvalidAPIKey(c *gin.Context) bool {
+	if !c.GetBool(invalidAPIKeyContextKey) {
+		return false
+	}
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid API key"})
+	return true
+}
@@ -1,35 +1,59 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
+	"strings"
 	"time"
+	"tournois-tt/api/pkg/logging"
+	"tournois-tt/api/pkg/privacy"
 	"tournois-tt/api/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-// Logger returns a middleware that logs request details including IPs and User-Agent
-func Logger() gin.HandlerFunc {
+var (
+	logger       = logging.For("middleware")
+	accessLogger = logging.For("http")
+)
+
+// Logger returns a middleware that logs request details. Client IPs and User-Agent are
+// pseudonymized according to the privacy mode: hash, truncate or off.
+func Logger(privacyMode string) gin.HandlerFunc {
+	mode, err := privacy.ParseMode(privacyMode)
+	if err != nil {
+		logger.Warn("Invalid LOG_PRIVACY, hashing client IPs", "error", err)
+		mode = privacy.ModeHash
+	}
+	pseudonymizer := privacy.NewPseudonymizer(mode, privacy.DefaultSaltRotation)
+
 	return func(c *gin.Context) {
 		// Start timer
 		start := time.Now()
 
 		// Get IPs before request is processed
 		ips := utils.GetIPsFromRequest(c)
+		for i, ip := range ips {
+			ips[i] = pseudonymizer.IP(ip)
+		}
 
 		// Process request
 		c.Next()
 
-		// Log request details after completion
-		latency := time.Since(start)
-		log.Printf("[%s] %s %s | Status: %d | Latency: %v | IPs: %s | User-Agent: %s",
-			c.Request.Method,
-			c.Request.URL.Path,
-			c.Request.URL.RawQuery,
-			c.Writer.Status(),
-			latency,
-			ips,
-			c.Request.UserAgent(),
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelError
+		}
+
+		accessLogger.LogAttrs(c.Request.Context(), level, "request",
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("query", c.Request.URL.RawQuery),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("ips", strings.Join(ips, ", ")),
+			slog.String("user_agent", pseudonymizer.UserAgent(c.Request.UserAgent())),
 		)
 	}
 }
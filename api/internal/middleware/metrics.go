@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"tournois-tt/api/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	httpRequests = metrics.NewCounterVec("tournois_http_requests_total",
+		"HTTP requests by method, route and status code", "method", "route", "status")
+	httpDuration = metrics.NewHistogramVec("tournois_http_request_duration_seconds",
+		"HTTP request latencies by method and route", metrics.DefaultBuckets, "method", "route")
+)
+
+// Metrics returns a middleware that counts requests and records their latency per route
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := routeLabel(c)
+		httpRequests.With(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		httpDuration.With(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// MetricsHandler serves the metrics behind the bearer token. They are not served when
+// token is empty.
+func MetricsHandler(token string) gin.HandlerFunc {
+	handler := metrics.Default.Handler()
+
+	return func(c *gin.Context) {
+		if token == "" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "metrics are disabled"})
+			return
+		}
+		if !bearerMatches(c, token) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		handler.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// routeLabel is the route template of a request, so that path parameters don't create
+// a series per value
+func routeLabel(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return "unmatched"
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"tournois-tt/api/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ValidateRequest returns a middleware that rejects requests whose query or path parameters
+// do not match the OpenAPI document. Undocumented query parameters are ignored.
+func ValidateRequest() gin.HandlerFunc {
+	doc, err := openapi.Load()
+	if err != nil {
+		panic(fmt.Sprintf("Error loading OpenAPI document: %v", err))
+	}
+
+	return func(c *gin.Context) {
+		op, ok := doc.Operation(c.Request.Method, c.FullPath())
+		if !ok {
+			c.Next()
+			return
+		}
+
+		for _, param := range op.Parameters {
+			var raw string
+			var present bool
+
+			switch param.In {
+			case "query":
+				raw, present = c.GetQuery(param.Name)
+			case "path":
+				raw = c.Param(param.Name)
+				present = raw != ""
+			default:
+				continue
+			}
+
+			if !present {
+				if param.Required {
+					c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+						"error": fmt.Sprintf("missing required %s parameter %q", param.In, param.Name),
+					})
+					return
+				}
+				continue
+			}
+
+			if err := openapi.ValidateParameter(param, raw); err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+					"error": fmt.Sprintf("invalid %s parameter %q: %v", param.In, param.Name, err),
+				})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -1,50 +1,153 @@
 package middleware
 
 import (
+	"math"
 	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
 	"time"
 
+	"tournois-tt/api/internal/config"
+	"tournois-tt/api/pkg/apikeys"
+	"tournois-tt/api/pkg/metrics"
+	"tournois-tt/api/pkg/ratelimit"
+
 	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
 )
 
-const (
-	requestsPerMinute = 45
-	burstSize         = 5
-)
+// routePolicies replace the default limits on some routes, for every caller. They are
+// tracked in buckets of their own.
+var routePolicies = map[string]ratelimit.Policy{
+	// Each subscription sends an email
+	"POST /v1/newsletter": {RequestsPerMinute: 3, Burst: 2},
+	// Polled by uptime monitors
+	"GET /v1/healthz": {RequestsPerMinute: 600, Burst: 60},
+	"GET /v1/readyz":  {RequestsPerMinute: 600, Burst: 60},
+}
 
-var limiterMap = make(map[string]*rate.Limiter)
+var rejections = metrics.NewCounterVec("tournois_ratelimit_rejections_total",
+	"Requests rejected by the rate limiter, by reason (rate or quota) and route", "reason", "route")
+
+// RateLimiter returns a middleware that limits requests per API key, or per host for
+// anonymous clients, and reports the limits in X-RateLimit-* headers. Keys are also held
+// to the daily quota of their tier. Allowlisted internal callers are not limited.
+// Requests with an invalid API key are limited as anonymous ones, then rejected. The usage
+// of the keys is counted in keys, and the buckets are kept in limiters.
+func RateLimiter(limits config.RateLimitConfig, limiters *ratelimit.Store, keys *apikeys.Store) gin.HandlerFunc {
+	allowlist := parseAllowlist(limits.Allowlist)
+	// Anonymous clients are limited per IP
+	anonymousPolicy := ratelimit.Policy{RequestsPerMinute: limits.RequestsPerMinute, Burst: limits.Burst}
 
-// RateLimiter returns a middleware that limits requests per host
-func RateLimiter() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get the real IP, considering X-Forwarded-For and X-Real-IP headers
 		clientIP := c.ClientIP()
+		if allowlisted(allowlist, clientIP) {
+			if !rejectInvalidAPIKey(c) {
+				c.Next()
+			}
+			return
+		}
 
-		// Get or create limiter for this IP
-		limiter := getLimiter(clientIP)
+		policy := anonymousPolicy
+		bucket := "ip:" + clientIP
+		key, authenticated := APIKeyFromContext(c)
+		if authenticated {
+			tier := key.TierInfo()
+			policy = ratelimit.Policy{RequestsPerMinute: tier.RequestsPerMinute, Burst: tier.Burst}
+			bucket = "key:" + key.ID
+		}
+
+		route := c.Request.Method + " " + c.FullPath()
+		if routePolicy, ok := routePolicies[route]; ok {
+			policy = routePolicy
+			bucket = route + " " + bucket
+		}
 
-		// Check if request can proceed
-		if !limiter.Allow() {
+		result := limiters.Allow(bucket, policy)
+		c.Header("X-RateLimit-Limit", strconv.Itoa(policy.RequestsPerMinute))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+
+		if !result.Allowed {
+			rejections.With("rate", routeLabel(c)).Inc()
+			c.Header("Retry-After", strconv.Itoa(max(seconds(result.RetryAfter), 1)))
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error": "rate limit exceeded",
 			})
 			c.Abort()
 			return
 		}
+		if rejectInvalidAPIKey(c) {
+			return
+		}
+
+		if authenticated {
+			usage, ok := keys.Consume(key)
+			if quota := key.TierInfo().DailyQuota; quota > 0 {
+				c.Header("X-Quota-Limit", strconv.Itoa(quota))
+				c.Header("X-Quota-Remaining", strconv.Itoa(max(quota-usage.Today, 0)))
+			}
+			if !ok {
+				rejections.With("quota", routeLabel(c)).Inc()
+				// Quotas reset at midnight UTC
+				now := time.Now().UTC()
+				midnight := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+				c.Header("Retry-After", strconv.Itoa(seconds(midnight.Sub(now))))
+				c.JSON(http.StatusTooManyRequests, gin.H{
+					"error": "daily quota exceeded",
+				})
+				c.Abort()
+				return
+			}
+		}
 
 		c.Next()
 	}
 }
 
-func getLimiter(clientIP string) *rate.Limiter {
-	// Create a new limiter if it doesn't exist
-	if limiter, exists := limiterMap[clientIP]; exists {
-		return limiter
+// seconds rounds a duration up to whole seconds
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// parseAllowlist parses IP addresses and CIDR ranges, skipping invalid entries
+func parseAllowlist(entries []string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				logger.Warn("Ignoring invalid rate limit allowlist entry", "entry", entry)
+				continue
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			logger.Warn("Ignoring invalid rate limit allowlist entry", "entry", entry)
+			continue
+		}
+		prefixes = append(prefixes, prefix.Masked())
 	}
+	return prefixes
+}
 
-	// Create a new rate limiter: requestsPerMinute requests per minute
-	limiter := rate.NewLimiter(rate.Every(time.Minute/requestsPerMinute), burstSize)
-	limiterMap[clientIP] = limiter
-	return limiter
+func allowlisted(allowlist []netip.Prefix, clientIP string) bool {
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range allowlist {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"regexp"
+
+	"tournois-tt/api/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// validRequestID restricts the request IDs accepted from clients
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestID returns a middleware that tags each request with an ID, taken from the
+// X-Request-ID header when valid, stored in the request context for logging and upstream
+// calls, and echoed in the response
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(logging.RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = logging.NewRequestID()
+		}
+
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Header(logging.RequestIDHeader, id)
+		c.Next()
+	}
+}
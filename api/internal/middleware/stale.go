@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"time"
+
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/degraded"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headers marking responses served from a stale snapshot
+const (
+	HeaderDataStale     = "X-Data-Stale"
+	HeaderDataUpdatedAt = "X-Data-Updated-At"
+)
+
+// StaleData returns a middleware marking responses as stale while mode is degraded, with
+// the time of the last successful refresh of store
+func StaleData(store *cache.Store, mode *degraded.Machine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if mode.Active() {
+			c.Header(HeaderDataStale, "true")
+			if last := store.LastCurrentSeasonRefresh(); !last.IsZero() {
+				c.Header(HeaderDataUpdatedAt, last.UTC().Format(time.RFC3339))
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,126 @@
+// Package openapi embeds the OpenAPI 3 document describing the API and validates
+// request parameters and response payloads against it.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+//go:embed openapi.json
+var rawSpec []byte
+
+// Document is the subset of an OpenAPI 3 document used for validation
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// PathItem maps lowercase HTTP methods to operations
+type PathItem map[string]*Operation
+
+// Components holds reusable schemas and responses
+type Components struct {
+	Schemas   map[string]*Schema   `json:"schemas"`
+	Responses map[string]*Response `json:"responses"`
+}
+
+// Operation describes a single API operation
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Parameters  []Parameter          `json:"parameters"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter describes a query or path parameter
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// Response describes an operation response, possibly by reference
+type Response struct {
+	Ref         string               `json:"$ref"`
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+// MediaType holds the schema of a response body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+var (
+	document *Document
+	loadErr  error
+	loadOnce sync.Once
+)
+
+// Raw returns the embedded OpenAPI document as JSON
+func Raw() []byte {
+	return rawSpec
+}
+
+// Load parses the embedded OpenAPI document
+func Load() (*Document, error) {
+	loadOnce.Do(func() {
+		document = &Document{}
+		if err := json.Unmarshal(rawSpec, document); err != nil {
+			loadErr = fmt.Errorf("failed to parse OpenAPI document: %v", err)
+		}
+	})
+	return document, loadErr
+}
+
+// Operation returns the operation for a Gin route pattern (e.g. "/v1/clubs/:id") and method
+func (d *Document) Operation(method, route string) (*Operation, bool) {
+	item, ok := d.Paths[ToOpenAPIPath(route)]
+	if !ok {
+		return nil, false
+	}
+	op, ok := item[strings.ToLower(method)]
+	return op, ok && op != nil
+}
+
+// ResponseSchema returns the JSON schema of an operation response for a status code
+func (d *Document) ResponseSchema(op *Operation, status int) (*Schema, error) {
+	return d.ResponseSchemaFor(op, status, "application/json")
+}
+
+// ResponseSchemaFor returns the schema of an operation response for a status code and media type
+func (d *Document) ResponseSchemaFor(op *Operation, status int, mediaType string) (*Schema, error) {
+	response, ok := op.Responses[fmt.Sprintf("%d", status)]
+	if !ok {
+		return nil, fmt.Errorf("operation %s does not document status %d", op.OperationID, status)
+	}
+
+	if response.Ref != "" {
+		name := strings.TrimPrefix(response.Ref, "#/components/responses/")
+		if response, ok = d.Components.Responses[name]; !ok {
+			return nil, fmt.Errorf("unknown response reference %s", name)
+		}
+	}
+
+	media, ok := response.Content[mediaType]
+	if !ok || media.Schema == nil {
+		return nil, fmt.Errorf("operation %s status %d has no %s schema", op.OperationID, status, mediaType)
+	}
+	return media.Schema, nil
+}
+
+// ToOpenAPIPath converts Gin path parameters (":id") to OpenAPI templates ("{id}")
+func ToOpenAPIPath(route string) string {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,210 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema is the subset of JSON Schema used by the API document
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *Schema            `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	Enum                 []any              `json:"enum"`
+	Pattern              string             `json:"pattern"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	Nullable             bool               `json:"nullable"`
+}
+
+// resolve follows a "#/components/schemas/..." reference
+func (d *Document) resolve(s *Schema) (*Schema, error) {
+	for s != nil && s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		resolved, ok := d.Components.Schemas[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown schema reference %s", s.Ref)
+		}
+		s = resolved
+	}
+	return s, nil
+}
+
+// ValidateJSON checks a JSON payload against a schema and returns every mismatch found.
+// Object properties that the schema does not declare are reported as well, so that
+// undocumented fields cannot silently appear in responses.
+func (d *Document) ValidateJSON(s *Schema, payload []byte) []string {
+	decoder := json.NewDecoder(strings.NewReader(string(payload)))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return []string{fmt.Sprintf("invalid JSON: %v", err)}
+	}
+
+	var problems []string
+	d.validate(s, value, "$", &problems)
+	return problems
+}
+
+func (d *Document) validate(s *Schema, value any, path string, problems *[]string) {
+	s, err := d.resolve(s)
+	if err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s: %v", path, err))
+		return
+	}
+	if s == nil {
+		return
+	}
+
+	if value == nil {
+		if !s.Nullable && s.Type != "" {
+			*problems = append(*problems, fmt.Sprintf("%s: null is not a %s", path, s.Type))
+		}
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf("%s: expected object", path))
+			return
+		}
+		d.validateObject(s, object, path, problems)
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf("%s: expected array", path))
+			return
+		}
+		for i, item := range items {
+			d.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf("%s: expected string", path))
+			return
+		}
+		if err := checkString(s, str); err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: %v", path, err))
+		}
+	case "integer", "number":
+		number, ok := value.(json.Number)
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf("%s: expected %s", path, s.Type))
+			return
+		}
+		if err := checkNumber(s, number.String()); err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: %v", path, err))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			*problems = append(*problems, fmt.Sprintf("%s: expected boolean", path))
+		}
+	}
+}
+
+func (d *Document) validateObject(s *Schema, object map[string]any, path string, problems *[]string) {
+	for _, name := range s.Required {
+		if _, ok := object[name]; !ok {
+			*problems = append(*problems, fmt.Sprintf("%s: missing required property %q", path, name))
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, declared := s.Properties[name]
+		switch {
+		case declared:
+			d.validate(property, object[name], path+"."+name, problems)
+		case s.AdditionalProperties != nil:
+			d.validate(s.AdditionalProperties, object[name], path+"."+name, problems)
+		case len(s.Properties) > 0:
+			*problems = append(*problems, fmt.Sprintf("%s: undocumented property %q", path, name))
+		}
+	}
+}
+
+// ValidateParameter checks the raw string value of a query or path parameter
+func ValidateParameter(p Parameter, raw string) error {
+	if p.Schema == nil {
+		return nil
+	}
+
+	switch p.Schema.Type {
+	case "integer", "number":
+		return checkNumber(p.Schema, raw)
+	case "boolean":
+		if _, err := strconv.ParseBool(raw); err != nil {
+			return fmt.Errorf("expected boolean")
+		}
+		return nil
+	default:
+		return checkString(p.Schema, raw)
+	}
+}
+
+func checkString(s *Schema, value string) error {
+	if len(s.Enum) > 0 {
+		allowed := make([]string, 0, len(s.Enum))
+		for _, candidate := range s.Enum {
+			if fmt.Sprint(candidate) == value {
+				return nil
+			}
+			allowed = append(allowed, fmt.Sprint(candidate))
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(allowed, ", "))
+	}
+
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q in schema: %v", s.Pattern, err)
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("must match %s", s.Pattern)
+		}
+	}
+
+	return nil
+}
+
+func checkNumber(s *Schema, raw string) error {
+	var value float64
+	if s.Type == "integer" {
+		i, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("expected integer")
+		}
+		value = float64(i)
+	} else {
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("expected number")
+		}
+		value = f
+	}
+
+	if s.Minimum != nil && value < *s.Minimum {
+		return fmt.Errorf("must be at least %v", *s.Minimum)
+	}
+	if s.Maximum != nil && value > *s.Maximum {
+		return fmt.Errorf("must be at most %v", *s.Maximum)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateJSON(t *testing.T) {
+	doc, err := Load()
+	require.NoError(t, err)
+
+	schema := &Schema{Ref: "#/components/schemas/Figures"}
+
+	assert.Empty(t, doc.ValidateJSON(schema, []byte(`{"count":1,"totalEndowment":2,"medianEndowment":2.5,"averageTableFee":0,"clubs":1}`)))
+
+	problems := doc.ValidateJSON(schema, []byte(`{"count":1.5,"totalEndowment":"2","medianEndowment":2,"clubs":1,"extra":true}`))
+	assert.ElementsMatch(t, []string{
+		`$: missing required property "averageTableFee"`,
+		`$.count: expected integer`,
+		`$.totalEndowment: expected integer`,
+		`$: undocumented property "extra"`,
+	}, problems)
+}
+
+func TestValidateParameter(t *testing.T) {
+	minimum := 1.0
+	id := Parameter{Name: "id", In: "path", Schema: &Schema{Type: "integer", Minimum: &minimum}}
+	assert.NoError(t, ValidateParameter(id, "42"))
+	assert.EqualError(t, ValidateParameter(id, "0"), "must be at least 1")
+	assert.EqualError(t, ValidateParameter(id, "abc"), "expected integer")
+
+	scope := Parameter{Name: "scope", In: "query", Schema: &Schema{Type: "string", Enum: []any{"all", "region"}}}
+	assert.NoError(t, ValidateParameter(scope, "region"))
+	assert.EqualError(t, ValidateParameter(scope, "world"), "must be one of all, region")
+}
+
+func TestToOpenAPIPath(t *testing.T) {
+	assert.Equal(t, "/v1/clubs/{id}", ToOpenAPIPath("/v1/clubs/:id"))
+	assert.Equal(t, "/v1/clubs", ToOpenAPIPath("/v1/clubs"))
+}
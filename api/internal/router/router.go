@@ -1,34 +1,40 @@
 package router
 
 import (
-	"tournois-tt/api/internal/config"
+	"tournois-tt/api/internal/app"
 	"tournois-tt/api/internal/handlers"
 	"tournois-tt/api/internal/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
-func NewRouter() *gin.Engine {
-	router := gin.Default()
+// NewRouter returns the router serving the API of the application a
+func NewRouter(a *app.App) *gin.Engine {
+	router := gin.New()
 	router.ForwardedByClientIP = true
 
 	// Only trust nginx reverse proxy
 	router.SetTrustedProxies([]string{"nginx"})
 
 	router.Use(gin.Recovery())
-	router.Use(middleware.RateLimiter())
-	router.Use(corsMiddleware())
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Logger(a.Config.Server.LogPrivacy))
+	router.Use(middleware.Metrics())
+	router.Use(middleware.APIKey(a.APIKeys))
+	router.Use(middleware.RateLimiter(a.Config.RateLimit, a.Limiters, a.APIKeys))
+	router.Use(corsMiddleware(a.Config.Server.FrontendURL))
 
-	setupRoutes(router)
+	setupRoutes(router, a)
 
 	return router
 }
 
-func corsMiddleware() gin.HandlerFunc {
+func corsMiddleware(frontendURL string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", config.FrontendURL)
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept, Origin")
+		c.Writer.Header().Set("Access-Control-Allow-Origin", frontendURL)
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept, Origin, Authorization, Last-Event-ID, X-API-Key, X-Request-ID")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset, Retry-After, X-Data-Stale, X-Data-Updated-At")
 		c.Writer.Header().Set("Access-Control-Max-Age", "86400") // 24 hours
 
 		if c.Request.Method == "OPTIONS" {
@@ -40,14 +46,62 @@ func corsMiddleware() gin.HandlerFunc {
 	}
 }
 
-func setupRoutes(router *gin.Engine) {
+func setupRoutes(router *gin.Engine, a *app.App) {
+	h := handlers.New(a)
+
 	v1 := router.Group("/v1")
+	v1.Use(middleware.ValidateRequest(), middleware.StaleData(a.Store, a.Degraded))
 	{
 		v1.GET("/healthz", handlers.HealthzHandler)
-		v1.GET("/tournaments", middleware.Logger(), handlers.TournamentsHandler)
-		v1.POST("/newsletter", handlers.NewsletterHandler)
+		v1.GET("/readyz", h.ReadyzHandler)
+		v1.GET("/openapi.json", handlers.OpenAPIHandler)
+		v1.GET("/tournaments", h.TournamentsHandler)
+		v1.GET("/stats", h.StatsHandler)
+		v1.GET("/clubs", h.ClubsHandler)
+		v1.GET("/clubs/:id", h.ClubHandler)
+		v1.GET("/regions", h.RegionsHandler)
+		v1.GET("/departments", h.DepartmentsHandler)
+		v1.GET("/events", h.EventsHandler)
+		v1.GET("/graphql", h.GraphQLHandler)
+		v1.POST("/graphql", h.GraphQLHandler)
+		v1.GET("/usage", h.UsageHandler)
+		v1.POST("/newsletter", h.NewsletterHandler)
+		v1.POST("/webhooks", h.CreateWebhookHandler)
+		v1.GET("/webhooks/:id", h.WebhookHandler)
+		v1.DELETE("/webhooks/:id", h.DeleteWebhookHandler)
+		v1.GET("/webhooks/:id/deliveries", h.WebhookDeliveriesHandler)
+	}
+
+	v2 := router.Group("/v2")
+	v2.Use(middleware.ValidateRequest(), middleware.StaleData(a.Store, a.Degraded))
+	{
+		v2.GET("/tournaments", h.TournamentsV2Handler)
+		v2.GET("/tournaments/:id", h.TournamentV2Handler)
+		v2.GET("/clubs", h.ClubsV2Handler)
+		v2.GET("/clubs/:id", h.ClubV2Handler)
 	}
 
+	// Operations on the data pipeline, every change is audited
+	admin := router.Group("/admin")
+	admin.Use(middleware.AdminAuth(a.Config.Admin, a.Audit))
+	{
+		admin.GET("/ui", h.AdminDashboardHandler)
+		admin.GET("/stats", h.AdminStatsHandler)
+		admin.GET("/audit", h.AdminAuditHandler)
+		admin.GET("/jobs", h.AdminJobsHandler)
+		admin.GET("/config", h.AdminConfigHandler)
+		admin.POST("/refresh", h.AdminRefreshHandler)
+		admin.POST("/geocode/failed", h.AdminRegeocodeFailedHandler)
+		admin.POST("/tournaments/:id/geocode", h.AdminRegeocodeHandler)
+		admin.PUT("/tournaments/:id/coordinates", h.AdminCoordinatesHandler)
+		admin.DELETE("/tournaments/:id", h.AdminPurgeHandler)
+		admin.POST("/tournaments/:id/restore", h.AdminRestoreHandler)
+		admin.GET("/purged", h.AdminPurgedHandler)
+	}
+
+	// Prometheus scraping, outside of the public API
+	router.GET("/metrics", middleware.MetricsHandler(a.Config.Server.MetricsToken))
+
 	// Direct redirect from root id to rules pdf: /:id -> rules url or '/'
-	router.GET("/:id", handlers.RedirectRulesHandler)
+	router.GET("/:id", h.RedirectRulesHandler)
 }
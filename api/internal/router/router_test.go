@@ -0,0 +1,743 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"tournois-tt/api/internal/app"
+	"tournois-tt/api/internal/config"
+	"tournois-tt/api/internal/crons"
+	"tournois-tt/api/internal/middleware"
+	"tournois-tt/api/internal/openapi"
+	"tournois-tt/api/pkg/apikeys"
+	"tournois-tt/api/pkg/audit"
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/events"
+	"tournois-tt/api/pkg/geocoding"
+	"tournois-tt/api/pkg/webhooks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestApp returns an application with an empty store in a temporary directory, and the
+// default configuration modified by change when it is not nil
+func newTestApp(t *testing.T, change func(*config.Config)) *app.App {
+	t.Helper()
+
+	cfg := config.Defaults()
+	if change != nil {
+		change(cfg)
+	}
+	a, err := app.NewDryRun(cfg, t.TempDir())
+	require.NoError(t, err)
+	return a
+}
+
+// seedStore fills the store of the application with a small fixture
+func seedStore(a *app.App) {
+	upcoming := time.Now().AddDate(0, 1, 0).Format("2006-01-02T15:04:05")
+
+	a.Store.Set(cache.TournamentCache{
+		ID:        3340,
+		Name:      "Tournoi de Rennes",
+		Type:      "P",
+		StartDate: upcoming,
+		EndDate:   upcoming,
+		Address: geocoding.Address{
+			StreetAddress:   "1 rue du Gymnase",
+			PostalCode:      "35000",
+			AddressLocality: "Rennes",
+			Latitude:        48.11,
+			Longitude:       -1.67,
+		},
+		Club:      cache.Club{ID: 42, Name: "TT Rennes", Code: "07350042", Identifier: "07350042"},
+		Rules:     &cache.Rules{AgeMin: 8, URL: "https://example.com/rules.pdf"},
+		Tables:    []cache.Table{{Name: "A", Fee: 8, Endowment: 100}},
+		Endowment: 100,
+	})
+	a.Store.Set(cache.TournamentCache{
+		ID:        3341,
+		Name:      "Tournoi de Bastia",
+		Type:      "R",
+		StartDate: "2025-10-04T00:00:00",
+		EndDate:   "2025-10-04T00:00:00",
+		Address:   geocoding.Address{PostalCode: "20200", AddressLocality: "Bastia"},
+		Club:      cache.Club{ID: 43, Name: "Bastia TT"},
+	})
+}
+
+func TestResponsesMatchOpenAPIDocument(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a := newTestApp(t, nil)
+	seedStore(a)
+
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+
+	a.Webhooks.SetResolver(publicResolver)
+	token := newTestAPIKey(t, a)
+	a.Store.MarkCurrentSeasonRefreshed(time.Now())
+
+	r := NewRouter(a)
+
+	testCases := []struct {
+		method string
+		target string
+		body   string
+		status int
+		accept string
+	}{
+		{"GET", "/v1/healthz", "", http.StatusOK, ""},
+		{"GET", "/v1/readyz", "", http.StatusOK, ""},
+		{"GET", "/v1/openapi.json", "", http.StatusOK, ""},
+		{"GET", "/v1/tournaments", "", http.StatusOK, ""},
+		{"GET", "/v1/tournaments?postalCode=35", "", http.StatusOK, ""},
+		{"GET", "/v1/tournaments?postalCode=abc", "", http.StatusBadRequest, ""},
+		{"GET", "/v1/stats?season=2025-2026", "", http.StatusOK, ""},
+		{"GET", "/v1/stats?season=2025", "", http.StatusBadRequest, ""},
+		{"GET", "/v1/stats?season=2025-2027", "", http.StatusBadRequest, ""},
+		{"GET", "/v1/clubs", "", http.StatusOK, ""},
+		{"GET", "/v1/clubs?department=2B", "", http.StatusOK, ""},
+		{"GET", "/v1/clubs?department=999", "", http.StatusBadRequest, ""},
+		{"GET", "/v1/clubs/42", "", http.StatusOK, ""},
+		{"GET", "/v1/clubs/abc", "", http.StatusBadRequest, ""},
+		{"GET", "/v1/clubs/7", "", http.StatusNotFound, ""},
+		{"GET", "/v1/regions", "", http.StatusOK, ""},
+		{"GET", "/v1/departments?region=53", "", http.StatusOK, ""},
+		{"GET", "/v1/departments?region=Bretagne", "", http.StatusBadRequest, ""},
+		{"POST", "/v1/newsletter", `{}`, http.StatusBadRequest, ""},
+		{"POST", "/v1/webhooks", `{"url":"ftp://example.com"}`, http.StatusBadRequest, ""},
+		{"POST", "/v1/webhooks", `{"url":"https://example.com/hook","department":"35","events":["tournament.created"]}`, http.StatusCreated, ""},
+		{"GET", "/v1/webhooks/0b8f6b8e-3c1d-4a43-9d6c-1f0a6f1f1d2e", "", http.StatusNotFound, ""},
+		{"GET", "/v1/webhooks/0b8f6b8e-3c1d-4a43-9d6c-1f0a6f1f1d2e/deliveries?status=dead", "", http.StatusNotFound, ""},
+		{"GET", "/v1/webhooks/nope", "", http.StatusBadRequest, ""},
+		{"POST", "/v1/graphql", `{"query":"{ tournament(id: 3340) { name tables { fee } club { name } } }"}`, http.StatusOK, ""},
+		{"POST", "/v1/graphql", `{"query":"{ unknown }"}`, http.StatusOK, ""},
+		{"POST", "/v1/graphql", `not json`, http.StatusBadRequest, ""},
+		{"GET", "/v1/graphql?query=%7Btournaments(first%3A1)%7BtotalCount%7D%7D", "", http.StatusOK, ""},
+		{"GET", "/v1/graphql?variables=nope", "", http.StatusBadRequest, ""},
+		{"GET", "/v2/tournaments", "", http.StatusOK, "application/ld+json"},
+		{"GET", "/v2/tournaments?itemsPerPage=1&page=2&order[startDate]=desc", "", http.StatusOK, ""},
+		{"GET", "/v2/tournaments?type=P&department=35", "", http.StatusOK, "application/json"},
+		{"GET", "/v2/tournaments?page=0", "", http.StatusBadRequest, ""},
+		{"GET", "/v2/tournaments", "", http.StatusNotAcceptable, "text/html"},
+		{"GET", "/v2/tournaments/3340", "", http.StatusOK, "application/ld+json"},
+		{"GET", "/v2/tournaments/3340", "", http.StatusOK, "application/json"},
+		{"GET", "/v2/tournaments/1", "", http.StatusNotFound, ""},
+		{"GET", "/v2/clubs", "", http.StatusOK, ""},
+		{"GET", "/v2/clubs/42", "", http.StatusOK, "application/ld+json"},
+		{"GET", "/v2/clubs/42", "", http.StatusOK, "application/json"},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.method+" "+tc.target, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			if tc.method == "POST" && tc.target == "/v1/webhooks" {
+				req.Header.Set(middleware.APIKeyHeader, token)
+			}
+			// Use a distinct client per request to stay below the rate limit
+			req.RemoteAddr = fmt.Sprintf("192.0.2.%d:1234", i+1)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.status, w.Code, w.Body.String())
+
+			route := strings.SplitN(tc.target, "?", 2)[0]
+			for _, info := range r.Routes() {
+				if info.Method == tc.method && matchesRoute(info.Path, route) {
+					route = info.Path
+					break
+				}
+			}
+
+			op, ok := doc.Operation(tc.method, route)
+			require.True(t, ok, "route %s %s is not documented", tc.method, route)
+
+			mediaType := "application/json"
+			if strings.HasPrefix(w.Header().Get("Content-Type"), "application/ld+json") {
+				mediaType = "application/ld+json"
+			}
+
+			schema, err := doc.ResponseSchemaFor(op, w.Code, mediaType)
+			require.NoError(t, err)
+			assert.Empty(t, doc.ValidateJSON(schema, w.Body.Bytes()))
+		})
+	}
+}
+
+func TestAllRoutesAreDocumented(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+
+	registered := make(map[string]bool)
+	for _, info := range NewRouter(newTestApp(t, nil)).Routes() {
+		if !strings.HasPrefix(info.Path, "/v1/") && !strings.HasPrefix(info.Path, "/v2/") {
+			continue
+		}
+		registered[info.Method+" "+openapi.ToOpenAPIPath(info.Path)] = true
+
+		_, ok := doc.Operation(info.Method, info.Path)
+		assert.True(t, ok, "route %s %s is missing from the OpenAPI document", info.Method, info.Path)
+	}
+
+	for path, item := range doc.Paths {
+		for method := range item {
+			assert.True(t, registered[strings.ToUpper(method)+" "+path], "documented operation %s %s is not routed", method, path)
+		}
+	}
+}
+
+func TestEventsStreamReplaysFilteredEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	a := newTestApp(t, nil)
+	bus := a.Events
+	before := bus.Publish(events.TournamentCreated, cache.TournamentCache{ID: 1}, nil)
+	bus.Publish(events.TournamentCreated, cache.TournamentCache{ID: 2, Type: "P", Club: cache.Club{Department: "35"}}, nil)
+	bus.Publish(events.TournamentCancelled, cache.TournamentCache{ID: 3, Type: "P", Club: cache.Club{Department: "2B"}}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req := httptest.NewRequest("GET", "/v1/events?department=35", nil).WithContext(ctx)
+	req.Header.Set("Last-Event-ID", bus.StreamID(before))
+	w := httptest.NewRecorder()
+	NewRouter(a).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), fmt.Sprintf("id: %s-%d\nevent: tournament.created\n", bus.Epoch(), before.ID+1))
+	assert.NotContains(t, w.Body.String(), "tournament.cancelled")
+	assert.NotContains(t, w.Body.String(), "event: reset")
+
+	// Ids sent before a restart, with another epoch or none, are unknown
+	for i, lastEventID := range []string{"other-1", fmt.Sprint(before.ID)} {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		req := httptest.NewRequest("GET", "/v1/events", nil).WithContext(ctx)
+		req.RemoteAddr = fmt.Sprintf("203.0.113.%d:1234", 70+i)
+		req.Header.Set("Last-Event-ID", lastEventID)
+		w := httptest.NewRecorder()
+		NewRouter(a).ServeHTTP(w, req)
+		cancel()
+
+		assert.Contains(t, w.Body.String(), "event: reset", lastEventID)
+		assert.NotContains(t, w.Body.String(), "event: tournament.created", "nothing is replayed after a reset")
+	}
+}
+
+// publicResolver resolves every host to a public address
+func publicResolver(string) ([]net.IP, error) {
+	return []net.IP{net.ParseIP("93.184.215.14")}, nil
+}
+
+// newTestAPIKey returns a new free key of the application
+func newTestAPIKey(t *testing.T, a *app.App) string {
+	t.Helper()
+
+	_, token, err := a.APIKeys.Create("Ligue de Bretagne", "free")
+	require.NoError(t, err)
+	return token
+}
+
+func TestWebhookSubscriptionLifecycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	a := newTestApp(t, nil)
+	a.Webhooks.SetResolver(publicResolver)
+	token := newTestAPIKey(t, a)
+
+	r := NewRouter(a)
+	calls := 0
+	call := func(method, target, secret, body string) *httptest.ResponseRecorder {
+		calls++
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.RemoteAddr = fmt.Sprintf("198.51.100.%d:1234", calls)
+		req.Header.Set("Content-Type", "application/json")
+		if secret != "" {
+			req.Header.Set("Authorization", "Bearer "+secret)
+		}
+		if method == "POST" {
+			req.Header.Set(middleware.APIKeyHeader, token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	req := httptest.NewRequest("POST", "/v1/webhooks", strings.NewReader(`{"url":"https://example.com/hook"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "creating a webhook requires an API key")
+
+	w = call("POST", "/v1/webhooks", "", `{"url":"https://169.254.169.254/latest/meta-data"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = call("POST", "/v1/webhooks", "", `{"url":"https://example.com/hook"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var created webhooks.Subscription
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.NotEmpty(t, created.Secret)
+	target := "/v1/webhooks/" + created.ID
+
+	assert.Equal(t, http.StatusUnauthorized, call("GET", target, "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, call("GET", target, "whsec_wrong", "").Code)
+
+	w = call("GET", target, created.Secret, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), created.Secret)
+
+	w = call("GET", target+"/deliveries", created.Secret, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[]`, w.Body.String())
+
+	assert.Equal(t, http.StatusNoContent, call("DELETE", target, created.Secret, "").Code)
+	assert.Equal(t, http.StatusNotFound, call("GET", target, created.Secret, "").Code)
+}
+
+func TestAPIKeysGetTheirOwnLimitsAndUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	a := newTestApp(t, nil)
+	token := newTestAPIKey(t, a)
+
+	r := NewRouter(a)
+	call := func(target, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		// Every call shares an IP, as integrators behind a gateway do
+		req.RemoteAddr = "203.0.113.7:1234"
+		if key != "" {
+			req.Header.Set(middleware.APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 5; i++ {
+		require.Equal(t, http.StatusOK, call("/v1/openapi.json", "").Code)
+	}
+	w := call("/v1/openapi.json", "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "45", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+	for i := 0; i < 10; i++ {
+		require.Equal(t, http.StatusOK, call("/v1/openapi.json", token).Code)
+	}
+	w = call("/v1/usage", token)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "120", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "9", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "9989", w.Header().Get("X-Quota-Remaining"))
+
+	var usage struct {
+		Tier  apikeys.Tier  `json:"tier"`
+		Usage apikeys.Usage `json:"usage"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &usage))
+	assert.Equal(t, 10000, usage.Tier.DailyQuota)
+	assert.Equal(t, 11, usage.Usage.Today)
+
+	// Invalid keys are counted against the IP, exhausted by the anonymous calls above
+	assert.Equal(t, http.StatusTooManyRequests, call("/v1/openapi.json", token+"x").Code)
+	req := httptest.NewRequest("GET", "/v1/openapi.json", nil)
+	req.RemoteAddr = "203.0.113.8:1234"
+	req.Header.Set(middleware.APIKeyHeader, token+"x")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "4", w.Header().Get("X-RateLimit-Remaining"), "the attempt is counted")
+}
+
+func TestRoutePoliciesAndAllowlist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := NewRouter(newTestApp(t, func(c *config.Config) {
+		c.RateLimit.Allowlist = []string{"10.10.0.0/16", "not an ip"}
+	}))
+	call := func(method, target, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(`{}`))
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Uptime monitors can poll beyond the anonymous burst
+	for i := 0; i < 20; i++ {
+		require.Equal(t, http.StatusOK, call("GET", "/v1/healthz", "203.0.113.20:1234").Code)
+	}
+
+	// Newsletter subscriptions are limited in their own, stricter bucket
+	for i := 0; i < 2; i++ {
+		require.NotEqual(t, http.StatusTooManyRequests, call("POST", "/v1/newsletter", "203.0.113.21:1234").Code)
+	}
+	w := call("POST", "/v1/newsletter", "203.0.113.21:1234")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "3", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "20", w.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, call("GET", "/v1/openapi.json", "203.0.113.21:1234").Code)
+
+	// Internal callers are not limited
+	for i := 0; i < 20; i++ {
+		w := call("GET", "/v1/openapi.json", "10.10.3.4:1234")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+	}
+}
+
+func TestRequestIDs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := NewRouter(newTestApp(t, nil))
+
+	call := func(requestID string) string {
+		req := httptest.NewRequest("GET", "/v1/healthz", nil)
+		req.RemoteAddr = "203.0.113.30:1234"
+		if requestID != "" {
+			req.Header.Set("X-Request-ID", requestID)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Header().Get("X-Request-ID")
+	}
+
+	assert.Equal(t, "frontend-1234", call("frontend-1234"))
+
+	generated := call("")
+	assert.Len(t, generated, 36)
+	assert.NotEqual(t, generated, call(""))
+
+	rejected := call("bad id\nwith newline")
+	assert.Len(t, rejected, 36)
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := NewRouter(newTestApp(t, func(c *config.Config) { c.Server.MetricsToken = "scraper" }))
+
+	call := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = "203.0.113.40:1234"
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, call("/v1/healthz", "").Code)
+
+	assert.Equal(t, http.StatusUnauthorized, call("/metrics", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, call("/metrics", "wrong").Code)
+
+	w := call("/metrics", "scraper")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `tournois_http_requests_total{method="GET",route="/v1/healthz",status="200"}`)
+	assert.Contains(t, w.Body.String(), "# TYPE tournois_http_request_duration_seconds histogram")
+	assert.Contains(t, w.Body.String(), "tournois_cache_tournaments ")
+
+	r = NewRouter(newTestApp(t, nil))
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "metrics are closed without a token")
+}
+
+func TestReadyzReportsFreshness(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a := newTestApp(t, func(c *config.Config) { c.Brevo.APIKey = "test" })
+	seedStore(a)
+	r := NewRouter(a)
+
+	call := func() (int, map[string]any) {
+		req := httptest.NewRequest("GET", "/v1/readyz", nil)
+		req.RemoteAddr = "203.0.113.50:1234"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return w.Code, body
+	}
+
+	a.Store.MarkCurrentSeasonRefreshed(time.Now().Add(-time.Minute))
+	code, body := call()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", body["refresh"].(map[string]any)["status"])
+	assert.Equal(t, float64(2), body["cache"].(map[string]any)["tournaments"])
+	assert.Equal(t, "closed", body["fftt"].(map[string]any)["circuit"])
+	assert.Equal(t, true, body["brevo"].(map[string]any)["configured"])
+
+	a.Store.MarkCurrentSeasonRefreshed(time.Now().Add(-a.Config.Readiness.DegradedAfter - time.Minute))
+	code, body = call()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "degraded", body["status"])
+
+	a.Store.MarkCurrentSeasonRefreshed(time.Now().Add(-a.Config.Readiness.DownAfter - time.Minute))
+	code, body = call()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "down", body["status"])
+}
+
+func TestReadyzIgnoresUnconfiguredBrevo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a := newTestApp(t, nil)
+	seedStore(a)
+	a.Store.MarkCurrentSeasonRefreshed(time.Now().Add(-time.Minute))
+
+	req := httptest.NewRequest("GET", "/v1/readyz", nil)
+	req.RemoteAddr = "203.0.113.51:1234"
+	w := httptest.NewRecorder()
+	NewRouter(a).ServeHTTP(w, req)
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, false, body["brevo"].(map[string]any)["configured"])
+	assert.Equal(t, "ok", body["status"], "Brevo is optional")
+}
+
+func TestDegradedModeMarksResponsesStale(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a := newTestApp(t, func(c *config.Config) {
+		c.Brevo.APIKey = "test"
+		c.Refresh.DegradedAfterFailures = 1
+	})
+	seedStore(a)
+	r := NewRouter(a)
+
+	calls := 0
+	call := func(target string) *httptest.ResponseRecorder {
+		calls++
+		req := httptest.NewRequest("GET", target, nil)
+		req.RemoteAddr = fmt.Sprintf("203.0.113.%d:1234", 70+calls)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := call("/v1/tournaments")
+	assert.Empty(t, w.Header().Get(middleware.HeaderDataStale))
+	assert.NotContains(t, w.Body.String(), `"stale"`)
+
+	// Far older than the down threshold, but FFTT outages keep the API serving
+	refreshed := time.Now().Add(-a.Config.Readiness.DownAfter - time.Hour)
+	a.Store.MarkCurrentSeasonRefreshed(refreshed)
+	a.Degraded.Failed(errors.New("FFTT returned 503"))
+
+	w = call("/v1/tournaments")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get(middleware.HeaderDataStale))
+	assert.Equal(t, refreshed.UTC().Format(time.RFC3339), w.Header().Get(middleware.HeaderDataUpdatedAt))
+	var tournaments []map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tournaments))
+	require.NotEmpty(t, tournaments)
+	assert.Equal(t, true, tournaments[0]["stale"])
+
+	w = call("/v2/tournaments")
+	assert.Equal(t, "true", w.Header().Get(middleware.HeaderDataStale))
+	assert.Contains(t, w.Body.String(), `"stale":true`)
+
+	w = call("/v1/readyz")
+	require.Equal(t, http.StatusOK, w.Code)
+	var readiness map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &readiness))
+	assert.Equal(t, "degraded", readiness["status"])
+	assert.Equal(t, "degraded", readiness["refresh"].(map[string]any)["mode"])
+
+	a.Store.MarkCurrentSeasonRefreshed(time.Now())
+	a.Degraded.Succeeded()
+	assert.Empty(t, call("/v1/tournaments").Header().Get(middleware.HeaderDataStale))
+}
+
+func TestAdminAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a := newTestApp(t, func(c *config.Config) { c.Admin.Token = "admin-secret" })
+	seedStore(a)
+
+	r := NewRouter(a)
+
+	calls := 0
+	call := func(method, target, token, body string) *httptest.ResponseRecorder {
+		calls++
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.RemoteAddr = fmt.Sprintf("203.0.113.%d:1234", 100+calls)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	disabled := httptest.NewRecorder()
+	NewRouter(newTestApp(t, nil)).ServeHTTP(disabled, httptest.NewRequest("GET", "/admin/stats", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, disabled.Code)
+
+	assert.Equal(t, http.StatusUnauthorized, call("GET", "/admin/stats", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, call("GET", "/admin/stats", "wrong", "").Code)
+
+	w := call("GET", "/admin/stats", "admin-secret", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var stats struct {
+		Cache cache.Stats `json:"cache"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, 2, stats.Cache.Tournaments)
+	assert.Equal(t, 1, stats.Cache.MissingCoordinates)
+
+	w = call("PUT", "/admin/tournaments/3341/coordinates", "admin-secret", `{"latitude":42.7,"longitude":9.45}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusBadRequest, call("PUT", "/admin/tournaments/3341/coordinates", "admin-secret", `{"latitude":142}`).Code)
+
+	// Tournaments are not written while a refresh runs
+	refreshing, done, finished := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(finished)
+		a.Jobs.Run(context.Background(), crons.Job{Name: crons.RefreshTournaments, Run: func(context.Context) error {
+			close(refreshing)
+			<-done
+			return nil
+		}})
+	}()
+	<-refreshing
+	assert.Equal(t, http.StatusConflict, call("PUT", "/admin/tournaments/3341/coordinates", "admin-secret", `{"latitude":1,"longitude":1}`).Code)
+	assert.Equal(t, http.StatusConflict, call("POST", "/admin/tournaments/3341/geocode", "admin-secret", "").Code)
+	close(done)
+	<-finished
+	tournament, _ := a.Store.Get(3341)
+	assert.Equal(t, 42.7, tournament.Address.Latitude)
+
+	require.Equal(t, http.StatusOK, call("DELETE", "/admin/tournaments/3340", "admin-secret", "").Code)
+	assert.Equal(t, http.StatusNotFound, call("DELETE", "/admin/tournaments/3340", "admin-secret", "").Code)
+	_, ok := a.Store.Get(3340)
+	assert.False(t, ok)
+	assert.Contains(t, call("GET", "/admin/purged", "admin-secret", "").Body.String(), "Tournoi de Rennes")
+
+	require.Equal(t, http.StatusOK, call("POST", "/admin/tournaments/3340/restore", "admin-secret", "").Code)
+	_, ok = a.Store.Get(3340)
+	assert.True(t, ok)
+
+	assert.Equal(t, http.StatusBadRequest, call("POST", "/admin/refresh", "admin-secret", `{"from":"yesterday"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, call("POST", "/admin/refresh", "admin-secret", `{"from":"2025-09-01","to":"2025-08-01"}`).Code)
+	assert.Equal(t, http.StatusServiceUnavailable, call("POST", "/admin/refresh", "admin-secret", `{"from":"2025-09-01"}`).Code)
+
+	w = call("GET", "/admin/jobs?job=refresh-tournaments", "admin-secret", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"runs":[`)
+	assert.Equal(t, http.StatusBadRequest, call("GET", "/admin/jobs?limit=0", "admin-secret", "").Code)
+
+	w = call("GET", "/admin/audit?limit=10", "admin-secret", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var entries []audit.Entry
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+	actions := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		actions = append(actions, entry.Outcome+" "+entry.Action)
+	}
+	assert.Equal(t, []string{
+		"ok tournament.restore",
+		"error tournament.purge",
+		"ok tournament.purge",
+		"ok tournament.coordinates",
+		"denied auth",
+	}, actions, "denied requests are recorded once per minute")
+	assert.Equal(t, "token", entries[0].Actor)
+}
+
+func TestAdminDashboard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a := newTestApp(t, func(c *config.Config) { c.Admin.Token = "admin-secret" })
+	seedStore(a)
+
+	r := NewRouter(a)
+
+	req := httptest.NewRequest("GET", "/3340", nil)
+	req.RemoteAddr = "203.0.113.60:1234"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusFound, w.Code)
+
+	req = httptest.NewRequest("GET", "/admin/ui", nil)
+	req.RemoteAddr = "203.0.113.61:1234"
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Basic")
+
+	req = httptest.NewRequest("GET", "/admin/ui", nil)
+	req.RemoteAddr = "203.0.113.62:1234"
+	req.SetBasicAuth("ops", "admin-secret")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	body := w.Body.String()
+	assert.Contains(t, body, "Règlements les plus consultés")
+	assert.Contains(t, body, "<td>3340</td><td>Tournoi de Rennes</td><td class=\"num\">1</td>")
+}
+
+func TestAdminConfigRedactsSecrets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := NewRouter(newTestApp(t, func(c *config.Config) {
+		c.Admin.Token = "admin-secret"
+		c.Brevo.APIKey = "brevo-secret"
+	}))
+
+	req := httptest.NewRequest("GET", "/admin/config", nil)
+	req.RemoteAddr = "203.0.113.90:1234"
+	req.Header.Set("Authorization", "Bearer admin-secret")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "admin-secret")
+	assert.NotContains(t, w.Body.String(), "brevo-secret")
+
+	var body struct {
+		Settings []config.Setting `json:"settings"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	settings := make(map[string]config.Setting)
+	for _, setting := range body.Settings {
+		settings[setting.Env] = setting
+	}
+	assert.Equal(t, "[redacted]", settings["BREVO_API_KEY"].Value)
+	assert.Equal(t, "", settings["GEOCODING_GOOGLE_API_KEY"].Value, "unset secrets stay empty")
+	assert.Equal(t, "*/5 * * * *", settings["REFRESH_SCHEDULE"].Value)
+	assert.Equal(t, "refresh.schedule", settings["REFRESH_SCHEDULE"].Key)
+}
+
+// matchesRoute reports whether a request path matches a Gin route pattern
+func matchesRoute(pattern, path string) bool {
+	patternSegments := strings.Split(pattern, "/")
+	pathSegments := strings.Split(path, "/")
+	if len(patternSegments) != len(pathSegments) {
+		return false
+	}
+	for i, segment := range patternSegments {
+		if !strings.HasPrefix(segment, ":") && segment != pathSegments[i] {
+			return false
+		}
+	}
+	return true
+}
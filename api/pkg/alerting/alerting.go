@@ -0,0 +1,326 @@
+// Package alerting notifies operators of incidents through configurable channels. Each
+// severity is routed to its own channels so that alerts can escalate, e.g. from the logs
+// to email. Repeated alerts are deduplicated and rate limited, and resolving an incident
+// notifies the channels that were told about it.
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"tournois-tt/api/pkg/logging"
+	"tournois-tt/api/pkg/metrics"
+
+	"golang.org/x/time/rate"
+)
+
+var logger = logging.For("alerting")
+
+var alertsSent = metrics.NewCounterVec("tournois_alerts_total",
+	"Alerts by severity and result: sent, resolved, failed, deduplicated or rate_limited", "severity", "result")
+
+// Severity is how urgent an alert is
+type Severity string
+
+// Severities, from least to most urgent
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+func (s Severity) rank() int {
+	switch s {
+	case SeverityCritical:
+		return 2
+	case SeverityWarning:
+		return 1
+	}
+	return 0
+}
+
+// Default limits of a dispatcher
+const (
+	DefaultRepeatInterval = time.Hour
+	DefaultPerHour        = 30
+)
+
+// notifyTimeout bounds the time spent notifying a channel
+const notifyTimeout = 10 * time.Second
+
+// queueSize is the number of alerts fired and waiting to be delivered
+const queueSize = 100
+
+// Alert is a notification about an incident. Alerts about the same incident share a key.
+type Alert struct {
+	Key      string    `json:"key"`
+	Severity Severity  `json:"severity"`
+	Title    string    `json:"title"`
+	Message  string    `json:"message"`
+	Time     time.Time `json:"time"`
+	Resolved bool      `json:"resolved,omitempty"`
+}
+
+// Subject is a one line summary of the alert
+func (a Alert) Subject() string {
+	if a.Resolved {
+		return "[RESOLVED] " + a.Title
+	}
+	return "[" + strings.ToUpper(string(a.Severity)) + "] " + a.Title
+}
+
+// Text is the subject followed by the message
+func (a Alert) Text() string {
+	if a.Message == "" {
+		return a.Subject()
+	}
+	return a.Subject() + "\n" + a.Message
+}
+
+// Notifier delivers alerts to a channel
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// incident is an alert that was fired and not resolved yet
+type incident struct {
+	severity Severity
+	// notified is the last time channels were told about the incident, zero if never
+	notified time.Time
+}
+
+// Dispatcher sends alerts to the channels routed for their severity
+type Dispatcher struct {
+	mu        sync.RWMutex
+	channels  map[string]Notifier
+	routes    map[Severity][]string
+	now       func() time.Time
+	repeat    time.Duration
+	limiter   *rate.Limiter
+	incidents map[string]incident
+	// queue delivers fired alerts one at a time, in order, until closed
+	queue   chan queued
+	pending sync.WaitGroup
+	closed  bool
+	stopped chan struct{}
+}
+
+type queued struct {
+	alert     Alert
+	notifiers map[string]Notifier
+}
+
+// NewDispatcher returns a dispatcher without channels, with the default limits
+func NewDispatcher() *Dispatcher {
+	d := &Dispatcher{
+		channels:  make(map[string]Notifier),
+		routes:    make(map[Severity][]string),
+		now:       time.Now,
+		incidents: make(map[string]incident),
+		queue:     make(chan queued, queueSize),
+		stopped:   make(chan struct{}),
+	}
+	d.SetLimits(DefaultRepeatInterval, DefaultPerHour)
+	go func() {
+		defer close(d.stopped)
+		for q := range d.queue {
+			d.deliver(context.Background(), q.alert, q.notifiers)
+			d.pending.Done()
+		}
+	}()
+	return d
+}
+
+// NewLogDispatcher returns a dispatcher logging every alert, the dispatcher of the API until
+// its channels are configured
+func NewLogDispatcher() *Dispatcher {
+	d := NewDispatcher()
+	d.AddChannel("log", LogNotifier{})
+	for _, severity := range []Severity{SeverityInfo, SeverityWarning, SeverityCritical} {
+		d.Route(severity, "log")
+	}
+	return d
+}
+
+// AddChannel registers a channel under a name
+func (d *Dispatcher) AddChannel(name string, notifier Notifier) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.channels[name] = notifier
+}
+
+// Route sets the channels notified of alerts of a severity
+func (d *Dispatcher) Route(severity Severity, channels ...string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.routes[severity] = channels
+}
+
+// SetLimits sets how long an unresolved alert is not repeated, and how many alerts may be
+// sent per hour. Resolve notifications are never limited.
+func (d *Dispatcher) SetLimits(repeat time.Duration, perHour int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.repeat = repeat
+	d.limiter = rate.NewLimiter(rate.Limit(float64(perHour)/3600), perHour)
+}
+
+// Fire sends an alert in the background. Deduplication happens before returning, so that
+// alerts fired in a row about the same incident are handled in order. A nil dispatcher
+// drops alerts.
+func (d *Dispatcher) Fire(alert Alert) {
+	if d == nil {
+		return
+	}
+	notifiers, ok := d.admit(&alert)
+	if !ok {
+		return
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		logger.Error("Alert dropped, the dispatcher is closed", "key", alert.Key, "title", alert.Title)
+		return
+	}
+	d.pending.Add(1)
+	select {
+	case d.queue <- queued{alert: alert, notifiers: notifiers}:
+	default:
+		d.pending.Done()
+		logger.Error("Alert dropped, too many alerts waiting", "key", alert.Key, "title", alert.Title)
+	}
+}
+
+// Resolve notifies that the incident of a key is over, if an alert was fired for it
+func (d *Dispatcher) Resolve(key, title, message string) {
+	d.Fire(Alert{Key: key, Title: title, Message: message, Resolved: true})
+}
+
+// Wait waits for the alerts fired so far to be delivered
+func (d *Dispatcher) Wait() {
+	d.pending.Wait()
+}
+
+// Close delivers the queued alerts and stops the delivery goroutine. Alerts fired
+// afterwards are dropped.
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+	<-d.stopped
+}
+
+// Send notifies the channels routed for the severity of an alert, unless the same incident
+// was notified recently or too many alerts were sent. It returns the errors of the channels
+// that failed.
+func (d *Dispatcher) Send(ctx context.Context, alert Alert) error {
+	notifiers, ok := d.admit(&alert)
+	if !ok {
+		return nil
+	}
+	return d.deliver(ctx, alert, notifiers)
+}
+
+// deliver notifies channels of an admitted alert
+func (d *Dispatcher) deliver(ctx context.Context, alert Alert, notifiers map[string]Notifier) error {
+	var failures []string
+	for name, notifier := range notifiers {
+		notifyCtx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		err := notifier.Notify(notifyCtx, alert)
+		cancel()
+		if err != nil {
+			logger.Error("Failed to send alert", "channel", name, "key", alert.Key, "error", err)
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if len(failures) > 0 {
+		alertsSent.With(string(alert.Severity), "failed").Inc()
+		return fmt.Errorf("failed to send alert: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// admit applies deduplication and rate limiting, and returns the channels to notify
+func (d *Dispatcher) admit(alert *Alert) (map[string]Notifier, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	if alert.Time.IsZero() {
+		alert.Time = now
+	}
+
+	current, active := d.incidents[alert.Key]
+	if alert.Resolved {
+		if !active {
+			return nil, false
+		}
+		delete(d.incidents, alert.Key)
+		if current.notified.IsZero() {
+			return nil, false
+		}
+		// Resolutions reach the channels of the worst severity notified
+		alert.Severity = current.severity
+		alertsSent.With(string(alert.Severity), "resolved").Inc()
+		return d.notifiers(alert.Severity), true
+	}
+
+	escalated := !active || alert.Severity.rank() > current.severity.rank()
+	if !escalated && !current.notified.IsZero() && now.Sub(current.notified) < d.repeat {
+		alertsSent.With(string(alert.Severity), "deduplicated").Inc()
+		return nil, false
+	}
+	if active && !escalated {
+		alert.Severity = current.severity
+	}
+	if !d.limiter.AllowN(now, 1) {
+		// Kept unnotified so that the next occurrence is not deduplicated
+		if !active {
+			d.incidents[alert.Key] = incident{severity: alert.Severity}
+		}
+		alertsSent.With(string(alert.Severity), "rate_limited").Inc()
+		logger.Warn("Alert rate limited", "key", alert.Key, "title", alert.Title)
+		return nil, false
+	}
+	d.incidents[alert.Key] = incident{severity: alert.Severity, notified: now}
+	alertsSent.With(string(alert.Severity), "sent").Inc()
+	return d.notifiers(alert.Severity), true
+}
+
+// notifiers returns the channels routed for a severity. Callers hold d.mu.
+func (d *Dispatcher) notifiers(severity Severity) map[string]Notifier {
+	names := d.routes[severity]
+	notifiers := make(map[string]Notifier, len(names))
+	for _, name := range names {
+		if notifier, ok := d.channels[name]; ok {
+			notifiers[name] = notifier
+		} else {
+			logger.Warn("Unknown alert channel", "channel", name)
+		}
+	}
+	return notifiers
+}
+
+// LogNotifier writes alerts to the logs
+type LogNotifier struct{}
+
+// Notify logs an alert at a level matching its severity
+func (LogNotifier) Notify(ctx context.Context, alert Alert) error {
+	args := []any{"key", alert.Key, "severity", alert.Severity, "message", alert.Message, "resolved", alert.Resolved}
+	switch {
+	case alert.Resolved || alert.Severity == SeverityInfo:
+		logger.InfoContext(ctx, alert.Title, args...)
+	case alert.Severity == SeverityWarning:
+		logger.WarnContext(ctx, alert.Title, args...)
+	default:
+		logger.ErrorContext(ctx, alert.Title, args...)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDispatcher returns a dispatcher with a clock and a chat channel for warnings and
+// a pager channel for critical alerts
+func newTestDispatcher() (*Dispatcher, *Recorder, *Recorder, *time.Time) {
+	chat, pager := &Recorder{}, &Recorder{}
+	d := NewDispatcher()
+	now := time.Date(2025, 10, 3, 12, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	d.AddChannel("chat", chat)
+	d.AddChannel("pager", pager)
+	d.Route(SeverityWarning, "chat")
+	d.Route(SeverityCritical, "chat", "pager")
+	return d, chat, pager, &now
+}
+
+func TestSendRoutesBySeverity(t *testing.T) {
+	d, chat, pager, _ := newTestDispatcher()
+
+	require.NoError(t, d.Send(context.Background(), Alert{Key: "fftt", Severity: SeverityWarning, Title: "Circuit open"}))
+	require.NoError(t, d.Send(context.Background(), Alert{Key: "cache", Severity: SeverityCritical, Title: "Cache not saved"}))
+	require.NoError(t, d.Send(context.Background(), Alert{Key: "info", Severity: SeverityInfo, Title: "Unrouted"}))
+
+	assert.Len(t, chat.Alerts(), 2)
+	require.Len(t, pager.Alerts(), 1)
+	assert.Equal(t, "Cache not saved", pager.Alerts()[0].Title)
+	assert.False(t, pager.Alerts()[0].Time.IsZero())
+}
+
+func TestRepeatedAlertsAreDeduplicated(t *testing.T) {
+	d, chat, pager, now := newTestDispatcher()
+	warning := Alert{Key: "geocoding", Severity: SeverityWarning, Title: "Geocoding failing"}
+
+	d.Send(context.Background(), warning)
+	*now = now.Add(10 * time.Minute)
+	d.Send(context.Background(), warning)
+	assert.Len(t, chat.Alerts(), 1)
+
+	// Escalations are never deduplicated
+	d.Send(context.Background(), Alert{Key: "geocoding", Severity: SeverityCritical, Title: "Geocoding down"})
+	assert.Len(t, pager.Alerts(), 1)
+	d.Send(context.Background(), warning)
+	assert.Len(t, chat.Alerts(), 2)
+
+	*now = now.Add(DefaultRepeatInterval)
+	d.Send(context.Background(), warning)
+	require.Len(t, pager.Alerts(), 2)
+	assert.Equal(t, SeverityCritical, pager.Alerts()[1].Severity)
+}
+
+func TestResolveNotifiesOnlyKnownIncidents(t *testing.T) {
+	d, chat, pager, _ := newTestDispatcher()
+
+	d.Send(context.Background(), Alert{Key: "cache", Title: "Cache saved", Resolved: true})
+	assert.Empty(t, chat.Alerts())
+
+	d.Send(context.Background(), Alert{Key: "cache", Severity: SeverityCritical, Title: "Cache not saved"})
+	d.Send(context.Background(), Alert{Key: "cache", Title: "Cache saved", Resolved: true})
+	require.Len(t, pager.Alerts(), 2)
+	resolved := pager.Alerts()[1]
+	assert.True(t, resolved.Resolved)
+	assert.Equal(t, SeverityCritical, resolved.Severity)
+	assert.Equal(t, "[RESOLVED] Cache saved", resolved.Subject())
+
+	d.Send(context.Background(), Alert{Key: "cache", Title: "Cache saved", Resolved: true})
+	assert.Len(t, pager.Alerts(), 2)
+}
+
+func TestAlertsAreRateLimited(t *testing.T) {
+	d, chat, _, now := newTestDispatcher()
+	d.SetLimits(time.Hour, 2)
+
+	for _, key := range []string{"a", "b", "c"} {
+		d.Send(context.Background(), Alert{Key: key, Severity: SeverityWarning, Title: key})
+	}
+	assert.Len(t, chat.Alerts(), 2)
+
+	// The limited alert was never notified: it is not deduplicated nor resolved
+	d.Send(context.Background(), Alert{Key: "c", Title: "c", Resolved: true})
+	assert.Len(t, chat.Alerts(), 2)
+
+	*now = now.Add(30 * time.Minute)
+	d.Send(context.Background(), Alert{Key: "c", Severity: SeverityWarning, Title: "c"})
+	assert.Len(t, chat.Alerts(), 3)
+}
+
+func TestFireSendsInTheBackground(t *testing.T) {
+	d, chat, _, _ := newTestDispatcher()
+
+	d.Fire(Alert{Key: "refresh", Severity: SeverityWarning, Title: "Refresh failing"})
+	d.Wait()
+	assert.Len(t, chat.Alerts(), 1)
+}
+
+func TestSendReportsFailedChannels(t *testing.T) {
+	d := NewDispatcher()
+	d.AddChannel("broken", &Recorder{Err: errors.New("connection refused")})
+	d.Route(SeverityCritical, "broken")
+
+	assert.ErrorContains(t, d.Send(context.Background(), Alert{Severity: SeverityCritical}), "broken: connection refused")
+}
+
+func TestCloseDeliversQueuedAlerts(t *testing.T) {
+	chat := &Recorder{}
+	d := NewDispatcher()
+	d.AddChannel("chat", chat)
+	d.Route(SeverityWarning, "chat")
+
+	d.Fire(Alert{Key: "cache.write", Severity: SeverityWarning, Title: "Cache not saved"})
+	d.Close()
+	assert.Len(t, chat.Alerts(), 1)
+
+	d.Fire(Alert{Key: "fftt", Severity: SeverityWarning, Title: "FFTT is down"})
+	d.Close()
+	assert.Len(t, chat.Alerts(), 1, "alerts fired after Close are dropped")
+}
@@ -0,0 +1,58 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Discord embed colors by severity
+var discordColors = map[Severity]int{
+	SeverityInfo:     0x3b6fd4,
+	SeverityWarning:  0xf0a020,
+	SeverityCritical: 0xb00020,
+}
+
+// discordResolvedColor is the embed color of resolve notifications
+const discordResolvedColor = 0x1b7a3a
+
+// DiscordNotifier posts alerts to a Discord channel webhook
+type DiscordNotifier struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+type discordEmbed struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Color       int    `json:"color"`
+	Timestamp   string `json:"timestamp"`
+}
+
+type discordMessage struct {
+	Username string         `json:"username"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+// Notify posts an alert as an embed
+func (d DiscordNotifier) Notify(ctx context.Context, alert Alert) error {
+	color := discordColors[alert.Severity]
+	if alert.Resolved {
+		color = discordResolvedColor
+	}
+	body, err := json.Marshal(discordMessage{
+		Username: "tournois-tt",
+		Embeds: []discordEmbed{{
+			Title:       alert.Subject(),
+			Description: alert.Message,
+			Color:       color,
+			Timestamp:   alert.Time.UTC().Format(time.RFC3339),
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal Discord message: %v", err)
+	}
+	return postJSON(ctx, d.Client, d.WebhookURL, body)
+}
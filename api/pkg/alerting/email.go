@@ -0,0 +1,58 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// brevoBaseURL is the Brevo API
+const brevoBaseURL = "https://api.brevo.com/v3"
+
+// EmailNotifier sends alerts by email with the Brevo transactional email API
+type EmailNotifier struct {
+	APIKey string
+	// Sender must be a sender verified in Brevo
+	Sender string
+	To     []string
+	// BaseURL overrides the Brevo API, for tests
+	BaseURL string
+	Client  *http.Client
+}
+
+type brevoContact struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type brevoEmail struct {
+	Sender      brevoContact   `json:"sender"`
+	To          []brevoContact `json:"to"`
+	Subject     string         `json:"subject"`
+	TextContent string         `json:"textContent"`
+	Tags        []string       `json:"tags,omitempty"`
+}
+
+// Notify emails an alert to every recipient
+func (e EmailNotifier) Notify(ctx context.Context, alert Alert) error {
+	email := brevoEmail{
+		Sender:      brevoContact{Email: e.Sender, Name: "tournois-tt alerts"},
+		Subject:     "tournois-tt " + alert.Subject(),
+		TextContent: alert.Text() + "\n\n" + alert.Time.Format("02/01/2006 15:04:05 MST"),
+		Tags:        []string{"alert"},
+	}
+	for _, to := range e.To {
+		email.To = append(email.To, brevoContact{Email: to})
+	}
+	body, err := json.Marshal(email)
+	if err != nil {
+		return fmt.Errorf("failed to marshal email: %v", err)
+	}
+
+	baseURL := e.BaseURL
+	if baseURL == "" {
+		baseURL = brevoBaseURL
+	}
+	return postJSON(ctx, e.Client, baseURL+"/smtp/email", body, "api-key", e.APIKey)
+}
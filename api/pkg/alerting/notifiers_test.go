@@ -0,0 +1,90 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testAlert = Alert{
+	Key:      "refresh",
+	Severity: SeverityCritical,
+	Title:    "Tournaments stale",
+	Message:  "FFTT returned 503",
+	Time:     time.Date(2025, 10, 3, 12, 0, 0, 0, time.UTC),
+}
+
+// capture starts a server decoding the JSON body of the request it receives
+func capture(t *testing.T, status int, body any) (*httptest.Server, *http.Request) {
+	t.Helper()
+
+	var received http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = *r
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(body))
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &received
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var received Alert
+	server, _ := capture(t, http.StatusNoContent, &received)
+
+	require.NoError(t, WebhookNotifier{URL: server.URL}.Notify(context.Background(), testAlert))
+	assert.Equal(t, testAlert, received)
+
+	failing, _ := capture(t, http.StatusBadGateway, &received)
+	assert.ErrorContains(t, WebhookNotifier{URL: failing.URL}.Notify(context.Background(), testAlert), "502")
+}
+
+func TestEmailNotifier(t *testing.T) {
+	var received brevoEmail
+	server, req := capture(t, http.StatusCreated, &received)
+
+	notifier := EmailNotifier{APIKey: "brevo-key", Sender: "alerts@tournois-tt.fr",
+		To: []string{"ops@tournois-tt.fr"}, BaseURL: server.URL}
+	require.NoError(t, notifier.Notify(context.Background(), testAlert))
+
+	assert.Equal(t, "/smtp/email", req.URL.Path)
+	assert.Equal(t, "brevo-key", req.Header.Get("api-key"))
+	assert.Equal(t, "alerts@tournois-tt.fr", received.Sender.Email)
+	assert.Equal(t, []brevoContact{{Email: "ops@tournois-tt.fr"}}, received.To)
+	assert.Equal(t, "tournois-tt [CRITICAL] Tournaments stale", received.Subject)
+	assert.Contains(t, received.TextContent, "FFTT returned 503")
+}
+
+func TestDiscordNotifier(t *testing.T) {
+	var received discordMessage
+	server, _ := capture(t, http.StatusNoContent, &received)
+
+	require.NoError(t, DiscordNotifier{WebhookURL: server.URL}.Notify(context.Background(), testAlert))
+	require.Len(t, received.Embeds, 1)
+	assert.Equal(t, "[CRITICAL] Tournaments stale", received.Embeds[0].Title)
+	assert.Equal(t, discordColors[SeverityCritical], received.Embeds[0].Color)
+	assert.Equal(t, "2025-10-03T12:00:00Z", received.Embeds[0].Timestamp)
+
+	resolved := testAlert
+	resolved.Resolved = true
+	require.NoError(t, DiscordNotifier{WebhookURL: server.URL}.Notify(context.Background(), resolved))
+	assert.Equal(t, discordResolvedColor, received.Embeds[0].Color)
+}
+
+func TestTelegramNotifier(t *testing.T) {
+	var received telegramMessage
+	server, req := capture(t, http.StatusOK, &received)
+
+	notifier := TelegramNotifier{BotToken: "123:abc", ChatID: "-10042", BaseURL: server.URL}
+	require.NoError(t, notifier.Notify(context.Background(), testAlert))
+	assert.Equal(t, "/bot123:abc/sendMessage", req.URL.Path)
+	assert.Equal(t, "-10042", received.ChatID)
+	assert.Equal(t, "[CRITICAL] Tournaments stale\nFFTT returned 503", received.Text)
+}
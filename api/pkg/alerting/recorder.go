@@ -0,0 +1,29 @@
+package alerting
+
+import (
+	"context"
+	"sync"
+)
+
+// Recorder is a channel keeping the alerts it receives, for tests
+type Recorder struct {
+	mu     sync.Mutex
+	alerts []Alert
+	// Err is returned by Notify
+	Err error
+}
+
+// Notify records an alert
+func (r *Recorder) Notify(_ context.Context, alert Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, alert)
+	return r.Err
+}
+
+// Alerts returns the alerts received so far
+func (r *Recorder) Alerts() []Alert {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Alert(nil), r.alerts...)
+}
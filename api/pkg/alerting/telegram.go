@@ -0,0 +1,39 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// telegramBaseURL is the Telegram Bot API
+const telegramBaseURL = "https://api.telegram.org"
+
+// TelegramNotifier sends alerts to a Telegram chat with a bot
+type TelegramNotifier struct {
+	BotToken string
+	ChatID   string
+	// BaseURL overrides the Telegram Bot API, for tests
+	BaseURL string
+	Client  *http.Client
+}
+
+type telegramMessage struct {
+	ChatID string `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+// Notify sends an alert as a plain text message
+func (t TelegramNotifier) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(telegramMessage{ChatID: t.ChatID, Text: alert.Text()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal Telegram message: %v", err)
+	}
+
+	baseURL := t.BaseURL
+	if baseURL == "" {
+		baseURL = telegramBaseURL
+	}
+	return postJSON(ctx, t.Client, baseURL+"/bot"+t.BotToken+"/sendMessage", body)
+}
@@ -0,0 +1,375 @@
+// Package apikeys manages the API keys issued to third-party consumers. Keys are stored
+// hashed, belong to a rate tier and have their usage counted per day.
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"tournois-tt/api/pkg/logging"
+
+	"tournois-tt/api/pkg/cache"
+)
+
+var logger = logging.For("apikeys")
+
+// Tier is a rate limit and daily quota applied to keys. A zero DailyQuota is unlimited.
+type Tier struct {
+	Name              string `json:"name"`
+	RequestsPerMinute int    `json:"requestsPerMinute"`
+	Burst             int    `json:"burst"`
+	DailyQuota        int    `json:"dailyQuota"`
+}
+
+// Tiers lists the available rate tiers by name
+var Tiers = map[string]Tier{
+	"free":     {Name: "free", RequestsPerMinute: 120, Burst: 20, DailyQuota: 10000},
+	"partner":  {Name: "partner", RequestsPerMinute: 600, Burst: 100, DailyQuota: 200000},
+	"internal": {Name: "internal", RequestsPerMinute: 3000, Burst: 500},
+}
+
+// DefaultTier is the tier of keys created without one
+const DefaultTier = "free"
+
+// tokenPrefix starts every API key so that leaked keys are easy to spot
+const tokenPrefix = "tt_"
+
+// reloadInterval throttles checks for keys changed on disk by the admin CLI
+const reloadInterval = 5 * time.Second
+
+// ErrInvalidKey is returned for unknown, malformed or revoked keys
+var ErrInvalidKey = errors.New("invalid API key")
+
+// Key is an issued API key. Only the hash of its secret is stored.
+type Key struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Tier       string     `json:"tier"`
+	SecretHash string     `json:"secretHash"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// Usage counts the requests made with a key. Daily counters reset at midnight UTC.
+type Usage struct {
+	Total      int64      `json:"total"`
+	Rejected   int64      `json:"rejected"`
+	Day        string     `json:"day"`
+	Today      int        `json:"today"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+// Store holds the keys, written by the admin CLI, and their usage, written by the server
+type Store struct {
+	mu         sync.Mutex
+	keysPath   string
+	usagePath  string
+	now        func() time.Time
+	keys       map[string]Key
+	keysMod    time.Time
+	lastReload time.Time
+	usage      map[string]*Usage
+	dirty      bool
+}
+
+// NewStore loads the keys and usage stored in dir
+func NewStore(dir string) (*Store, error) {
+	s := &Store{
+		keysPath:  filepath.Join(dir, "apikeys.json"),
+		usagePath: filepath.Join(dir, "apikeys_usage.json"),
+		now:       time.Now,
+		keys:      make(map[string]Key),
+		usage:     make(map[string]*Usage),
+	}
+
+	if err := s.loadKeys(); err != nil {
+		return nil, err
+	}
+	if err := readJSON(s.usagePath, &s.usage); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// TierInfo returns the tier of a key, falling back to the default tier
+func (k Key) TierInfo() Tier {
+	if tier, ok := Tiers[k.Tier]; ok {
+		return tier
+	}
+	return Tiers[DefaultTier]
+}
+
+// Create issues a new key and returns it with its token, which is not stored and
+// cannot be retrieved later
+func (s *Store) Create(name, tier string) (Key, string, error) {
+	if tier == "" {
+		tier = DefaultTier
+	}
+	if _, ok := Tiers[tier]; !ok {
+		return Key{}, "", fmt.Errorf("unknown tier %q", tier)
+	}
+
+	id, err := randomHex(4)
+	if err != nil {
+		return Key{}, "", err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return Key{}, "", err
+	}
+
+	key := Key{
+		ID:         id,
+		Name:       name,
+		Tier:       tier,
+		SecretHash: hashSecret(secret),
+		CreatedAt:  s.now().UTC(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadKeys(); err != nil {
+		return Key{}, "", err
+	}
+	s.keys[id] = key
+	if err := writeJSON(s.keysPath, s.sortedKeys()); err != nil {
+		return Key{}, "", err
+	}
+
+	return key, tokenPrefix + id + "_" + secret, nil
+}
+
+// SetTier changes the tier of a key
+func (s *Store) SetTier(id, tier string) error {
+	if _, ok := Tiers[tier]; !ok {
+		return fmt.Errorf("unknown tier %q", tier)
+	}
+	return s.update(id, func(key *Key) { key.Tier = tier })
+}
+
+// Revoke disables a key
+func (s *Store) Revoke(id string) error {
+	return s.update(id, func(key *Key) {
+		if key.RevokedAt == nil {
+			now := s.now().UTC()
+			key.RevokedAt = &now
+		}
+	})
+}
+
+func (s *Store) update(id string, change func(*Key)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadKeys(); err != nil {
+		return err
+	}
+	key, ok := s.keys[id]
+	if !ok {
+		return fmt.Errorf("unknown key %q", id)
+	}
+	change(&key)
+	s.keys[id] = key
+	return writeJSON(s.keysPath, s.sortedKeys())
+}
+
+// List returns all keys sorted by creation date
+func (s *Store) List() []Key {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedKeys()
+}
+
+// Authenticate returns the active key matching a token
+func (s *Store) Authenticate(token string) (Key, error) {
+	rest, ok := strings.CutPrefix(token, tokenPrefix)
+	if !ok {
+		return Key{}, ErrInvalidKey
+	}
+	id, secret, ok := strings.Cut(rest, "_")
+	if !ok {
+		return Key{}, ErrInvalidKey
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now := s.now(); now.Sub(s.lastReload) >= reloadInterval {
+		s.lastReload = now
+		if err := s.loadKeys(); err != nil {
+			logger.Error("Failed to reload API keys", "error", err)
+		}
+	}
+
+	key, ok := s.keys[id]
+	if !ok || key.RevokedAt != nil {
+		return Key{}, ErrInvalidKey
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(key.SecretHash)) != 1 {
+		return Key{}, ErrInvalidKey
+	}
+	return key, nil
+}
+
+// Consume counts a request made with a key and reports whether it is within the daily quota.
+// Requests over quota are counted as rejected.
+func (s *Store) Consume(key Key) (Usage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC()
+	usage := s.usageOf(key.ID, now)
+	s.dirty = true
+
+	quota := key.TierInfo().DailyQuota
+	if quota > 0 && usage.Today >= quota {
+		usage.Rejected++
+		return *usage, false
+	}
+
+	usage.Total++
+	usage.Today++
+	usage.LastUsedAt = &now
+	return *usage, true
+}
+
+// Usage returns the usage of a key
+func (s *Store) Usage(id string) Usage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.usageOf(id, s.now().UTC())
+}
+
+// usageOf returns the usage counters of a key, resetting daily ones. Callers hold s.mu.
+func (s *Store) usageOf(id string, now time.Time) *Usage {
+	usage, ok := s.usage[id]
+	if !ok {
+		usage = &Usage{}
+		s.usage[id] = usage
+	}
+	if day := now.Format("2006-01-02"); usage.Day != day {
+		usage.Day = day
+		usage.Today = 0
+	}
+	return usage
+}
+
+// SaveUsage writes the usage counters to disk if they changed
+func (s *Store) SaveUsage() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+	if err := writeJSON(s.usagePath, s.usage); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// FlushUsage saves the usage counters every interval until ctx is done, then a last time
+func (s *Store) FlushUsage(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.SaveUsage(); err != nil {
+				logger.Error("Failed to save API key usage", "error", err)
+			}
+			return
+		case <-ticker.C:
+			if err := s.SaveUsage(); err != nil {
+				logger.Error("Failed to save API key usage", "error", err)
+			}
+		}
+	}
+}
+
+// loadKeys reads the keys file if it changed since the last load. Callers hold s.mu.
+func (s *Store) loadKeys() error {
+	info, err := os.Stat(s.keysPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read API keys: %v", err)
+	}
+	if info.ModTime().Equal(s.keysMod) {
+		return nil
+	}
+
+	var keys []Key
+	if err := readJSON(s.keysPath, &keys); err != nil {
+		return err
+	}
+
+	s.keys = make(map[string]Key, len(keys))
+	for _, key := range keys {
+		s.keys[key.ID] = key
+	}
+	s.keysMod = info.ModTime()
+	return nil
+}
+
+// sortedKeys returns the keys sorted by creation date. Callers hold s.mu.
+func (s *Store) sortedKeys() []Key {
+	keys := make([]Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate key: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", filepath.Base(path), err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// writeJSON writes a file atomically, readable by its owner only
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %v", filepath.Base(path), err)
+	}
+	return cache.WriteFileAtomic(path, data, 0600)
+}
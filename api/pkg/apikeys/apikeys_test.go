@@ -0,0 +1,106 @@
+package apikeys
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStore returns a store in a temporary directory with a controllable clock
+func newTestStore(t *testing.T) (*Store, *time.Time) {
+	t.Helper()
+
+	s, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	now := time.Date(2025, 10, 1, 23, 59, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func TestCreateAuthenticateRevoke(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	key, token, err := s.Create("Ligue de Bretagne", "partner")
+	require.NoError(t, err)
+	secret, ok := strings.CutPrefix(token, "tt_"+key.ID+"_")
+	require.True(t, ok)
+	assert.Equal(t, hashSecret(secret), key.SecretHash)
+
+	authenticated, err := s.Authenticate(token)
+	require.NoError(t, err)
+	assert.Equal(t, "partner", authenticated.TierInfo().Name)
+
+	for _, invalid := range []string{"", "nope", "tt_" + key.ID, "tt_" + key.ID + "_wrong", "tt_unknown_" + token} {
+		_, err := s.Authenticate(invalid)
+		assert.ErrorIs(t, err, ErrInvalidKey, invalid)
+	}
+
+	_, _, err = s.Create("Scraper", "unlimited")
+	assert.Error(t, err)
+
+	require.NoError(t, s.Revoke(key.ID))
+	_, err = s.Authenticate(token)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestKeysCreatedByAnotherProcessAreReloaded(t *testing.T) {
+	server, now := newTestStore(t)
+
+	cli, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+	cli.keysPath = server.keysPath
+
+	key, token, err := cli.Create("Club de Rennes", "")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTier, key.Tier)
+
+	*now = now.Add(reloadInterval)
+	_, err = server.Authenticate(token)
+	require.NoError(t, err)
+
+	// Revocations are picked up without restarting the server
+	require.NoError(t, cli.Revoke(key.ID))
+	*now = now.Add(reloadInterval)
+	_, err = server.Authenticate(token)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestDailyQuota(t *testing.T) {
+	original := Tiers["free"]
+	t.Cleanup(func() { Tiers["free"] = original })
+	tier := original
+	tier.DailyQuota = 2
+	Tiers["free"] = tier
+
+	s, now := newTestStore(t)
+	key, _, err := s.Create("Quota", "free")
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, ok := s.Consume(key)
+		assert.True(t, ok)
+	}
+	usage, ok := s.Consume(key)
+	assert.False(t, ok)
+	assert.Equal(t, 2, usage.Today)
+	assert.EqualValues(t, 1, usage.Rejected)
+
+	// Quotas reset at midnight UTC
+	*now = now.Add(time.Minute)
+	usage, ok = s.Consume(key)
+	assert.True(t, ok)
+	assert.Equal(t, "2025-10-02", usage.Day)
+	assert.Equal(t, 1, usage.Today)
+	assert.EqualValues(t, 3, usage.Total)
+
+	require.NoError(t, s.SaveUsage())
+	reloaded, err := NewStore(filepath.Dir(s.usagePath))
+	require.NoError(t, err)
+	reloaded.now = s.now
+	assert.Equal(t, usage, reloaded.Usage(key.ID))
+}
@@ -0,0 +1,172 @@
+// Package audit records the actions of administrators in an append-only log, rotated when
+// it grows past MaxSize
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"tournois-tt/api/pkg/logging"
+)
+
+var logger = logging.For("audit")
+
+// Outcomes of audited actions
+const (
+	OutcomeOK      = "ok"
+	OutcomeError   = "error"
+	OutcomeStarted = "started"
+	OutcomeDenied  = "denied"
+)
+
+// MaxSize is the size past which the log is rotated. The previous log is kept, so that the
+// logs never use more than twice this size.
+const MaxSize = 1 << 20
+
+// DeniedInterval is the minimum delay between two recorded denied requests. The requests
+// denied in between are counted in the details of the next one.
+const DeniedInterval = time.Minute
+
+// Entry is an audited action
+type Entry struct {
+	Time      time.Time      `json:"time"`
+	Actor     string         `json:"actor"`
+	Action    string         `json:"action"`
+	Target    string         `json:"target,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+	Outcome   string         `json:"outcome"`
+	Error     string         `json:"error,omitempty"`
+	RequestID string         `json:"requestId,omitempty"`
+}
+
+// Log appends entries to a JSON lines file
+type Log struct {
+	mu      sync.Mutex
+	path    string
+	now     func() time.Time
+	maxSize int64
+
+	lastDenied time.Time
+	suppressed int
+}
+
+// NewLog returns a log appending to path
+func NewLog(path string) *Log {
+	return &Log{path: path, now: time.Now, maxSize: MaxSize}
+}
+
+// Record appends an entry, timestamped now. Denied requests are recorded at most once per
+// DeniedInterval.
+func (l *Log) Record(entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry.Time = l.now().UTC()
+	if entry.Outcome == OutcomeDenied {
+		if entry.Time.Sub(l.lastDenied) < DeniedInterval {
+			l.suppressed++
+			return nil
+		}
+		if l.suppressed > 0 {
+			details := map[string]any{"suppressed": l.suppressed}
+			for key, value := range entry.Details {
+				details[key] = value
+			}
+			entry.Details = details
+		}
+		l.lastDenied, l.suppressed = entry.Time, 0
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %v", err)
+	}
+
+	logger.Info("Admin action", "actor", entry.Actor, "action", entry.Action, "target", entry.Target,
+		"outcome", entry.Outcome, "error", entry.Error, "request_id", entry.RequestID)
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %v", err)
+	}
+	if info, err := os.Stat(l.path); err == nil && info.Size()+int64(len(data)) > l.maxSize {
+		if err := os.Rename(l.path, l.rotatedPath()); err != nil {
+			return fmt.Errorf("failed to rotate audit log: %v", err)
+		}
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %v", err)
+	}
+	return nil
+}
+
+// rotatedPath is where the previous log is kept
+func (l *Log) rotatedPath() string {
+	return l.path + ".1"
+}
+
+// Recent returns up to limit entries, most recent first. The previous log is only read
+// when the current one has fewer entries.
+func (l *Log) Recent(limit int) ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries, err := readEntries(l.path)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || len(entries) < limit {
+		previous, err := readEntries(l.rotatedPath())
+		if err != nil {
+			return nil, err
+		}
+		entries = append(previous, entries...)
+	}
+
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	recent := make([]Entry, len(entries))
+	for i, entry := range entries {
+		recent[len(entries)-1-i] = entry
+	}
+	return recent, nil
+}
+
+// readEntries reads the entries of a log file, none when it doesn't exist
+func readEntries(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %v", err)
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			logger.Warn("Skipping invalid audit log line", "error", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %v", err)
+	}
+	return entries, nil
+}
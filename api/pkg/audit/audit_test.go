@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndRecent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l := NewLog(path)
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { now = now.Add(time.Second); return now }
+
+	entries, err := l.Recent(10)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.NoError(t, l.Record(Entry{Actor: "token", Action: "tournament.purge", Target: "3340", Outcome: OutcomeOK}))
+	require.NoError(t, l.Record(Entry{Actor: "token", Action: "refresh", Details: map[string]any{"from": "2025-09-01"}, Outcome: OutcomeStarted}))
+	require.NoError(t, l.Record(Entry{Actor: "CN=ops", Action: "tournament.restore", Target: "3340", Outcome: OutcomeError, Error: "tournament not found"}))
+
+	entries, err = l.Recent(2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "tournament.restore", entries[0].Action)
+	assert.Equal(t, "CN=ops", entries[0].Actor)
+	assert.Equal(t, "refresh", entries[1].Action)
+	assert.Equal(t, "2025-09-01", entries[1].Details["from"])
+	assert.True(t, entries[1].Time.Before(entries[0].Time))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestRecordRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l := NewLog(path)
+	l.maxSize = 400
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, l.Record(Entry{Actor: "token", Action: "tournament.purge", Target: fmt.Sprint(i), Outcome: OutcomeOK}))
+	}
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, info.Size(), l.maxSize)
+	assert.FileExists(t, path+".1")
+
+	entries, err := l.Recent(4)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	assert.Equal(t, "9", entries[0].Target)
+	assert.Equal(t, "6", entries[3].Target, "older entries are read from the previous log")
+}
+
+func TestRecordThrottlesDenied(t *testing.T) {
+	l := NewLog(filepath.Join(t.TempDir(), "audit.log"))
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	denied := Entry{Actor: "anonymous", Action: "auth", Target: "GET /admin/stats", Outcome: OutcomeDenied}
+	for i := 0; i < 5; i++ {
+		require.NoError(t, l.Record(denied))
+		now = now.Add(time.Second)
+	}
+	require.NoError(t, l.Record(Entry{Actor: "token", Action: "refresh", Outcome: OutcomeStarted}))
+	now = now.Add(DeniedInterval)
+	require.NoError(t, l.Record(denied))
+
+	entries, err := l.Recent(0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, OutcomeDenied, entries[0].Outcome)
+	assert.EqualValues(t, 4, entries[0].Details["suppressed"])
+	assert.Equal(t, "refresh", entries[1].Action, "other actions are not throttled")
+	assert.Nil(t, entries[2].Details)
+}
@@ -2,28 +2,29 @@ package brevo
 
 import (
 	"context"
-
-	brevo "github.com/getbrevo/brevo-go/lib"
+	"fmt"
+	"io"
+	"net/http"
 )
 
-func NewBrevoClient(apiKey string) *brevo.APIClient {
-	cfg := brevo.NewConfiguration()
-	cfg.AddDefaultHeader("api-key", apiKey)
-
-	br := brevo.NewAPIClient(cfg)
-
-	return br
-}
+// SendCampaign sends an email campaign now
+func (c *Client) SendCampaign(ctx context.Context, campaignID int) error {
+	url := fmt.Sprintf("%s/emailCampaigns/%d/sendNow", c.BaseURL, campaignID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("api-key", c.APIKey)
 
-func SendCampaign(b *brevo.APIClient, campaignID int) error {
-	resp, err := b.EmailCampaignsApi.SendEmailCampaignNow(context.Background(), int64(campaignID))
+	resp, err := c.HTTP.Do(req)
 	if err != nil {
-		if resp != nil {
-			// Close the body to prevent resource leaks
-			defer resp.Body.Close()
-		}
 		return err
 	}
+	defer resp.Body.Close()
 
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return &APIError{StatusCode: resp.StatusCode, Body: body}
+	}
 	return nil
 }
@@ -0,0 +1,76 @@
+package brevo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// BaseURL is the Brevo API endpoint
+const BaseURL = "https://api.brevo.com/v3"
+
+// Client calls the Brevo API
+type Client struct {
+	APIKey  string
+	BaseURL string
+	HTTP    *http.Client
+}
+
+// NewClient returns a client authenticated with apiKey, sending its requests with
+// httpClient
+func NewClient(apiKey string, httpClient *http.Client) *Client {
+	return &Client{APIKey: apiKey, BaseURL: BaseURL, HTTP: httpClient}
+}
+
+// Configured reports whether the API key is set
+func (c *Client) Configured() bool {
+	return c.APIKey != ""
+}
+
+// APIError is returned when Brevo rejects a request
+type APIError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Brevo API returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// AddContact creates a contact in a list, or updates it if it exists. It returns the
+// status of the response: 201 when the contact was created, 204 when it was updated.
+func (c *Client) AddContact(ctx context.Context, email string, listID int, attributes map[string]any) (int, error) {
+	payload := map[string]any{
+		"email":         email,
+		"listIds":       []int{listID},
+		"updateEnabled": true,
+		"attributes":    attributes,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/contacts", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("api-key", c.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// The body is empty on 204
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, &APIError{StatusCode: resp.StatusCode, Body: respBody}
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"tournois-tt/api/pkg/alerting"
+)
+
+// SetAlerts sets the dispatcher alerting while the store files cannot be written, none by
+// default
+func (s *Store) SetAlerts(alerts *alerting.Dispatcher) {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	s.alerts = alerts
+}
+
+// writeFile replaces a file of the store with data, alerting while writes fail
+func (s *Store) writeFile(filePath string, data []byte) error {
+	return s.write(filePath, func() error { return WriteFileAtomic(filePath, data, 0644) })
+}
+
+// write writes a file of the store with save, one file at a time, and reports the outcome
+func (s *Store) write(filePath string, save func() error) error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	err := save()
+	s.reportWrite(filePath, err)
+	return err
+}
+
+// reportWrite alerts when a store file cannot be written, and when it can again. Callers
+// hold saveMu.
+func (s *Store) reportWrite(filePath string, err error) {
+	name := filepath.Base(filePath)
+	key := "cache.write." + name
+	if err != nil {
+		s.unsaved[name] = true
+		s.alerts.Fire(alerting.Alert{
+			Key:      key,
+			Severity: alerting.SeverityCritical,
+			Title:    "Cache file " + name + " not saved",
+			Message:  fmt.Sprintf("Changes are kept in memory only and lost on restart: %v", err),
+		})
+		return
+	}
+	if s.unsaved[name] {
+		delete(s.unsaved, name)
+		s.alerts.Resolve(key, "Cache file "+name+" saved", "")
+	}
+}
@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tournois-tt/api/pkg/alerting"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailedWritesAlertUntilSaved(t *testing.T) {
+	recorder := &alerting.Recorder{}
+	alerts := alerting.NewDispatcher()
+	t.Cleanup(alerts.Close)
+	alerts.AddChannel("test", recorder)
+	alerts.Route(alerting.SeverityCritical, "test")
+
+	dir := t.TempDir()
+	store := NewStore(dir)
+	store.SetAlerts(alerts)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file"), nil, 0644))
+	assert.Error(t, store.writeFile(filepath.Join(dir, "file", "data.json"), []byte("[]")))
+	require.NoError(t, store.writeFile(filepath.Join(dir, "data.json"), []byte("[]")))
+	alerts.Wait()
+
+	require.Len(t, recorder.Alerts(), 2)
+	assert.Equal(t, "cache.write.data.json", recorder.Alerts()[0].Key)
+	assert.False(t, recorder.Alerts()[0].Resolved)
+	assert.True(t, recorder.Alerts()[1].Resolved)
+}
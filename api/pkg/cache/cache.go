@@ -3,7 +3,6 @@ package cache
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -12,8 +11,12 @@ import (
 	"time"
 
 	igimage "tournois-tt/api/pkg/image"
+	"tournois-tt/api/pkg/logging"
+	"tournois-tt/api/pkg/utils"
 )
 
+var logger = logging.For("cache")
+
 // GenericCache is a thread-safe in-memory cache with JSON persistence capabilities
 type GenericCache[T any] struct {
 	sync.RWMutex
@@ -194,12 +197,7 @@ func SaveToJSON[T any](cache *GenericCache[T], filePath string) error {
 			return fmt.Errorf("failed to marshal cache items: %v", err)
 		}
 
-		// Write to file
-		if err := os.WriteFile(filePath, data, 0644); err != nil {
-			return fmt.Errorf("failed to write cache file: %v", err)
-		}
-
-		return nil
+		return WriteFileAtomic(filePath, data, 0644)
 	}
 
 	// For small datasets, just marshal directly
@@ -208,115 +206,41 @@ func SaveToJSON[T any](cache *GenericCache[T], filePath string) error {
 		return fmt.Errorf("failed to marshal cache items: %v", err)
 	}
 
-	// Write to file
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write cache file: %v", err)
-	}
-
-	return nil
+	return WriteFileAtomic(filePath, data, 0644)
 }
 
-// DefaultTournamentCache is the global instance of the tournament cache
-var DefaultTournamentCache *GenericCache[TournamentCache]
-
-// DefaultGeocodeCache is the global instance of the geocode cache
-var DefaultGeocodeCache *GenericCache[GeocodeResult]
-
-// CacheFilePath is the path to the cache file
-var CacheFilePath string
-
-// GeocodeCache filepath
-var GeocodeCacheFilePath string
-
-// InitCache initializes the unified cache
-func InitCache() error {
-	// Set up the cache file path - match the existing structure where data.json is in api/cache
-	execDir, err := os.Getwd()
-	var cacheDir string
-	if err != nil {
-		// Fallback to relative path if we can't get working directory
-		cacheDir = filepath.Join("api", "cache")
-	} else {
-		// Find the api directory in the path
-		if idx := strings.LastIndex(execDir, "api"); idx != -1 {
-			// If we're already in the api directory or a subdirectory
-			cacheDir = filepath.Join(execDir[:idx+3], "cache") // 3 = len("api")
-		} else {
-			// If we're in the root directory
-			cacheDir = filepath.Join(execDir, "api", "cache")
-		}
+// WriteFileAtomic replaces a file through a temporary file, so that an interrupted write
+// never leaves it truncated. The directory of the file is created if needed.
+func WriteFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+	name := filepath.Base(filePath)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %v", name, err)
 	}
-
-	CacheFilePath = filepath.Join(cacheDir, "data.json")
-	GeocodeCacheFilePath = filepath.Join(cacheDir, "geocode.json")
-
-	// Load cache from JSON file
-	DefaultTournamentCache, err = LoadFromJSON(CacheFilePath, func(tournament TournamentCache) string {
-		return GenerateTournamentCacheKey(tournament)
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to load tournament cache: %v", err)
+	tmp := filePath + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %v", name, err)
 	}
-
-	// Initialize geocode cache
-	DefaultGeocodeCache = NewGenericCache[GeocodeResult]()
-
-	return nil
-}
-
-// EnsureCacheInitialized makes sure cache is initialized
-func EnsureCacheInitialized() error {
-	if DefaultTournamentCache == nil {
-		return InitCache()
+	if err := os.Rename(tmp, filePath); err != nil {
+		return fmt.Errorf("failed to write %s: %v", name, err)
 	}
 	return nil
 }
 
-// SaveTournamentsToCache saves tournament data to a JSON file
-func SaveTournamentsToCache(tournaments []TournamentCache) error {
-	// Ensure cache is initialized
-	if err := EnsureCacheInitialized(); err != nil {
-		return err
-	}
-
-	// Track new tournaments for Instagram DM notifications
-	var newTournaments []TournamentCache
-
-	// Add tournaments to the in-memory cache
-	for _, tournament := range tournaments {
-		key := GenerateTournamentCacheKey(tournament)
-
-		// Check if tournament already exists in cache
-		_, exists := DefaultTournamentCache.Get(key)
-		if !exists {
-			newTournaments = append(newTournaments, tournament)
-			log.Printf("New tournament detected: %s (ID: %d)", tournament.Name, tournament.ID)
-		}
-
-		DefaultTournamentCache.Set(key, tournament)
-	}
-
-	// Save to JSON file
-	if err := SaveToJSON(DefaultTournamentCache, CacheFilePath); err != nil {
-		return err
+// Dir returns the api/cache directory holding the cache files, found from the working
+// directory
+func Dir() string {
+	execDir, err := os.Getwd()
+	if err != nil {
+		// Fallback to relative path if we can't get working directory
+		return filepath.Join("api", "cache")
 	}
-
-	// Update sitemap automatically after saving tournaments
-	go updateSitemap()
-
-	return nil
-}
-
-// LoadTournaments loads existing tournament data from JSON file
-func LoadTournaments() (map[string]TournamentCache, error) {
-	// Ensure cache is initialized
-	if err := EnsureCacheInitialized(); err != nil {
-		return nil, err
+	// Find the api directory in the path
+	if idx := strings.LastIndex(execDir, "api"); idx != -1 {
+		// If we're already in the api directory or a subdirectory
+		return filepath.Join(execDir[:idx+3], "cache") // 3 = len("api")
 	}
-
-	// Return a copy of all items in the cache
-	return DefaultTournamentCache.GetAll(), nil
+	// If we're in the root directory
+	return filepath.Join(execDir, "api", "cache")
 }
 
 // GenerateTournamentCacheKey creates a unique key for a tournament
@@ -324,129 +248,12 @@ func GenerateTournamentCacheKey(tournament TournamentCache) string {
 	return fmt.Sprintf("%d", tournament.ID)
 }
 
-// GetCachedTournament retrieves a tournament from the cache
-func GetCachedTournament(id int) (TournamentCache, bool) {
-	// Ensure cache is initialized
-	if err := EnsureCacheInitialized(); err != nil {
-		return TournamentCache{}, false
-	}
-
-	key := fmt.Sprintf("%d", id)
-	return DefaultTournamentCache.Get(key)
-}
-
-// SetCachedTournament stores a tournament in the cache
-func SetCachedTournament(tournament TournamentCache) {
-	// Ensure cache is initialized - ignore error as we're just setting
-	if DefaultTournamentCache == nil {
-		if err := InitCache(); err != nil {
-			return
-		}
-	}
-
-	key := GenerateTournamentCacheKey(tournament)
-	DefaultTournamentCache.Set(key, tournament)
-}
-
-// GenerateAddressCacheKey creates a unique key for an address
-func GenerateAddressCacheKey(addr Address) string {
-	return fmt.Sprintf("%s|%s|%s",
-		strings.TrimSpace(addr.StreetAddress),
-		strings.TrimSpace(addr.PostalCode),
-		strings.TrimSpace(addr.AddressLocality))
-}
-
-// GenerateGeocodeCacheKey creates a unique key for an address
-func GenerateGeocodeCacheKey(addr Address) string {
-	return fmt.Sprintf("%s|%s|%s",
-		strings.TrimSpace(addr.StreetAddress),
-		strings.TrimSpace(addr.PostalCode),
-		strings.TrimSpace(addr.AddressLocality))
-}
-
-// SaveGeocodeResultsToCache saves geocoding results to a JSON file
-func SaveGeocodeResultsToCache(results []GeocodeResult) error {
-	// Ensure cache is initialized
-	if err := EnsureCacheInitialized(); err != nil {
-		return err
-	}
-
-	// Add results to the in-memory cache
-	for _, result := range results {
-		key := GenerateGeocodeCacheKey(result.Address)
-		DefaultGeocodeCache.Set(key, result)
-	}
-
-	// Save to JSON file
-	return SaveToJSON(DefaultGeocodeCache, GeocodeCacheFilePath)
-}
-
-// LoadGeocodeResultsFromCache loads existing geocoding results from JSON file
-func LoadGeocodeResultsFromCache() (map[string]GeocodeResult, error) {
-	// Ensure cache is initialized
-	if err := EnsureCacheInitialized(); err != nil {
-		return nil, err
-	}
-
-	// Return a copy of all items in the cache
-	return DefaultGeocodeCache.GetAll(), nil
-}
-
-// GetCachedGeocodeResult retrieves a geocoding result from the cache
-func GetCachedGeocodeResult(addr Address) (GeocodeResult, bool) {
-	// Get cached tournaments
-	tournaments, err := LoadTournaments()
-	if err != nil {
-		return GeocodeResult{}, false
-	}
-
-	// Search for matching address in cached tournaments
-	key := GenerateAddressCacheKey(addr)
-
-	// Look for a tournament with matching address
-	for _, tourney := range tournaments {
-		if GenerateAddressCacheKey(tourney.Address) == key {
-			// Found a match - extract geocode data
-			result := GeocodeResult{
-				Address: Address{
-					StreetAddress:             tourney.Address.StreetAddress,
-					PostalCode:                tourney.Address.PostalCode,
-					AddressLocality:           tourney.Address.AddressLocality,
-					DisambiguatingDescription: tourney.Address.DisambiguatingDescription,
-					Latitude:                  tourney.Address.Latitude,
-					Longitude:                 tourney.Address.Longitude,
-					Failed:                    tourney.Address.Failed,
-				},
-				Latitude:  tourney.Address.Latitude,
-				Longitude: tourney.Address.Longitude,
-				Failed:    tourney.Address.Failed,
-				Timestamp: tourney.Timestamp,
-			}
-			return result, true
-		}
-	}
-
-	return GeocodeResult{}, false
-}
-
-// SetCachedGeocodeResult stores a geocoding result in the cache
-func SetCachedGeocodeResult(result GeocodeResult) {
-	// We won't implement this separately anymore.
-	// Geocode results will be stored as part of tournament data when saving tournaments.
-	// This is now just a stub for backward compatibility.
-}
-
-// Legacy function for backward compatibility
-func LoadGeocodeCache() (map[string]GeocodeResult, error) {
-	return LoadGeocodeResultsFromCache()
-}
-
-// updateSitemap updates the sitemap by calling npm scripts directly
-func updateSitemap() {
+// UpdateSitemap updates the sitemap and the RSS feed by calling npm scripts directly
+func UpdateSitemap() {
 	// Get the project root directory
 	execDir, err := os.Getwd()
 	if err != nil {
-		log.Printf("Warning: Could not get working directory for sitemap update: %v", err)
+		logger.Warn("Could not get working directory for sitemap update", "error", err)
 		return
 	}
 
@@ -482,24 +289,24 @@ func updateSitemap() {
 		// We're in Docker
 		frontendDir = foundDockerPath
 		isDockerEnv = true
-		log.Printf("Detected Docker environment, using frontend directory: %s", frontendDir)
+		logger.Debug("Detected Docker environment", "frontend_dir", frontendDir)
 
 		// Only generate sitemap/RSS in production Docker (single container)
 		// In development Docker, containers are separate so skip generation
 		if frontendDir == "/tournois-tt/frontend" {
-			log.Printf("Development Docker detected - skipping sitemap/RSS generation (separate containers)")
+			logger.Debug("Development Docker detected, skipping sitemap and RSS generation")
 			return // Exit early, no generation in dev Docker
 		}
 	} else {
 		// We're in local development, frontend files are in frontend directory
 		frontendDir = filepath.Join(projectRoot, "frontend")
 		isDockerEnv = false
-		log.Printf("Detected local development environment, using frontend directory: %s", frontendDir)
+		logger.Debug("Detected local development environment", "frontend_dir", frontendDir)
 	}
 
 	// Check if frontend directory exists
 	if _, err := os.Stat(frontendDir); os.IsNotExist(err) {
-		log.Printf("Warning: Frontend directory not found: %s", frontendDir)
+		logger.Warn("Frontend directory not found", "frontend_dir", frontendDir)
 		return
 	}
 
@@ -526,9 +333,9 @@ func updateSitemap() {
 			cmd.Env = append(os.Environ(), "OUTPUT_DIR="+outputDir)
 
 			if err := cmd.Run(); err != nil {
-				log.Printf("Warning: Failed to run npm run %s: %v", cmdName, err)
+				logger.Warn("Failed to run npm script", "script", cmdName, "error", err)
 			} else {
-				log.Printf("Successfully ran npm run %s", cmdName)
+				logger.Info("Ran npm script", "script", cmdName)
 			}
 		}
 	} else {
@@ -543,16 +350,16 @@ func updateSitemap() {
 			cmd.Dir = frontendDir
 
 			if err := cmd.Run(); err != nil {
-				log.Printf("Warning: Failed to run npm run %s: %v", cmdName, err)
+				logger.Warn("Failed to run npm script", "script", cmdName, "error", err)
 			} else {
-				log.Printf("Successfully ran npm run %s", cmdName)
+				logger.Info("Ran npm script", "script", cmdName)
 			}
 		}
 	}
 }
 
-// convertTournamentToImageData converts a TournamentCache to TournamentImage for image generation
-func convertTournamentToImageData(tournament TournamentCache) igimage.TournamentImage {
+// ImageData converts a TournamentCache to TournamentImage for image generation
+func ImageData(tournament TournamentCache) igimage.TournamentImage {
 	// Format address
 	address := formatTournamentAddress(tournament.Address)
 
@@ -627,7 +434,6 @@ func isTournamentInPast(endDate string) bool {
 		}
 	}
 
-	now := time.Now()
 	// Consider a tournament past if it ended before today (not including today)
-	return end.Before(now.Truncate(24 * time.Hour))
+	return end.Before(utils.Today(time.Now()))
 }
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"reflect"
+	"time"
+)
+
+// ChangeKind describes how a tournament changed when saving the cache
+type ChangeKind string
+
+// Change kinds
+const (
+	ChangeCreated   ChangeKind = "created"
+	ChangeUpdated   ChangeKind = "updated"
+	ChangeCancelled ChangeKind = "cancelled"
+)
+
+// TournamentChange is a tournament created, updated or cancelled by Store.Save, or changed
+// by Purge, Restore, SetCoordinates and Update, such as when geocoding again. Before is nil
+// for created tournaments.
+type TournamentChange struct {
+	Kind   ChangeKind
+	Before *TournamentCache
+	After  TournamentCache
+}
+
+// notifyChanges passes changes to the registered listeners
+func (s *Store) notifyChanges(changes []TournamentChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	s.listenersMu.RLock()
+	defer s.listenersMu.RUnlock()
+	for _, listener := range s.listeners {
+		listener(changes)
+	}
+}
+
+// apply stores fetched tournaments in memory and returns the changes.
+// Cached tournaments starting within the date range of the fetched ones but missing from
+// them are marked as cancelled.
+func (s *Store) apply(tournaments []TournamentCache) []TournamentChange {
+	var changes []TournamentChange
+	saved := make(map[string]bool, len(tournaments))
+	var windowStart, windowEnd string
+
+	// Add tournaments to the in-memory cache
+	for _, tournament := range tournaments {
+		if s.isPurged(tournament.ID) {
+			continue
+		}
+		tournament = enrichTournament(tournament)
+		key := GenerateTournamentCacheKey(tournament)
+		saved[key] = true
+
+		if windowStart == "" || tournament.StartDate < windowStart {
+			windowStart = tournament.StartDate
+		}
+		if tournament.StartDate > windowEnd {
+			windowEnd = tournament.StartDate
+		}
+
+		// Check if tournament already exists in cache
+		previous, exists := s.tournaments.Get(key)
+		if !exists {
+			changes = append(changes, TournamentChange{Kind: ChangeCreated, After: tournament})
+			logger.Info("New tournament detected", "tournament_id", tournament.ID, "name", tournament.Name)
+		} else if tournamentChanged(previous, tournament) {
+			changes = append(changes, TournamentChange{Kind: ChangeUpdated, Before: &previous, After: tournament})
+		}
+
+		s.tournaments.Set(key, tournament)
+	}
+
+	// Tournaments that disappeared from the fetched date range have been cancelled
+	for key, tournament := range s.tournaments.GetAll() {
+		if saved[key] || tournament.Cancelled || tournament.StartDate < windowStart || tournament.StartDate > windowEnd {
+			continue
+		}
+		previous := tournament
+		tournament.Cancelled = true
+		s.tournaments.Set(key, tournament)
+		changes = append(changes, TournamentChange{Kind: ChangeCancelled, Before: &previous, After: tournament})
+		logger.Info("Cancelled tournament detected", "tournament_id", tournament.ID, "name", tournament.Name)
+	}
+
+	return changes
+}
+
+// tournamentChanged reports whether two versions of a tournament differ, ignoring their timestamps
+func tournamentChanged(before, after TournamentCache) bool {
+	before.Timestamp = time.Time{}
+	after.Timestamp = time.Time{}
+	return !reflect.DeepEqual(before, after)
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyTournamentsDetectsChanges(t *testing.T) {
+	store := NewStore(t.TempDir())
+	for _, tournament := range []TournamentCache{
+		{ID: 1, Name: "Tournoi A", StartDate: "2025-10-04T00:00:00"},
+		{ID: 2, Name: "Tournoi B", StartDate: "2025-11-08T00:00:00"},
+		{ID: 3, Name: "Tournoi C", StartDate: "2025-12-06T00:00:00"},
+		{ID: 4, Name: "Tournoi D", StartDate: "2026-03-07T00:00:00"},
+	} {
+		store.Set(tournament)
+	}
+
+	changes := store.apply([]TournamentCache{
+		{ID: 1, Name: "Tournoi A", StartDate: "2025-10-04T00:00:00"},
+		{ID: 3, Name: "Tournoi C (reporté)", StartDate: "2025-12-13T00:00:00"},
+		{ID: 5, Name: "Tournoi E", StartDate: "2025-11-15T00:00:00"},
+	})
+
+	byID := make(map[int]TournamentChange)
+	for _, change := range changes {
+		byID[change.After.ID] = change
+	}
+
+	// Tournament 1 is unchanged and tournament 4 starts after the fetched range
+	require.Len(t, changes, 3)
+	assert.Equal(t, ChangeUpdated, byID[3].Kind)
+	assert.Equal(t, "Tournoi C", byID[3].Before.Name)
+	assert.Equal(t, ChangeCreated, byID[5].Kind)
+	assert.Nil(t, byID[5].Before)
+	assert.Equal(t, ChangeCancelled, byID[2].Kind)
+	assert.True(t, byID[2].After.Cancelled)
+
+	cancelled, _ := store.Get(2)
+	assert.True(t, cancelled.Cancelled)
+
+	// A cancelled tournament is only reported once, and reappearing clears the flag
+	assert.Empty(t, store.apply([]TournamentCache{
+		{ID: 1, Name: "Tournoi A", StartDate: "2025-10-04T00:00:00"},
+		{ID: 3, Name: "Tournoi C (reporté)", StartDate: "2025-12-13T00:00:00"},
+		{ID: 5, Name: "Tournoi E", StartDate: "2025-11-15T00:00:00"},
+	}))
+	changes = store.apply([]TournamentCache{{ID: 2, Name: "Tournoi B", StartDate: "2025-11-08T00:00:00"}})
+	require.Len(t, changes, 1)
+	assert.Equal(t, ChangeUpdated, changes[0].Kind)
+	assert.False(t, changes[0].After.Cancelled)
+}
@@ -0,0 +1,27 @@
+package cache
+
+import (
+	"tournois-tt/api/pkg/refdata"
+)
+
+// enrichTournament fills the organizing club's department and region from reference data.
+// The department is taken from the club when recognized, otherwise from the venue postal code,
+// and is stored as its INSEE code; the region is stored as its official name.
+func enrichTournament(t TournamentCache) TournamentCache {
+	department, ok := refdata.LookupDepartment(t.Club.Department)
+	if !ok {
+		department, ok = refdata.DepartmentFromPostalCode(t.Address.PostalCode)
+	}
+
+	if ok {
+		t.Club.Department = department.Code
+		t.Club.Region = refdata.RegionOfDepartment(department).Name
+		return t
+	}
+
+	if region, ok := refdata.LookupRegion(t.Club.Region); ok {
+		t.Club.Region = region.Name
+	}
+
+	return t
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// ChangeRemoved is a tournament missing from the newer version, or purged by an
+// administrator. Saves never remove tournaments.
+const ChangeRemoved ChangeKind = "removed"
+
+// Sorted returns tournaments sorted by id
+func Sorted(tournaments map[string]TournamentCache) []TournamentCache {
+	sorted := make([]TournamentCache, 0, len(tournaments))
+	for _, tournament := range tournaments {
+		sorted = append(sorted, tournament)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	return sorted
+}
+
+// WriteTournaments writes tournaments as an indented JSON array sorted by id, the format of
+// data.json
+func WriteTournaments(w io.Writer, tournaments map[string]TournamentCache) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(Sorted(tournaments)); err != nil {
+		return fmt.Errorf("failed to write tournaments: %v", err)
+	}
+	return nil
+}
+
+// ReadTournaments reads a JSON array of tournaments, such as data.json or an export, by key
+func ReadTournaments(path string) (map[string]TournamentCache, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tournaments: %v", err)
+	}
+
+	var list []TournamentCache
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse tournaments of %s: %v", path, err)
+	}
+
+	tournaments := make(map[string]TournamentCache, len(list))
+	for _, tournament := range list {
+		if tournament.ID == 0 {
+			return nil, fmt.Errorf("tournament without id in %s", path)
+		}
+		tournaments[GenerateTournamentCacheKey(tournament)] = tournament
+	}
+	return tournaments, nil
+}
+
+// Diff returns the changes from the tournaments before to the tournaments after, sorted by
+// tournament id. Removed tournaments have their last version as both Before and After.
+func Diff(before, after map[string]TournamentCache) []TournamentChange {
+	var changes []TournamentChange
+	for key, tournament := range after {
+		previous, exists := before[key]
+		switch {
+		case !exists:
+			changes = append(changes, TournamentChange{Kind: ChangeCreated, After: tournament})
+		case tournament.Cancelled && !previous.Cancelled:
+			changes = append(changes, TournamentChange{Kind: ChangeCancelled, Before: &previous, After: tournament})
+		case tournamentChanged(previous, tournament):
+			changes = append(changes, TournamentChange{Kind: ChangeUpdated, Before: &previous, After: tournament})
+		}
+	}
+	for key, tournament := range before {
+		if _, exists := after[key]; !exists {
+			previous := tournament
+			changes = append(changes, TournamentChange{Kind: ChangeRemoved, Before: &previous, After: tournament})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].After.ID < changes[j].After.ID })
+	return changes
+}
+
+// ChangedFields returns the names of the top-level fields that differ between two versions
+// of a tournament, ignoring their timestamps
+func ChangedFields(before, after TournamentCache) []string {
+	var fields []string
+	add := func(name string, changed bool) {
+		if changed {
+			fields = append(fields, name)
+		}
+	}
+	add("name", before.Name != after.Name)
+	add("type", before.Type != after.Type)
+	add("startDate", before.StartDate != after.StartDate)
+	add("endDate", before.EndDate != after.EndDate)
+	add("address", before.Address != after.Address)
+	add("club", before.Club != after.Club)
+	add("rules", tournamentChanged(TournamentCache{Rules: before.Rules}, TournamentCache{Rules: after.Rules}))
+	add("tables", tournamentChanged(TournamentCache{Tables: before.Tables}, TournamentCache{Tables: after.Tables}))
+	add("endowment", before.Endowment != after.Endowment)
+	add("page", before.Page != after.Page)
+	add("cancelled", before.Cancelled != after.Cancelled)
+	return fields
+}
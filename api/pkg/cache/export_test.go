@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAndReadTournaments(t *testing.T) {
+	tournaments := map[string]TournamentCache{
+		"2": {ID: 2, Name: "Tournoi B"},
+		"1": {ID: 1, Name: "Tournoi A", Rules: &Rules{URL: "https://example.com/1.pdf"}},
+	}
+
+	path := filepath.Join(t.TempDir(), "export.json")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, WriteTournaments(file, tournaments))
+	require.NoError(t, file.Close())
+
+	read, err := ReadTournaments(path)
+	require.NoError(t, err)
+	assert.Equal(t, tournaments, read)
+
+	require.NoError(t, os.WriteFile(path, []byte(`[{"name": "Sans id"}]`), 0644))
+	_, err = ReadTournaments(path)
+	assert.Error(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	before := map[string]TournamentCache{
+		"1": {ID: 1, Name: "Tournoi A"},
+		"2": {ID: 2, Name: "Tournoi B"},
+		"3": {ID: 3, Name: "Tournoi C"},
+		"4": {ID: 4, Name: "Tournoi D"},
+	}
+	after := map[string]TournamentCache{
+		"1": {ID: 1, Name: "Tournoi A"},
+		"2": {ID: 2, Name: "Tournoi B", Cancelled: true},
+		"3": {ID: 3, Name: "Tournoi C", Endowment: 500},
+		"5": {ID: 5, Name: "Tournoi E"},
+	}
+
+	changes := Diff(before, after)
+	require.Len(t, changes, 4)
+	assert.Equal(t, ChangeCancelled, changes[0].Kind)
+	assert.Equal(t, ChangeUpdated, changes[1].Kind)
+	assert.Equal(t, []string{"endowment"}, ChangedFields(*changes[1].Before, changes[1].After))
+	assert.Equal(t, ChangeRemoved, changes[2].Kind)
+	assert.Equal(t, 4, changes[2].After.ID)
+	assert.Equal(t, ChangeCreated, changes[3].Kind)
+	assert.Nil(t, changes[3].Before)
+}
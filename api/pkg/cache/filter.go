@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"tournois-tt/api/pkg/utils"
+)
+
+// TournamentFilter selects cached tournaments. Empty fields and zero dates match everything.
+type TournamentFilter struct {
+	PostalCode  string
+	Locality    string
+	Type        string
+	Department  string
+	Region      string
+	ClubID      int
+	StartAfter  time.Time
+	StartBefore time.Time
+	EndsAfter   time.Time
+}
+
+// Match reports whether a tournament satisfies the filter
+func (f TournamentFilter) Match(t TournamentCache) bool {
+	if f.PostalCode != "" && !strings.HasPrefix(t.Address.PostalCode, f.PostalCode) {
+		return false
+	}
+	if f.Locality != "" && !strings.EqualFold(t.Address.AddressLocality, f.Locality) {
+		return false
+	}
+	if f.Type != "" && t.Type != f.Type {
+		return false
+	}
+	if f.Department != "" && t.Club.Department != f.Department {
+		return false
+	}
+	if f.Region != "" && t.Club.Region != f.Region {
+		return false
+	}
+	if f.ClubID != 0 && t.Club.ID != f.ClubID {
+		return false
+	}
+	if !f.StartAfter.IsZero() || !f.StartBefore.IsZero() {
+		startDate, ok := utils.ParseTournamentDate(t.StartDate)
+		if !ok {
+			return false
+		}
+		if !f.StartAfter.IsZero() && startDate.Before(f.StartAfter) {
+			return false
+		}
+		if !f.StartBefore.IsZero() && startDate.After(f.StartBefore) {
+			return false
+		}
+	}
+	if !f.EndsAfter.IsZero() {
+		endDate := t.EndDate
+		if endDate == "" {
+			endDate = t.StartDate
+		}
+		if end, ok := utils.ParseTournamentDate(endDate); !ok || end.Before(f.EndsAfter) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterTournaments returns the tournaments matching the filter sorted by start date, then id
+func FilterTournaments(tournaments map[string]TournamentCache, f TournamentFilter, descending bool) []TournamentCache {
+	result := make([]TournamentCache, 0, len(tournaments))
+	for _, t := range tournaments {
+		if f.Match(t) {
+			result = append(result, t)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].StartDate != result[j].StartDate {
+			return (result[i].StartDate < result[j].StartDate) != descending
+		}
+		return result[i].ID < result[j].ID
+	})
+
+	return result
+}
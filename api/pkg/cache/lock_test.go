@@ -0,0 +1,23 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockDir(t *testing.T) {
+	dir := t.TempDir()
+
+	unlock, err := LockDir(dir)
+	require.NoError(t, err)
+
+	_, err = LockDir(dir)
+	assert.ErrorIs(t, err, ErrLocked)
+
+	unlock()
+	unlock, err = LockDir(dir)
+	require.NoError(t, err)
+	unlock()
+}
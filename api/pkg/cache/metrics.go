@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"time"
+
+	"tournois-tt/api/pkg/metrics"
+)
+
+var (
+	_ = metrics.NewGaugeFunc("tournois_cache_tournaments", "Number of tournaments in the cache", func() float64 {
+		store := reported.Load()
+		if store == nil {
+			return 0
+		}
+		return float64(store.Size())
+	})
+	lastRefresh = metrics.NewGaugeVec("tournois_cache_last_refresh_timestamp_seconds",
+		"Unix time of the last successful save of refreshed tournaments")
+	tournamentChanges = metrics.NewCounterVec("tournois_cache_tournament_changes_total",
+		"Tournament changes detected when refreshing the cache", "kind")
+)
+
+// recordRefresh updates the metrics of a successful refresh
+func recordRefresh(changes []TournamentChange) {
+	lastRefresh.With().Set(float64(time.Now().Unix()))
+	for _, change := range changes {
+		tournamentChanges.With(string(change.Kind)).Inc()
+	}
+}
@@ -22,8 +22,10 @@ type TournamentCache struct {
 	Address   geocoding.Address `json:"address"`
 	Club      Club              `json:"club"`
 	Rules     *Rules            `json:"rules,omitempty"`
+	Tables    []Table           `json:"tables,omitempty"`
 	Endowment int               `json:"endowment"`
 	Page      string            `json:"page,omitempty"`
+	Cancelled bool              `json:"cancelled,omitempty"`
 	Timestamp time.Time         `json:"timestamp"`
 }
 
@@ -46,13 +48,14 @@ type Rules struct {
 	URL     string `json:"url,omitempty"`
 }
 
-// GeocodeResult represents a cached geocoding result
-type GeocodeResult struct {
-	Address   geocoding.Address `json:"address"`
-	Latitude  float64           `json:"latitude,omitempty"`
-	Longitude float64           `json:"longitude,omitempty"`
-	Failed    bool              `json:"failed"`
-	Timestamp time.Time         `json:"timestamp"`
+// Table represents a tournament table (event) with its entry fee and endowment
+type Table struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Date        string `json:"date,omitempty"`
+	Time        string `json:"time,omitempty"`
+	Fee         int    `json:"fee"`
+	Endowment   int    `json:"endowment"`
 }
 
 // GeocodeConfig allows configuring geocoding behavior
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// ErrTournamentNotFound is returned for tournaments missing from the cache or the purged ones
+var ErrTournamentNotFound = errors.New("tournament not found")
+
+// purgedFilePath is where purged tournaments are persisted. Refreshes skip them until they
+// are restored.
+func (s *Store) purgedFilePath() string {
+	return filepath.Join(s.dir, "purged.json")
+}
+
+// loadPurged reads the purged tournaments file if it exists
+func (s *Store) loadPurged() error {
+	data, err := os.ReadFile(s.purgedFilePath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read purged tournaments: %v", err)
+	}
+
+	var tournaments []TournamentCache
+	if err := json.Unmarshal(data, &tournaments); err != nil {
+		return fmt.Errorf("failed to parse purged tournaments: %v", err)
+	}
+
+	s.purgedMu.Lock()
+	defer s.purgedMu.Unlock()
+	s.purged = make(map[int]TournamentCache, len(tournaments))
+	for _, tournament := range tournaments {
+		s.purged[tournament.ID] = tournament
+	}
+	return nil
+}
+
+// savePurged writes the purged tournaments. Callers hold purgedMu.
+func (s *Store) savePurged() error {
+	data, err := json.MarshalIndent(s.sortedPurged(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal purged tournaments: %v", err)
+	}
+	return s.writeFile(s.purgedFilePath(), data)
+}
+
+// sortedPurged returns the purged tournaments sorted by id. Callers hold purgedMu.
+func (s *Store) sortedPurged() []TournamentCache {
+	tournaments := make([]TournamentCache, 0, len(s.purged))
+	for _, tournament := range s.purged {
+		tournaments = append(tournaments, tournament)
+	}
+	sort.Slice(tournaments, func(i, j int) bool { return tournaments[i].ID < tournaments[j].ID })
+	return tournaments
+}
+
+// isPurged reports whether refreshes must skip a tournament
+func (s *Store) isPurged(id int) bool {
+	s.purgedMu.Lock()
+	defer s.purgedMu.Unlock()
+	_, ok := s.purged[id]
+	return ok
+}
+
+// Purged returns the purged tournaments sorted by id
+func (s *Store) Purged() []TournamentCache {
+	s.purgedMu.Lock()
+	defer s.purgedMu.Unlock()
+	return s.sortedPurged()
+}
+
+// Purge removes a tournament and keeps it aside so that it can be restored
+func (s *Store) Purge(id int) (TournamentCache, error) {
+	key := strconv.Itoa(id)
+	tournament, ok := s.tournaments.Get(key)
+	if !ok {
+		return TournamentCache{}, ErrTournamentNotFound
+	}
+
+	s.purgedMu.Lock()
+	s.purged[id] = tournament
+	err := s.savePurged()
+	if err != nil {
+		delete(s.purged, id)
+	}
+	s.purgedMu.Unlock()
+	if err != nil {
+		return TournamentCache{}, err
+	}
+
+	s.tournaments.Delete(key)
+	s.revision.Add(1)
+	s.notifyChanges([]TournamentChange{{Kind: ChangeRemoved, Before: &tournament, After: tournament}})
+	return tournament, s.Flush()
+}
+
+// Restore puts a purged tournament back
+func (s *Store) Restore(id int) (TournamentCache, error) {
+	s.purgedMu.Lock()
+	tournament, ok := s.purged[id]
+	if !ok {
+		s.purgedMu.Unlock()
+		return TournamentCache{}, ErrTournamentNotFound
+	}
+	delete(s.purged, id)
+	err := s.savePurged()
+	if err != nil {
+		s.purged[id] = tournament
+	}
+	s.purgedMu.Unlock()
+	if err != nil {
+		return TournamentCache{}, err
+	}
+
+	s.Set(tournament)
+	s.notifyChanges([]TournamentChange{{Kind: ChangeCreated, After: tournament}})
+	return tournament, s.Flush()
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeAndRestore(t *testing.T) {
+	store := NewStore(t.TempDir())
+	store.Set(TournamentCache{ID: 1, Name: "Tournoi A", StartDate: "2025-10-04T00:00:00"})
+	store.Set(TournamentCache{ID: 2, Name: "Tournoi B", StartDate: "2025-10-11T00:00:00"})
+	var changes []TournamentChange
+	store.OnTournamentsChanged(func(c []TournamentChange) { changes = append(changes, c...) })
+
+	purgedTournament, err := store.Purge(1)
+	require.NoError(t, err)
+	assert.Equal(t, "Tournoi A", purgedTournament.Name)
+	_, ok := store.Get(1)
+	assert.False(t, ok)
+	assert.FileExists(t, filepath.Join(store.Dir(), "purged.json"))
+	require.Len(t, changes, 1)
+	assert.Equal(t, ChangeRemoved, changes[0].Kind)
+	assert.Equal(t, 1, changes[0].After.ID)
+
+	_, err = store.Purge(1)
+	assert.ErrorIs(t, err, ErrTournamentNotFound)
+
+	// Refreshes don't bring purged tournaments back
+	store.apply([]TournamentCache{
+		{ID: 1, Name: "Tournoi A", StartDate: "2025-10-04T00:00:00"},
+		{ID: 2, Name: "Tournoi B", StartDate: "2025-10-11T00:00:00"},
+	})
+	_, ok = store.Get(1)
+	assert.False(t, ok)
+
+	// Purged tournaments survive a reload
+	store, err = OpenStore(store.Dir())
+	require.NoError(t, err)
+	require.Len(t, store.Purged(), 1)
+	changes = nil
+	store.OnTournamentsChanged(func(c []TournamentChange) { changes = append(changes, c...) })
+
+	restored, err := store.Restore(1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored.ID)
+	_, ok = store.Get(1)
+	assert.True(t, ok)
+	assert.Empty(t, store.Purged())
+	require.Len(t, changes, 1)
+	assert.Equal(t, ChangeCreated, changes[0].Kind)
+}
+
+func TestSetCoordinates(t *testing.T) {
+	store := NewStore(t.TempDir())
+	tournament := TournamentCache{ID: 1, Name: "Tournoi A"}
+	tournament.Address.Failed = true
+	store.Set(tournament)
+	var changes []TournamentChange
+	store.OnTournamentsChanged(func(c []TournamentChange) { changes = append(changes, c...) })
+
+	updated, err := store.SetCoordinates(1, 48.11, -1.67)
+	require.NoError(t, err)
+	assert.False(t, updated.NeedsGeocoding())
+	assert.FileExists(t, store.Path())
+	require.Len(t, changes, 1)
+	assert.Equal(t, ChangeUpdated, changes[0].Kind)
+	assert.True(t, changes[0].Before.NeedsGeocoding())
+	assert.Equal(t, 48.11, changes[0].After.Address.Latitude)
+
+	_, err = store.SetCoordinates(2, 48.11, -1.67)
+	assert.ErrorIs(t, err, ErrTournamentNotFound)
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// refreshState is persisted to refresh.json so that freshness survives restarts. The
+// modification time of data.json is not used, as imports and admin changes write it too.
+type refreshState struct {
+	CurrentSeasonRefreshedAt time.Time `json:"currentSeasonRefreshedAt"`
+}
+
+// refreshFilePath is where the refresh state is persisted
+func (s *Store) refreshFilePath() string {
+	return filepath.Join(s.dir, "refresh.json")
+}
+
+// MarkCurrentSeasonRefreshed records a successful refresh of the current season
+// tournaments and saves it
+func (s *Store) MarkCurrentSeasonRefreshed(at time.Time) error {
+	s.currentSeasonRefresh.Store(at.UnixNano())
+
+	data, err := json.MarshalIndent(refreshState{CurrentSeasonRefreshedAt: at.UTC()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal refresh state: %v", err)
+	}
+	return s.writeFile(s.refreshFilePath(), data)
+}
+
+// LastCurrentSeasonRefresh returns when the current season tournaments were last refreshed,
+// or the zero time if they never were
+func (s *Store) LastCurrentSeasonRefresh() time.Time {
+	nanos := s.currentSeasonRefresh.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// loadRefresh reads the refresh state file if it exists
+func (s *Store) loadRefresh() error {
+	data, err := os.ReadFile(s.refreshFilePath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read refresh state: %v", err)
+	}
+
+	var state refreshState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse refresh state: %v", err)
+	}
+	if !state.CurrentSeasonRefreshedAt.IsZero() {
+		s.currentSeasonRefresh.Store(state.CurrentSeasonRefreshedAt.UnixNano())
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrentSeasonRefreshIsPersisted(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+	store.Set(TournamentCache{ID: 1, Name: "Tournoi A"})
+	require.NoError(t, store.Flush())
+
+	reopened, err := OpenStore(dir)
+	require.NoError(t, err)
+	assert.True(t, reopened.LastCurrentSeasonRefresh().IsZero(), "writing the tournaments is not a refresh")
+
+	refreshed := time.Date(2025, 10, 4, 6, 0, 0, 0, time.UTC)
+	require.NoError(t, store.MarkCurrentSeasonRefreshed(refreshed))
+	reopened, err = OpenStore(dir)
+	require.NoError(t, err)
+	assert.True(t, refreshed.Equal(reopened.LastCurrentSeasonRefresh()))
+
+	require.NoError(t, os.WriteFile(store.refreshFilePath(), []byte("{"), 0644))
+	_, err = OpenStore(dir)
+	assert.ErrorContains(t, err, "failed to parse refresh state")
+}
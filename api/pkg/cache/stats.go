@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"os"
+	"time"
+)
+
+// Stats summarizes the content of the tournament cache
+type Stats struct {
+	Tournaments        int        `json:"tournaments"`
+	Cancelled          int        `json:"cancelled"`
+	FailedGeocoding    int        `json:"failedGeocoding"`
+	MissingCoordinates int        `json:"missingCoordinates"`
+	Purged             int        `json:"purged"`
+	Revision           uint64     `json:"revision"`
+	LastRefresh        *time.Time `json:"lastRefresh,omitempty"`
+	FileSize           int64      `json:"fileSize"`
+	FileModified       *time.Time `json:"fileModified,omitempty"`
+}
+
+// NeedsGeocoding reports whether a tournament has no usable coordinates
+func (t TournamentCache) NeedsGeocoding() bool {
+	return t.Address.Failed || (t.Address.Latitude == 0 && t.Address.Longitude == 0)
+}
+
+// Stats returns statistics about the tournaments
+func (s *Store) Stats() Stats {
+	tournaments := s.All()
+	stats := Stats{
+		Tournaments: len(tournaments),
+		Purged:      len(s.Purged()),
+		Revision:    s.Revision(),
+	}
+	for _, tournament := range tournaments {
+		if tournament.Cancelled {
+			stats.Cancelled++
+		}
+		if tournament.Address.Failed {
+			stats.FailedGeocoding++
+		} else if tournament.NeedsGeocoding() {
+			stats.MissingCoordinates++
+		}
+	}
+	if last := s.LastCurrentSeasonRefresh(); !last.IsZero() {
+		stats.LastRefresh = &last
+	}
+	if info, err := os.Stat(s.Path()); err == nil {
+		modified := info.ModTime()
+		stats.FileSize = info.Size()
+		stats.FileModified = &modified
+	}
+
+	return stats
+}
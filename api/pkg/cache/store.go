@@ -0,0 +1,222 @@
+package cache
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tournois-tt/api/pkg/alerting"
+)
+
+// Store holds the tournaments, persisted to data.json in its directory. Tournaments purged
+// by an administrator are kept aside in purged.json, and the time of the last refresh in
+// refresh.json.
+type Store struct {
+	dir         string
+	tournaments *GenericCache[TournamentCache]
+
+	// revision is incremented every time the tournaments change, savedRevision is the
+	// revision last written to disk
+	revision      atomic.Uint64
+	savedRevision atomic.Uint64
+
+	// currentSeasonRefresh holds the Unix nanoseconds of the last successful refresh of
+	// the current season, 0 if unknown
+	currentSeasonRefresh atomic.Int64
+
+	purgedMu sync.Mutex
+	purged   map[int]TournamentCache
+
+	// saveMu serializes the writes of the store files. unsaved holds the files whose last
+	// write failed, alerted through alerts.
+	saveMu  sync.Mutex
+	unsaved map[string]bool
+	alerts  *alerting.Dispatcher
+
+	listenersMu sync.RWMutex
+	listeners   []func([]TournamentChange)
+	onSaved     []func()
+
+	now func() time.Time
+}
+
+// NewStore returns an empty store persisted in dir
+func NewStore(dir string) *Store {
+	return &Store{
+		dir:         dir,
+		tournaments: NewGenericCache[TournamentCache](),
+		purged:      make(map[int]TournamentCache),
+		unsaved:     make(map[string]bool),
+		now:         time.Now,
+	}
+}
+
+// OpenStore loads the store persisted in dir, empty if it was never saved
+func OpenStore(dir string) (*Store, error) {
+	s := NewStore(dir)
+
+	tournaments, err := LoadFromJSON(s.Path(), GenerateTournamentCacheKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tournament cache: %v", err)
+	}
+	// Enrich tournaments cached before reference data was applied
+	items := tournaments.GetAll()
+	for key, tournament := range items {
+		items[key] = enrichTournament(tournament)
+	}
+	s.tournaments.SetAll(items)
+	s.savedRevision.Store(s.revision.Add(1))
+
+	if err := s.loadRefresh(); err != nil {
+		return nil, err
+	}
+	if err := s.loadPurged(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetClock replaces the clock timestamping changes
+func (s *Store) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Dir returns the directory of the store files
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Path returns the path of the tournaments file
+func (s *Store) Path() string {
+	return filepath.Join(s.dir, "data.json")
+}
+
+// Revision returns a counter that changes whenever the tournaments are modified, allowing
+// derived data to be memoized until the next change
+func (s *Store) Revision() uint64 {
+	return s.revision.Load()
+}
+
+// Size returns the number of tournaments
+func (s *Store) Size() int {
+	return s.tournaments.Size()
+}
+
+// All returns a copy of the tournaments, by key
+func (s *Store) All() map[string]TournamentCache {
+	return s.tournaments.GetAll()
+}
+
+// Get returns a tournament
+func (s *Store) Get(id int) (TournamentCache, bool) {
+	return s.tournaments.Get(strconv.Itoa(id))
+}
+
+// Set stores a tournament, in memory until the next Flush
+func (s *Store) Set(tournament TournamentCache) {
+	tournament = enrichTournament(tournament)
+	s.tournaments.Set(GenerateTournamentCacheKey(tournament), tournament)
+	s.revision.Add(1)
+}
+
+// Replace replaces all the tournaments, in memory until the next Flush
+func (s *Store) Replace(tournaments []TournamentCache) {
+	items := make(map[string]TournamentCache, len(tournaments))
+	for _, tournament := range tournaments {
+		tournament = enrichTournament(tournament)
+		items[GenerateTournamentCacheKey(tournament)] = tournament
+	}
+	s.tournaments.SetAll(items)
+	s.revision.Add(1)
+}
+
+// OnTournamentsChanged registers a function called with the changes detected by each save
+func (s *Store) OnTournamentsChanged(listener func([]TournamentChange)) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+// OnSaved registers a function called after refreshed tournaments were saved
+func (s *Store) OnSaved(fn func()) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	s.onSaved = append(s.onSaved, fn)
+}
+
+// Save stores refreshed tournaments, notifies their changes and writes the file
+func (s *Store) Save(tournaments []TournamentCache) error {
+	changes := s.apply(tournaments)
+	saved := s.revision.Add(1)
+
+	s.notifyChanges(changes)
+
+	if err := s.write(s.Path(), func() error { return SaveToJSON(s.tournaments, s.Path()) }); err != nil {
+		return err
+	}
+	s.savedRevision.Store(saved)
+	recordRefresh(changes)
+
+	s.listenersMu.RLock()
+	defer s.listenersMu.RUnlock()
+	for _, fn := range s.onSaved {
+		fn()
+	}
+	return nil
+}
+
+// Flush writes the tournaments to disk if they changed since they were loaded or saved
+func (s *Store) Flush() error {
+	current := s.Revision()
+	if s.savedRevision.Load() == current {
+		return nil
+	}
+	if err := s.write(s.Path(), func() error { return SaveToJSON(s.tournaments, s.Path()) }); err != nil {
+		return err
+	}
+	s.savedRevision.Store(current)
+	return nil
+}
+
+// SetCoordinates overrides the coordinates of a tournament. Refreshes keep them, as they
+// only geocode tournaments without coordinates.
+func (s *Store) SetCoordinates(id int, latitude, longitude float64) (TournamentCache, error) {
+	previous, ok := s.Get(id)
+	if !ok {
+		return TournamentCache{}, ErrTournamentNotFound
+	}
+
+	tournament := previous
+	tournament.Address.Latitude = latitude
+	tournament.Address.Longitude = longitude
+	tournament.Address.Failed = false
+	tournament.Timestamp = s.now()
+	s.Update(tournament)
+	return tournament, s.Flush()
+}
+
+// Update replaces stored tournaments, in memory until the next Flush, and notifies the
+// listeners of their changes. Tournaments that are not stored are ignored.
+func (s *Store) Update(tournaments ...TournamentCache) {
+	var changes []TournamentChange
+	for _, tournament := range tournaments {
+		previous, ok := s.Get(tournament.ID)
+		if !ok {
+			continue
+		}
+		s.Set(tournament)
+		changes = append(changes, TournamentChange{Kind: ChangeUpdated, Before: &previous, After: tournament})
+	}
+	s.notifyChanges(changes)
+}
+
+// reported is the store whose size is exported in metrics
+var reported atomic.Pointer[Store]
+
+// ReportMetrics exports the size of the store in the tournois_cache_tournaments metric
+func (s *Store) ReportMetrics() {
+	reported.Store(s)
+}
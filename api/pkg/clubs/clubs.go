@@ -0,0 +1,205 @@
+// Package clubs derives organizing clubs and their tournament history from the tournament cache
+package clubs
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/utils"
+)
+
+// Edition is a tournament organized by a club
+type Edition struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+	Endowment int    `json:"endowment"`
+}
+
+// Venue is the usual location of a club's tournaments
+type Venue struct {
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	Tournaments int     `json:"tournaments"`
+}
+
+// Club is an organizing club with its tournament history
+type Club struct {
+	ID                  int            `json:"id"`
+	Name                string         `json:"name"`
+	Code                string         `json:"code"`
+	Identifier          string         `json:"identifier"`
+	Department          string         `json:"department,omitempty"`
+	Region              string         `json:"region,omitempty"`
+	TournamentsBySeason map[string]int `json:"tournamentsBySeason"`
+	Upcoming            []Edition      `json:"upcoming"`
+	Venue               *Venue         `json:"venue,omitempty"`
+	History             []Edition      `json:"history,omitempty"`
+}
+
+// venuePrecision rounds coordinates to 4 decimals (~10m) when grouping venues
+const venuePrecision = 1e4
+
+// memo caches the derived clubs until the tournaments change or the day ends
+var memo = struct {
+	sync.Mutex
+	store    *cache.Store
+	revision uint64
+	today    time.Time
+	clubs    map[int]Club
+}{}
+
+// List returns all organizing clubs sorted by name, without their full history. Editions
+// ending on or after now are upcoming.
+func List(store *cache.Store, now time.Time) []Club {
+	clubsByID := load(store, now)
+
+	result := make([]Club, 0, len(clubsByID))
+	for _, club := range clubsByID {
+		club.History = nil
+		result = append(result, club)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].ID < result[j].ID
+	})
+
+	return result
+}
+
+// Get returns a club with its full tournament history
+func Get(store *cache.Store, id int, now time.Time) (Club, bool) {
+	club, ok := load(store, now)[id]
+	return club, ok
+}
+
+// load returns the clubs derived from the store, memoized per store revision and day
+func load(store *cache.Store, now time.Time) map[int]Club {
+	memo.Lock()
+	defer memo.Unlock()
+
+	revision, today := store.Revision(), utils.Today(now)
+	if memo.store != store || memo.revision != revision || !memo.today.Equal(today) {
+		memo.clubs = Build(store.All(), now)
+		memo.store = store
+		memo.revision = revision
+		memo.today = today
+	}
+
+	return memo.clubs
+}
+
+// Build groups tournaments by organizing club. Editions ending on or after now are upcoming.
+func Build(tournaments map[string]cache.TournamentCache, now time.Time) map[int]Club {
+	// Sort tournaments chronologically so the latest club data wins
+	sorted := make([]cache.TournamentCache, 0, len(tournaments))
+	for _, t := range tournaments {
+		if t.Club.ID != 0 {
+			sorted = append(sorted, t)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].StartDate != sorted[j].StartDate {
+			return sorted[i].StartDate < sorted[j].StartDate
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	clubsByID := make(map[int]Club)
+	venues := make(map[int]map[[2]float64]int)
+
+	for _, t := range sorted {
+		club, ok := clubsByID[t.Club.ID]
+		if !ok {
+			club = Club{
+				ID:                  t.Club.ID,
+				TournamentsBySeason: make(map[string]int),
+				Upcoming:            []Edition{},
+			}
+			venues[t.Club.ID] = make(map[[2]float64]int)
+		}
+
+		club.Name = t.Club.Name
+		club.Code = t.Club.Code
+		club.Identifier = t.Club.Identifier
+		if t.Club.Department != "" {
+			club.Department = t.Club.Department
+		}
+		if t.Club.Region != "" {
+			club.Region = t.Club.Region
+		}
+
+		edition := Edition{
+			ID:        t.ID,
+			Name:      t.Name,
+			Type:      t.Type,
+			StartDate: t.StartDate,
+			EndDate:   t.EndDate,
+			Endowment: t.Endowment,
+		}
+		club.History = append(club.History, edition)
+
+		if startDate, ok := utils.ParseTournamentDate(t.StartDate); ok {
+			club.TournamentsBySeason[utils.SeasonLabel(startDate)]++
+		}
+		if isUpcoming(t, now) {
+			club.Upcoming = append(club.Upcoming, edition)
+		}
+
+		if !t.Address.Failed && t.Address.Latitude != 0 && t.Address.Longitude != 0 {
+			venues[t.Club.ID][roundCoordinates(t.Address.Latitude, t.Address.Longitude)]++
+		}
+
+		clubsByID[t.Club.ID] = club
+	}
+
+	for id, club := range clubsByID {
+		club.Venue = usualVenue(venues[id])
+		clubsByID[id] = club
+	}
+
+	return clubsByID
+}
+
+// isUpcoming reports whether a tournament has not ended yet
+func isUpcoming(t cache.TournamentCache, now time.Time) bool {
+	date := t.EndDate
+	if date == "" {
+		date = t.StartDate
+	}
+
+	end, ok := utils.ParseTournamentDate(date)
+	if !ok {
+		return false
+	}
+
+	return !end.Before(utils.Today(now))
+}
+
+// usualVenue returns the most frequent coordinates, or nil if none are known
+func usualVenue(counts map[[2]float64]int) *Venue {
+	var best *Venue
+	for coordinates, count := range counts {
+		if best == nil || count > best.Tournaments ||
+			(count == best.Tournaments && coordinates[0] < best.Latitude) {
+			best = &Venue{Latitude: coordinates[0], Longitude: coordinates[1], Tournaments: count}
+		}
+	}
+	return best
+}
+
+// roundCoordinates rounds coordinates so that nearby geocodes of the same venue match
+func roundCoordinates(lat, lon float64) [2]float64 {
+	return [2]float64{
+		math.Round(lat*venuePrecision) / venuePrecision,
+		math.Round(lon*venuePrecision) / venuePrecision,
+	}
+}
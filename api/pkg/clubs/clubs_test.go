@@ -0,0 +1,62 @@
+package clubs
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/geocoding"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	club := cache.Club{ID: 42, Name: "TT Rennes", Department: "35", Region: "Bretagne"}
+	gym := geocoding.Address{Latitude: 48.11731, Longitude: -1.67782}
+
+	tournaments := map[string]cache.TournamentCache{
+		"1": {ID: 1, Name: "Open 2024", StartDate: "2024-10-05T00:00:00", EndDate: "2024-10-06T00:00:00", Club: club, Address: gym},
+		"2": {ID: 2, Name: "Open 2025", StartDate: "2025-10-04T00:00:00", EndDate: "2025-10-05T00:00:00", Club: club, Address: gym},
+		"3": {ID: 3, Name: "Noël 2025", StartDate: "2025-12-20T00:00:00", EndDate: "2025-12-20T00:00:00", Club: club,
+			Address: geocoding.Address{Latitude: 48.2, Longitude: -1.6}},
+		"4": {ID: 4, Name: "Sans club", StartDate: "2025-12-20T00:00:00"},
+	}
+
+	clubsByID := Build(tournaments, time.Date(2025, time.November, 1, 12, 0, 0, 0, time.UTC))
+	require.Len(t, clubsByID, 1)
+
+	got := clubsByID[42]
+	assert.Equal(t, "TT Rennes", got.Name)
+	assert.Equal(t, map[string]int{"2024-2025": 1, "2025-2026": 2}, got.TournamentsBySeason)
+	require.Len(t, got.Upcoming, 1)
+	assert.Equal(t, 3, got.Upcoming[0].ID)
+	require.Len(t, got.History, 3)
+	assert.Equal(t, 1, got.History[0].ID)
+
+	require.NotNil(t, got.Venue)
+	assert.Equal(t, 48.1173, got.Venue.Latitude)
+	assert.Equal(t, -1.6778, got.Venue.Longitude)
+	assert.Equal(t, 2, got.Venue.Tournaments)
+}
+
+func TestHostTransportSkipsVerificationForTheProxyOnly(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	insecure := &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	proxied := &http.Client{Transport: hostTransport{host: serverURL.Hostname(), transport: insecure, fallback: http.DefaultTransport}}
+	resp, err := proxied.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	other := &http.Client{Transport: hostTransport{host: proxyHost, transport: insecure, fallback: http.DefaultTransport}}
+	_, err = other.Get(server.URL)
+	assert.Error(t, err, "the self-signed certificate must be verified for other hosts")
+}
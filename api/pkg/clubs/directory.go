@@ -0,0 +1,299 @@
+package clubs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"tournois-tt/api/pkg/logging"
+	"tournois-tt/api/pkg/refdata"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+var logger = logging.For("clubs")
+
+// URLs of the FFTT directory of clubs, committees and leagues
+const (
+	structuresURL   = "https://www.fftt.com/site/ajax1"
+	structureURL    = "https://www.fftt.com/site/structures/by-number?number_id=%s"
+	clubDetailURL   = "https://" + proxyHost + "/v1/proxy/xml_club_detail.php?club=%s"
+	proxyHost       = "fftt.dafunker.com"
+	directoryPause  = 200 * time.Millisecond
+	clubDetailTries = 3
+)
+
+var (
+	structureIDPattern   = regexp.MustCompile(`structures/by-number\?number_id=(\d+)`)
+	departmentPattern    = regexp.MustCompile(`(?i)(?:^|\D)((?:97[1-4]|97[6]|2[AB]|0[1-9]|[1-9][0-9]))(?:\D|$)`)
+	leagueRegionPattern  = regexp.MustCompile(`(?i)Ligue\s+(?:de\s+)?([^<>\n]+)`)
+	structureMailPattern = regexp.MustCompile(`Mail : ([^<\n]+)(?:<|$)`)
+)
+
+// Contact is the email address of a club, committee or league of the FFTT directory
+type Contact struct {
+	Email      string
+	Department string
+	Region     string
+}
+
+// NewHTTPClient returns the client to scrape the directory with. The certificate of the FFTT
+// API proxy is not trusted by every system, so it is not verified for that host only.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	proxy := http.DefaultTransport.(*http.Transport).Clone()
+	proxy.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: hostTransport{host: proxyHost, transport: proxy, fallback: http.DefaultTransport},
+	}
+}
+
+// hostTransport sends the requests to host with transport, and the others with fallback
+type hostTransport struct {
+	host      string
+	transport http.RoundTripper
+	fallback  http.RoundTripper
+}
+
+func (t hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Hostname() == t.host {
+		return t.transport.RoundTrip(req)
+	}
+	return t.fallback.RoundTrip(req)
+}
+
+// Departments returns the codes of the metropolitan and overseas departments, as used by
+// the FFTT directory
+func Departments() []string {
+	departments := make([]string, 0, 100)
+	for i := 1; i <= 95; i++ {
+		departments = append(departments, fmt.Sprintf("%02d", i))
+	}
+	return append(departments, "971", "972", "973", "974", "976")
+}
+
+// regionOfDepartment returns the region name of a department code, or "" if unknown
+func regionOfDepartment(code string) string {
+	department, ok := refdata.DepartmentByCode(code)
+	if !ok {
+		return ""
+	}
+	return refdata.RegionOfDepartment(department).Name
+}
+
+// ScrapeClubContacts passes the email address of every club of the departments to found.
+// Failures on a department or a club are logged and skipped.
+func ScrapeClubContacts(ctx context.Context, client *http.Client, departments []string, found func(Contact)) error {
+	for _, department := range departments {
+		logger.InfoContext(ctx, "Scraping clubs of department", "department", department)
+		body, err := postStructures(ctx, client, url.Values{"structures_department": {department}})
+		if err != nil {
+			logger.WarnContext(ctx, "Failed to list clubs", "department", department, "error", err)
+			continue
+		}
+
+		for _, match := range structureIDPattern.FindAllStringSubmatch(body, -1) {
+			if err := pause(ctx); err != nil {
+				return err
+			}
+
+			club, err := clubDetail(ctx, client, match[1])
+			if err != nil {
+				logger.WarnContext(ctx, "Failed to fetch club details", "club", match[1], "error", err)
+				continue
+			}
+			if email := strings.TrimSpace(club.Email); email != "" {
+				region := regionOfDepartment(department)
+				if region == "" {
+					region = "Unknown"
+				}
+				found(Contact{Email: email, Department: department, Region: region})
+			}
+		}
+	}
+	return nil
+}
+
+// ScrapeStructureContacts passes the email addresses of every league and committee to found
+func ScrapeStructureContacts(ctx context.Context, client *http.Client, found func(Contact)) error {
+	body, err := postStructures(ctx, client, url.Values{"categories_id": {"Ligue"}})
+	if err != nil {
+		return err
+	}
+
+	leagues := strings.Index(body, "Les ligues")
+	committees := strings.Index(body, "Les comités")
+	if leagues == -1 || committees == -1 {
+		return fmt.Errorf("leagues and committees sections not found")
+	}
+
+	logger.InfoContext(ctx, "Scraping leagues")
+	if err := scrapeStructures(ctx, client, body[leagues:committees], found); err != nil {
+		return err
+	}
+	logger.InfoContext(ctx, "Scraping committees")
+	return scrapeStructures(ctx, client, body[committees:], found)
+}
+
+// scrapeStructures passes the email addresses of the structures linked from section to found
+func scrapeStructures(ctx context.Context, client *http.Client, section string, found func(Contact)) error {
+	for _, match := range structureIDPattern.FindAllStringSubmatch(section, -1) {
+		if err := pause(ctx); err != nil {
+			return err
+		}
+
+		page, err := get(ctx, client, fmt.Sprintf(structureURL, match[1]))
+		if err != nil {
+			logger.WarnContext(ctx, "Failed to fetch structure details", "structure", match[1], "error", err)
+			continue
+		}
+		body := string(page)
+
+		var department, region string
+		if m := departmentPattern.FindStringSubmatch(body); len(m) > 1 {
+			department = m[1]
+			region = regionOfDepartment(department)
+		}
+		if region == "" {
+			region = "Unknown"
+			if m := leagueRegionPattern.FindStringSubmatch(body); len(m) > 1 {
+				region = strings.TrimSpace(m[1])
+			}
+		}
+
+		for _, m := range structureMailPattern.FindAllStringSubmatch(body, -1) {
+			emails := strings.FieldsFunc(m[1], func(r rune) bool {
+				return r == '|' || r == ';' || r == ',' || r == '\n' || r == '\r'
+			})
+			for _, email := range emails {
+				if email = strings.TrimSpace(email); email != "" {
+					found(Contact{Email: email, Department: department, Region: region})
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// postStructures searches the FFTT directory and returns the HTML of the results
+func postStructures(ctx context.Context, client *http.Client, form url.Values) (string, error) {
+	form.Set("plugins_controller", "structures")
+	form.Set("plugins_action", "plugin_maps_ajax")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, structuresURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// get returns the body of a page
+func get(ctx context.Context, client *http.Client, pageURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// clubDetailResponse is the club detail returned by the FFTT API proxy
+type clubDetailResponse struct {
+	XMLName xml.Name `xml:"liste"`
+	Clubs   []struct {
+		Email  string `xml:"mailcor"`
+		Number string `xml:"numero"`
+		Name   string `xml:"nom"`
+	} `xml:"club"`
+}
+
+// clubErrorResponse is returned by the FFTT API proxy for unknown clubs
+type clubErrorResponse struct {
+	XMLName xml.Name `xml:"erreurs"`
+	Message string   `xml:",chardata"`
+}
+
+// clubDetail fetches the details of a club, retrying with an exponential backoff
+func clubDetail(ctx context.Context, client *http.Client, clubID string) (Contact, error) {
+	var lastErr error
+	for attempt := 0; attempt < clubDetailTries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return Contact{}, ctx.Err()
+			case <-time.After(time.Duration(1<<uint(attempt)) * time.Second):
+			}
+		}
+
+		body, err := get(ctx, client, fmt.Sprintf(clubDetailURL, clubID))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		var failure clubErrorResponse
+		if err := decodeLatin1(body, &failure); err == nil && failure.Message != "" {
+			return Contact{}, fmt.Errorf("API error: %s", failure.Message)
+		}
+
+		var detail clubDetailResponse
+		if err := decodeLatin1(body, &detail); err != nil {
+			lastErr = err
+			continue
+		}
+		if len(detail.Clubs) == 0 {
+			return Contact{}, nil
+		}
+		return Contact{Email: detail.Clubs[0].Email}, nil
+	}
+	return Contact{}, fmt.Errorf("failed after %d attempts, last error: %v", clubDetailTries, lastErr)
+}
+
+// decodeLatin1 decodes XML that may be encoded in ISO-8859-1
+func decodeLatin1(data []byte, v any) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		if strings.ToLower(charset) != "iso-8859-1" {
+			return nil, fmt.Errorf("unknown charset: %s", charset)
+		}
+		return transform.NewReader(input, charmap.ISO8859_1.NewDecoder()), nil
+	}
+	return decoder.Decode(v)
+}
+
+// pause spaces the requests to the FFTT directory
+func pause(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(directoryPause):
+		return nil
+	}
+}
@@ -0,0 +1,152 @@
+// Package degraded tracks whether the API serves its last good snapshot of the tournaments
+// because refreshing them from the FFTT keeps failing. Entering degraded mode, staying in it
+// too long and recovering raise alerts of increasing severity.
+package degraded
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"tournois-tt/api/pkg/alerting"
+	"tournois-tt/api/pkg/logging"
+)
+
+var logger = logging.For("degraded")
+
+// Mode is whether the tournaments are refreshed normally
+type Mode string
+
+// Modes
+const (
+	Normal   Mode = "normal"
+	Degraded Mode = "degraded"
+)
+
+// alertKey identifies the alerts of degraded mode
+const alertKey = "tournaments.refresh"
+
+// Policy sets when to enter degraded mode and when to escalate
+type Policy struct {
+	// FailuresBeforeDegraded is the number of consecutive failed refreshes entering degraded mode
+	FailuresBeforeDegraded int
+	// EscalateAfter is how long degraded mode lasts before a critical alert
+	EscalateAfter time.Duration
+}
+
+// DefaultPolicy tolerates a single failed refresh and escalates after an hour
+var DefaultPolicy = Policy{FailuresBeforeDegraded: 2, EscalateAfter: time.Hour}
+
+// Status is the current mode and the failures that caused it
+type Status struct {
+	Mode      Mode       `json:"mode"`
+	Since     *time.Time `json:"since,omitempty"`
+	Failures  int        `json:"failures"`
+	LastError string     `json:"lastError,omitempty"`
+	Escalated bool       `json:"escalated"`
+}
+
+// Machine is the degraded mode state machine: normal, then degraded after consecutive
+// failures, escalated after a while, and back to normal on the first success
+type Machine struct {
+	mu        sync.Mutex
+	policy    Policy
+	now       func() time.Time
+	alert     func(alerting.Alert)
+	mode      Mode
+	since     time.Time
+	failures  int
+	lastError string
+	escalated bool
+}
+
+// New returns a machine in normal mode sending its alerts to alert
+func New(policy Policy, alert func(alerting.Alert)) *Machine {
+	return &Machine{policy: policy, now: time.Now, alert: alert, mode: Normal}
+}
+
+// SetPolicy changes the thresholds of the machine
+func (m *Machine) SetPolicy(policy Policy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.policy = policy
+}
+
+// Failed records a failed refresh
+func (m *Machine) Failed(err error) {
+	m.mu.Lock()
+	now := m.now()
+	m.failures++
+	m.lastError = err.Error()
+
+	var alert *alerting.Alert
+	switch {
+	case m.mode == Normal && m.failures >= m.policy.FailuresBeforeDegraded:
+		m.mode, m.since = Degraded, now
+		logger.Warn("Entering degraded mode, serving the last good tournaments", "failures", m.failures, "error", err)
+		alert = &alerting.Alert{
+			Key:      alertKey,
+			Severity: alerting.SeverityWarning,
+			Title:    "Tournaments refresh failing, serving stale data",
+			Message:  fmt.Sprintf("%d consecutive refreshes failed: %v", m.failures, err),
+		}
+	case m.mode == Degraded && !m.escalated && now.Sub(m.since) >= m.policy.EscalateAfter:
+		m.escalated = true
+		alert = &alerting.Alert{
+			Key:      alertKey,
+			Severity: alerting.SeverityCritical,
+			Title:    "Tournaments stale for " + now.Sub(m.since).Round(time.Minute).String(),
+			Message:  fmt.Sprintf("%d consecutive refreshes failed since %s: %v", m.failures, m.since.Format(time.RFC3339), err),
+		}
+	}
+	m.mu.Unlock()
+
+	if alert != nil {
+		alert.Time = now
+		m.alert(*alert)
+	}
+}
+
+// Succeeded records a successful refresh, leaving degraded mode
+func (m *Machine) Succeeded() {
+	m.mu.Lock()
+	now := m.now()
+	wasDegraded, since, severity := m.mode == Degraded, m.since, alerting.SeverityWarning
+	if m.escalated {
+		severity = alerting.SeverityCritical
+	}
+	m.mode, m.since, m.failures, m.lastError, m.escalated = Normal, time.Time{}, 0, "", false
+	m.mu.Unlock()
+
+	if wasDegraded {
+		logger.Info("Leaving degraded mode, tournaments refreshed", "degraded_for", now.Sub(since))
+		m.alert(alerting.Alert{
+			Key:      alertKey,
+			Severity: severity,
+			Title:    "Tournaments refresh recovered",
+			Message:  "Refreshed after " + now.Sub(since).Round(time.Second).String() + " in degraded mode",
+			Time:     now,
+			Resolved: true,
+		})
+	}
+}
+
+// Active reports whether the API is in degraded mode
+func (m *Machine) Active() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mode == Degraded
+}
+
+// Status returns the current mode
+func (m *Machine) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := Status{Mode: m.mode, Failures: m.failures, LastError: m.lastError, Escalated: m.escalated}
+	if m.mode == Degraded {
+		since := m.since
+		status.Since = &since
+	}
+	return status
+}
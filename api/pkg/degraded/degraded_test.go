@@ -0,0 +1,67 @@
+package degraded
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"tournois-tt/api/pkg/alerting"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDegradedModeEscalatesAndRecovers(t *testing.T) {
+	var alerts []alerting.Alert
+	m := New(Policy{FailuresBeforeDegraded: 2, EscalateAfter: time.Hour}, func(alert alerting.Alert) {
+		alerts = append(alerts, alert)
+	})
+	now := time.Date(2025, 10, 3, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	fftt := errors.New("FFTT returned 503")
+
+	m.Failed(fftt)
+	assert.False(t, m.Active())
+	assert.Empty(t, alerts)
+
+	now = now.Add(5 * time.Minute)
+	m.Failed(fftt)
+	require.True(t, m.Active())
+	require.Len(t, alerts, 1)
+	assert.Equal(t, alerting.SeverityWarning, alerts[0].Severity)
+
+	now = now.Add(30 * time.Minute)
+	m.Failed(fftt)
+	assert.Len(t, alerts, 1)
+
+	now = now.Add(30 * time.Minute)
+	m.Failed(fftt)
+	m.Failed(fftt)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, alerting.SeverityCritical, alerts[1].Severity)
+
+	status := m.Status()
+	assert.Equal(t, Degraded, status.Mode)
+	assert.Equal(t, 5, status.Failures)
+	assert.Equal(t, "FFTT returned 503", status.LastError)
+	assert.True(t, status.Escalated)
+
+	m.Succeeded()
+	assert.False(t, m.Active())
+	require.Len(t, alerts, 3)
+	assert.True(t, alerts[2].Resolved)
+	assert.Equal(t, alerting.SeverityCritical, alerts[2].Severity)
+	assert.Equal(t, Status{Mode: Normal}, m.Status())
+
+	m.Succeeded()
+	assert.Len(t, alerts, 3)
+}
+
+func TestSuccessResetsConsecutiveFailures(t *testing.T) {
+	m := New(Policy{FailuresBeforeDegraded: 2, EscalateAfter: time.Hour}, func(alerting.Alert) {})
+
+	m.Failed(errors.New("timeout"))
+	m.Succeeded()
+	m.Failed(errors.New("timeout"))
+	assert.False(t, m.Active())
+}
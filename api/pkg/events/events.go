@@ -0,0 +1,254 @@
+// Package events publishes tournament changes detected by the cache on an in-process bus,
+// keeping a bounded replay buffer so that subscribers can resume after a disconnection.
+package events
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tournois-tt/api/pkg/cache"
+)
+
+// Type is the type of a tournament event
+type Type string
+
+// Event types
+const (
+	TournamentCreated   Type = "tournament.created"
+	TournamentUpdated   Type = "tournament.updated"
+	TournamentCancelled Type = "tournament.cancelled"
+	// TournamentRemoved is published when an administrator purges a tournament
+	TournamentRemoved Type = "tournament.removed"
+)
+
+// DefaultReplaySize is the number of events kept by the default bus for replay
+const DefaultReplaySize = 1000
+
+// subscriberBuffer is the number of events a subscriber may lag behind before being dropped
+const subscriberBuffer = 64
+
+// FieldChange is the before and after JSON values of a changed tournament field
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// Event is a change of a tournament
+type Event struct {
+	ID         uint64                `json:"id"`
+	Type       Type                  `json:"type"`
+	Time       time.Time             `json:"time"`
+	Tournament cache.TournamentCache `json:"tournament"`
+	Changes    []FieldChange         `json:"changes,omitempty"`
+}
+
+// Subscription receives the events published after it was created
+type Subscription struct {
+	// Events is closed when the subscription is cancelled or lags too far behind
+	Events <-chan Event
+	events chan Event
+}
+
+// Bus dispatches events to subscribers and keeps the most recent ones for replay. Event ids
+// restart at 1 with each bus, so they are only meaningful with its epoch.
+type Bus struct {
+	mu          sync.Mutex
+	epoch       string
+	lastID      uint64
+	replay      []Event
+	replaySize  int
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+// NewBus creates a bus keeping up to replaySize events for replay
+func NewBus(replaySize int) *Bus {
+	return &Bus{
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		replaySize:  replaySize,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Epoch identifies the bus, different in every process
+func (b *Bus) Epoch() string {
+	return b.epoch
+}
+
+// StreamID returns the id of an event sent to clients, prefixed with the epoch of the bus
+func (b *Bus) StreamID(event Event) string {
+	return b.epoch + "-" + strconv.FormatUint(event.ID, 10)
+}
+
+// ParseStreamID returns the epoch and event id of a StreamID. Ids without an epoch, sent by
+// earlier versions, have an empty epoch.
+func ParseStreamID(raw string) (epoch string, id uint64, err error) {
+	if i := strings.LastIndexByte(raw, '-'); i >= 0 {
+		epoch, raw = raw[:i], raw[i+1:]
+	}
+	id, err = strconv.ParseUint(raw, 10, 64)
+	return epoch, id, err
+}
+
+// PublishChanges converts cache changes to events and publishes them. It is meant to be
+// registered with Store.OnTournamentsChanged.
+func (b *Bus) PublishChanges(changes []cache.TournamentChange) {
+	for _, change := range changes {
+		switch change.Kind {
+		case cache.ChangeCreated:
+			b.Publish(TournamentCreated, change.After, nil)
+		case cache.ChangeUpdated:
+			b.Publish(TournamentUpdated, change.After, Diff(*change.Before, change.After))
+		case cache.ChangeCancelled:
+			b.Publish(TournamentCancelled, change.After, nil)
+		case cache.ChangeRemoved:
+			b.Publish(TournamentRemoved, change.After, nil)
+		}
+	}
+}
+
+// Publish assigns the next id to an event, records it for replay and sends it to subscribers
+func (b *Bus) Publish(eventType Type, tournament cache.TournamentCache, changes []FieldChange) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event := Event{
+		ID:         b.lastID,
+		Type:       eventType,
+		Time:       time.Now().UTC(),
+		Tournament: tournament,
+		Changes:    changes,
+	}
+
+	b.replay = append(b.replay, event)
+	if len(b.replay) > b.replaySize {
+		b.replay = b.replay[len(b.replay)-b.replaySize:]
+	}
+
+	for subscription := range b.subscribers {
+		select {
+		case subscription.events <- event:
+		default:
+			// Drop subscribers that cannot keep up, they resume from the replay buffer
+			delete(b.subscribers, subscription)
+			close(subscription.events)
+		}
+	}
+
+	return event
+}
+
+// Subscribe returns the buffered events published after lastEventID of epoch and a
+// subscription to the following ones. complete is false when events after lastEventID were
+// already evicted from the replay buffer, or when lastEventID is unknown to this bus, such
+// as an id of another epoch: nothing is replayed then.
+func (b *Bus) Subscribe(epoch string, lastEventID uint64) (replay []Event, subscription *Subscription, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	complete = true
+	if lastEventID > 0 && epoch != b.epoch {
+		complete = false
+	} else if lastEventID > 0 {
+		oldest := b.lastID + 1
+		if len(b.replay) > 0 {
+			oldest = b.replay[0].ID
+		}
+		complete = lastEventID <= b.lastID && lastEventID+1 >= oldest
+
+		for _, event := range b.replay {
+			if event.ID > lastEventID {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	events := make(chan Event, subscriberBuffer)
+	subscription = &Subscription{Events: events, events: events}
+	if b.closed {
+		close(events)
+		return replay, subscription, complete
+	}
+	b.subscribers[subscription] = struct{}{}
+
+	return replay, subscription, complete
+}
+
+// Unsubscribe cancels a subscription
+func (b *Bus) Unsubscribe(subscription *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[subscription]; ok {
+		delete(b.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+// Recent returns up to limit buffered events, most recent first
+func (b *Bus) Recent(limit int) []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if limit <= 0 || limit > len(b.replay) {
+		limit = len(b.replay)
+	}
+	recent := make([]Event, 0, limit)
+	for i := len(b.replay) - 1; i >= len(b.replay)-limit; i-- {
+		recent = append(recent, b.replay[i])
+	}
+	return recent
+}
+
+// Close cancels every subscription, so that streams end when the server shuts down.
+// Later subscriptions are closed immediately.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for subscription := range b.subscribers {
+		delete(b.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+// Diff returns the top-level tournament fields whose JSON values differ, ignoring the timestamp
+func Diff(before, after cache.TournamentCache) []FieldChange {
+	beforeFields := jsonFields(before)
+	afterFields := jsonFields(after)
+
+	names := make(map[string]struct{}, len(beforeFields)+len(afterFields))
+	for name := range beforeFields {
+		names[name] = struct{}{}
+	}
+	for name := range afterFields {
+		names[name] = struct{}{}
+	}
+	delete(names, "timestamp")
+
+	var changes []FieldChange
+	for name := range names {
+		if !reflect.DeepEqual(beforeFields[name], afterFields[name]) {
+			changes = append(changes, FieldChange{Field: name, Before: beforeFields[name], After: afterFields[name]})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// jsonFields returns the JSON object representation of a tournament
+func jsonFields(t cache.TournamentCache) map[string]any {
+	raw, _ := json.Marshal(t)
+	var fields map[string]any
+	_ = json.Unmarshal(raw, &fields)
+	return fields
+}
@@ -0,0 +1,130 @@
+package events
+
+import (
+	"testing"
+
+	"tournois-tt/api/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribeReplaysBufferedEvents(t *testing.T) {
+	bus := NewBus(3)
+	for id := 1; id <= 5; id++ {
+		bus.Publish(TournamentCreated, cache.TournamentCache{ID: id}, nil)
+	}
+
+	replay, subscription, complete := bus.Subscribe(bus.Epoch(), 3)
+	defer bus.Unsubscribe(subscription)
+	assert.True(t, complete)
+	require.Len(t, replay, 2)
+	assert.EqualValues(t, 4, replay[0].ID)
+
+	// Event 2 was evicted from the buffer
+	replay, other, complete := bus.Subscribe(bus.Epoch(), 1)
+	bus.Unsubscribe(other)
+	assert.False(t, complete)
+	assert.Len(t, replay, 3)
+
+	// Ids unknown to the bus, or from another process, are not replayed
+	_, other, complete = bus.Subscribe(bus.Epoch(), 42)
+	bus.Unsubscribe(other)
+	assert.False(t, complete)
+	replay, other, complete = bus.Subscribe("other", 3)
+	bus.Unsubscribe(other)
+	assert.False(t, complete)
+	assert.Empty(t, replay)
+
+	bus.Publish(TournamentCancelled, cache.TournamentCache{ID: 6}, nil)
+	event := <-subscription.Events
+	assert.EqualValues(t, 6, event.ID)
+	assert.Equal(t, TournamentCancelled, event.Type)
+}
+
+func TestStreamIDs(t *testing.T) {
+	bus := NewBus(DefaultReplaySize)
+	event := bus.Publish(TournamentCreated, cache.TournamentCache{ID: 1}, nil)
+
+	epoch, id, err := ParseStreamID(bus.StreamID(event))
+	require.NoError(t, err)
+	assert.Equal(t, bus.Epoch(), epoch)
+	assert.Equal(t, event.ID, id)
+
+	epoch, id, err = ParseStreamID("12")
+	require.NoError(t, err)
+	assert.Empty(t, epoch, "ids of earlier versions have no epoch")
+	assert.EqualValues(t, 12, id)
+
+	_, _, err = ParseStreamID("epoch-twelve")
+	assert.Error(t, err)
+}
+
+func TestSlowSubscribersAreDropped(t *testing.T) {
+	bus := NewBus(DefaultReplaySize)
+	_, subscription, _ := bus.Subscribe(bus.Epoch(), 0)
+
+	for id := 0; id <= subscriberBuffer; id++ {
+		bus.Publish(TournamentCreated, cache.TournamentCache{ID: id}, nil)
+	}
+
+	received := 0
+	for range subscription.Events {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received)
+
+	// Unsubscribing a dropped subscription is a no-op
+	bus.Unsubscribe(subscription)
+}
+
+func TestRecentReturnsNewestFirst(t *testing.T) {
+	bus := NewBus(2)
+	for id := 1; id <= 3; id++ {
+		bus.Publish(TournamentCreated, cache.TournamentCache{ID: id}, nil)
+	}
+
+	recent := bus.Recent(5)
+	require.Len(t, recent, 2)
+	assert.Equal(t, 3, recent[0].Tournament.ID)
+	assert.Equal(t, 2, recent[1].Tournament.ID)
+	assert.Len(t, bus.Recent(1), 1)
+}
+
+func TestCloseEndsSubscriptions(t *testing.T) {
+	bus := NewBus(DefaultReplaySize)
+	_, subscription, _ := bus.Subscribe(bus.Epoch(), 0)
+
+	bus.Close()
+	_, open := <-subscription.Events
+	assert.False(t, open)
+
+	_, late, _ := bus.Subscribe(bus.Epoch(), 0)
+	_, open = <-late.Events
+	assert.False(t, open)
+	bus.Unsubscribe(late)
+}
+
+func TestPublishChangesComputesDiffs(t *testing.T) {
+	bus := NewBus(DefaultReplaySize)
+	_, subscription, _ := bus.Subscribe(bus.Epoch(), 0)
+	defer bus.Unsubscribe(subscription)
+
+	before := cache.TournamentCache{ID: 1, Name: "Tournoi", StartDate: "2025-10-04T00:00:00", Endowment: 500}
+	after := before
+	after.StartDate = "2025-10-11T00:00:00"
+	after.Tables = []cache.Table{{Name: "A", Fee: 8}}
+
+	bus.PublishChanges([]cache.TournamentChange{{Kind: cache.ChangeUpdated, Before: &before, After: after}})
+
+	event := <-subscription.Events
+	assert.Equal(t, TournamentUpdated, event.Type)
+	require.Len(t, event.Changes, 2)
+	assert.Equal(t, FieldChange{Field: "startDate", Before: "2025-10-04T00:00:00", After: "2025-10-11T00:00:00"}, event.Changes[0])
+	assert.Equal(t, "tables", event.Changes[1].Field)
+	assert.Nil(t, event.Changes[1].Before)
+
+	bus.PublishChanges([]cache.TournamentChange{{Kind: cache.ChangeRemoved, Before: &after, After: after}})
+	event = <-subscription.Events
+	assert.Equal(t, TournamentRemoved, event.Type)
+}
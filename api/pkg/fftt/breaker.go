@@ -0,0 +1,162 @@
+package fftt
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tournois-tt/api/pkg/alerting"
+	"tournois-tt/api/pkg/metrics"
+)
+
+// CircuitState is the state of a circuit breaker
+type CircuitState string
+
+const (
+	// CircuitClosed lets requests through
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejects requests until the cooldown is over
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single probe request through
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// ErrCircuitOpen is returned instead of calling the FFTT API while it is failing
+var ErrCircuitOpen = errors.New("FFTT circuit breaker is open")
+
+// Breaker stops calling the FFTT API after consecutive failures, and probes it again
+// once a cooldown has passed
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	state     CircuitState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	onChange  func(state CircuitState, failures int)
+}
+
+// NewBreaker returns a breaker opening after threshold consecutive failures for cooldown
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     CircuitClosed,
+	}
+}
+
+// NewAlertingBreaker returns the breaker guarding the FFTT client of the application,
+// alerting through alerts while it is open
+func NewAlertingBreaker(alerts *alerting.Dispatcher) *Breaker {
+	b := NewBreaker(5, 2*time.Minute)
+	b.OnChange(func(state CircuitState, failures int) {
+		const key = "fftt.circuit"
+		if state == CircuitClosed {
+			alerts.Resolve(key, "FFTT API reachable again", "")
+			return
+		}
+		alerts.Fire(alerting.Alert{
+			Key:      key,
+			Severity: alerting.SeverityWarning,
+			Title:    "FFTT circuit breaker opened",
+			Message:  fmt.Sprintf("%d consecutive FFTT requests failed, tournaments are not refreshed", failures),
+		})
+	})
+	return b
+}
+
+// OnChange sets a function called when the breaker opens or closes
+func (b *Breaker) OnChange(fn func(state CircuitState, failures int)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onChange = fn
+}
+
+// reported is the breaker whose state is exported in metrics
+var reported atomic.Pointer[Breaker]
+
+var _ = metrics.NewGaugeFunc("tournois_fftt_circuit_open",
+	"1 when the FFTT circuit breaker rejects requests, 0 otherwise", func() float64 {
+		b := reported.Load()
+		if b == nil || b.State() == CircuitClosed {
+			return 0
+		}
+		return 1
+	})
+
+// ReportMetrics exports the state of the breaker in the tournois_fftt_circuit_open metric
+func (b *Breaker) ReportMetrics() {
+	reported.Store(b)
+}
+
+// Allow reports whether a request may be sent, returning ErrCircuitOpen otherwise
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.current() {
+	case CircuitOpen:
+		return ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Record reports the outcome of an allowed request
+func (b *Breaker) Record(success bool) {
+	b.mu.Lock()
+	state := b.current()
+	b.probing = false
+	changed := false
+
+	if success {
+		if state != CircuitClosed {
+			logger.Info("FFTT circuit breaker closed")
+			changed = true
+		}
+		b.state = CircuitClosed
+		b.failures = 0
+	} else {
+		b.failures++
+		if state == CircuitHalfOpen || b.failures >= b.threshold {
+			if state != CircuitOpen {
+				logger.Warn("FFTT circuit breaker opened", "failures", b.failures, "cooldown", b.cooldown)
+				changed = true
+			}
+			b.state = CircuitOpen
+			b.openedAt = b.now()
+		}
+	}
+
+	newState, failures, onChange := b.state, b.failures, b.onChange
+	b.mu.Unlock()
+
+	if changed && onChange != nil {
+		onChange(newState, failures)
+	}
+}
+
+// State returns the current state of the breaker
+func (b *Breaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.current()
+}
+
+// current moves an open breaker to half-open once the cooldown is over. Callers hold b.mu.
+func (b *Breaker) current() CircuitState {
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		b.state = CircuitHalfOpen
+		b.probing = false
+	}
+	return b.state
+}
@@ -0,0 +1,87 @@
+package fftt
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreakerOpensAndProbes(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBreaker(3, time.Minute)
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, b.Allow())
+		b.Record(false)
+	}
+	assert.Equal(t, CircuitClosed, b.State())
+
+	require.NoError(t, b.Allow())
+	b.Record(false)
+	assert.Equal(t, CircuitOpen, b.State())
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// A single probe is let through after the cooldown
+	now = now.Add(time.Minute)
+	assert.Equal(t, CircuitHalfOpen, b.State())
+	require.NoError(t, b.Allow())
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// A failed probe opens the circuit again
+	b.Record(false)
+	assert.Equal(t, CircuitOpen, b.State())
+
+	now = now.Add(time.Minute)
+	require.NoError(t, b.Allow())
+	b.Record(true)
+	assert.Equal(t, CircuitClosed, b.State())
+	assert.NoError(t, b.Allow())
+}
+
+func TestClientOpensCircuitOnServerErrors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		HTTPClient: &http.Client{Transport: &mockTransport{server: server}},
+		Breaker:    NewBreaker(2, time.Minute),
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.GetTournaments(context.Background(), nil)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	_, err := client.GetTournaments(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 2, calls)
+}
+
+func TestBreakerNotifiesChanges(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+	var changes []CircuitState
+	b.OnChange(func(state CircuitState, failures int) { changes = append(changes, state) })
+
+	b.Record(false)
+	b.Record(false)
+	b.Record(false)
+	now = now.Add(time.Minute)
+	require.NoError(t, b.Allow())
+	b.Record(true)
+	b.Record(true)
+
+	assert.Equal(t, []CircuitState{CircuitOpen, CircuitClosed}, changes)
+}
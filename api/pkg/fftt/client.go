@@ -1,12 +1,22 @@
 package fftt
 
 import (
-	"log"
+	"context"
 	"net/http"
 	"net/url"
-	"sync"
+	"strconv"
+	"time"
+
+	"tournois-tt/api/pkg/logging"
+	"tournois-tt/api/pkg/metrics"
 )
 
+var logger = logging.For("fftt")
+
+var requestDuration = metrics.NewHistogramVec("tournois_fftt_request_duration_seconds",
+	"Duration of FFTT API requests by status code, or error when no response was received",
+	[]float64{0.25, 0.5, 1, 2.5, 5, 10, 30, 60}, "status")
+
 // API URL constants
 const (
 	// FFTT_API_BASE_URL is the base URL for FFTT API
@@ -19,34 +29,28 @@ const (
 
 // FFTTClientInterface defines the interface for the FFTT client
 type FFTTClientInterface interface {
-	GetTournaments(params url.Values) (*http.Response, error)
+	GetTournaments(ctx context.Context, params url.Values) (*http.Response, error)
 }
 
 // Client implements the FFTTClientInterface
 type Client struct {
 	HTTPClient *http.Client
+	// Breaker, when set, stops requests while the API is failing
+	Breaker *Breaker
 }
 
-// Global client instance for the application
-var FFTTClient FFTTClientInterface
-var initOnce sync.Once
-
-// GetClient returns the singleton instance of the FFTT client
-func GetClient() FFTTClientInterface {
-	initOnce.Do(func() {
-		FFTTClient = &Client{
-			HTTPClient: &http.Client{},
-		}
-	})
-	return FFTTClient
+// NewClient returns a client sending its requests with httpClient, stopped by breaker
+// while the API is failing
+func NewClient(httpClient *http.Client, breaker *Breaker) *Client {
+	return &Client{HTTPClient: httpClient, Breaker: breaker}
 }
 
 // GetTournaments fetches tournaments from the FFTT API
-func (c *Client) GetTournaments(params url.Values) (*http.Response, error) {
+func (c *Client) GetTournaments(ctx context.Context, params url.Values) (*http.Response, error) {
 	// Construct the full URL with constants
 	requestURL := FFTT_API_BASE_URL + FFTT_TOURNAMENT_ENDPOINT
 
-	req, err := http.NewRequest("GET", requestURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -56,13 +60,32 @@ func (c *Client) GetTournaments(params url.Values) (*http.Response, error) {
 		req.URL.RawQuery = params.Encode()
 	}
 
-	// Log the complete URL with query parameters
-	log.Println("ok123", req.URL.String())
+	logger.DebugContext(ctx, "Requesting FFTT tournaments", "url", req.URL.String())
 
 	// Set required headers
 	req.Header.Set("Referer", FFTT_REFERER_URL)
 	req.Header.Set("Content-Type", "application/json")
+	logging.PropagateRequestID(ctx, req)
+
+	if c.Breaker != nil {
+		if err := c.Breaker.Allow(); err != nil {
+			return nil, err
+		}
+	}
 
 	// Send the request
-	return c.HTTPClient.Do(req)
+	start := time.Now()
+	resp, err := c.HTTPClient.Do(req)
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	if c.Breaker != nil {
+		// Client errors are our own, only outages and throttling open the circuit
+		c.Breaker.Record(err == nil && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests)
+	}
+	requestDuration.With(status).Observe(time.Since(start).Seconds())
+	logger.DebugContext(ctx, "FFTT request completed", "status", status, "duration", time.Since(start))
+
+	return resp, err
 }
@@ -1,28 +1,27 @@
 package fftt
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
 
+	"tournois-tt/api/pkg/logging"
+
 	"github.com/stretchr/testify/assert"
 )
 
-func TestGetClient(t *testing.T) {
-	// Get the client
-	client := GetClient()
-	assert.NotNil(t, client, "GetClient() should not return nil")
-
-	// Call again to test singleton behavior
-	client2 := GetClient()
-	assert.Equal(t, client, client2, "GetClient() should return the same instance on subsequent calls")
+func TestNewClient(t *testing.T) {
+	httpClient := &http.Client{}
+	breaker := NewBreaker(3, 0)
 
-	// Make sure the HTTPClient is initialized
-	clientImpl, ok := client.(*Client)
-	assert.True(t, ok, "Client should be of type *Client")
-	assert.NotNil(t, clientImpl.HTTPClient, "The HTTPClient should be initialized")
+	// Each client is independent, so that tests and applications can run side by side
+	client := NewClient(httpClient, breaker)
+	assert.Same(t, httpClient, client.HTTPClient)
+	assert.Same(t, breaker, client.Breaker)
+	assert.NotSame(t, client, NewClient(httpClient, breaker))
 }
 
 func TestGetTournaments(t *testing.T) {
@@ -38,6 +37,7 @@ func TestGetTournaments(t *testing.T) {
 		// Verify request headers
 		assert.Equal(t, "application/json", r.Header.Get("Content-Type"), "Content-Type header should be set to application/json")
 		assert.Equal(t, FFTT_REFERER_URL, r.Header.Get("Referer"), "Referer header should be set")
+		assert.Equal(t, "cron-42", r.Header.Get(logging.RequestIDHeader), "Request ID should be propagated")
 
 		// Send a mock response
 		w.Header().Set("Content-Type", "application/json")
@@ -58,7 +58,7 @@ func TestGetTournaments(t *testing.T) {
 	params.Add("param", "test")
 
 	// Call the method
-	resp, err := client.GetTournaments(params)
+	resp, err := client.GetTournaments(logging.WithRequestID(context.Background(), "cron-42"), params)
 
 	// Verify the response
 	assert.NoError(t, err, "GetTournaments should not return an error")
@@ -82,7 +82,7 @@ func TestGetTournamentsError(t *testing.T) {
 	}
 
 	// Call the method with empty parameters
-	resp, err := client.GetTournaments(url.Values{})
+	resp, err := client.GetTournaments(context.Background(), url.Values{})
 
 	// Verify the response
 	assert.Error(t, err, "GetTournaments should return an error")
@@ -2,25 +2,15 @@ package fftt
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"time"
 )
 
-// Debug flag to control verbose logging
-var Debug = false
-
-// debugLog logs a message only if Debug is true
-func debugLog(format string, args ...interface{}) {
-	if Debug {
-		log.Printf(format, args...)
-	}
-}
-
 // truncateString truncates a string to maxLen and adds "..." if it was truncated
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -30,14 +20,8 @@ func truncateString(s string, maxLen int) string {
 }
 
 // FetchTournaments fetches tournaments from the FFTT API with the given query parameters
-func FetchTournaments(queryParams url.Values) ([]Tournament, error) {
-	// Ensure the FFTTClient is initialized if it's nil
-	if FFTTClient == nil {
-		GetClient() // This will initialize FFTTClient
-	}
-
-	// Use the client to make the request
-	resp, err := FFTTClient.GetTournaments(queryParams)
+func FetchTournaments(ctx context.Context, client FFTTClientInterface, queryParams url.Values) ([]Tournament, error) {
+	resp, err := client.GetTournaments(ctx, queryParams)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch tournaments: %v", err)
 	}
@@ -53,8 +37,7 @@ func FetchTournaments(queryParams url.Values) ([]Tournament, error) {
 		return nil, fmt.Errorf("failed to read response body: %v", err)
 	}
 
-	// Log the full response body if in debug mode
-	debugLog("FFTT API response: %s", string(bodyBytes))
+	logger.DebugContext(ctx, "FFTT API response", "body", truncateString(string(bodyBytes), 2000))
 
 	// Check if the response is an array
 	trimmedBody := bytes.TrimSpace(bodyBytes)
@@ -109,7 +92,7 @@ func FetchTournaments(queryParams url.Values) ([]Tournament, error) {
 }
 
 // GetFutureTournaments fetches and returns tournaments that start after the given date
-func GetFutureTournaments(startDateAfter time.Time, startDateBefore *time.Time) ([]Tournament, error) {
+func GetFutureTournaments(ctx context.Context, client FFTTClientInterface, startDateAfter time.Time, startDateBefore *time.Time) ([]Tournament, error) {
 	// Create query params for future tournaments
 	queryParams := url.Values{}
 	queryParams.Set("startDate[after]", startDateAfter.Format("2006-01-02T15:04:05"))
@@ -119,5 +102,5 @@ func GetFutureTournaments(startDateAfter time.Time, startDateBefore *time.Time)
 	queryParams.Set("itemsPerPage", "999999")
 	queryParams.Set("order[startDate]", "asc")
 
-	return FetchTournaments(queryParams)
+	return FetchTournaments(ctx, client, queryParams)
 }
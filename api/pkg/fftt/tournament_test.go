@@ -1,6 +1,7 @@
 package fftt
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -14,7 +15,7 @@ type mockClient struct {
 	mockGetTournamentsFn func(params url.Values) (*http.Response, error)
 }
 
-func (m *mockClient) GetTournaments(params url.Values) (*http.Response, error) {
+func (m *mockClient) GetTournaments(_ context.Context, params url.Values) (*http.Response, error) {
 	return m.mockGetTournamentsFn(params)
 }
 
@@ -69,10 +70,6 @@ func TestFetchTournaments(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// Save original client and replace it after test
-	originalClient := FFTTClient
-	defer func() { FFTTClient = originalClient }()
-
 	// Create mock client
 	mockFFTT := &mockClient{
 		mockGetTournamentsFn: func(params url.Values) (*http.Response, error) {
@@ -87,16 +84,11 @@ func TestFetchTournaments(t *testing.T) {
 		},
 	}
 
-	// Override the client for testing
-	FFTTClient = mockFFTT
-
 	// Test the fetch tournaments function
 	params := url.Values{}
 	params.Set("itemsPerPage", "10")
 
-	// Important: Call FetchTournaments with the explicit client parameter
-	// instead of relying on GetClient() to get the globally set client
-	tournaments, err := FetchTournaments(params)
+	tournaments, err := FetchTournaments(context.Background(), mockFFTT, params)
 
 	// Check for errors
 	if err != nil {
@@ -152,10 +144,6 @@ func TestGetFutureTournaments(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// Save original client and replace it after test
-	originalClient := FFTTClient
-	defer func() { FFTTClient = originalClient }()
-
 	// Create mock client
 	mockFFTT := &mockClient{
 		mockGetTournamentsFn: func(params url.Values) (*http.Response, error) {
@@ -170,14 +158,11 @@ func TestGetFutureTournaments(t *testing.T) {
 		},
 	}
 
-	// Override the client for testing
-	FFTTClient = mockFFTT
-
 	// Test GetFutureTournaments
 	startDateAfter := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
 	startDateBefore := time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)
 
-	tournaments, err := GetFutureTournaments(startDateAfter, &startDateBefore)
+	tournaments, err := GetFutureTournaments(context.Background(), mockFFTT, startDateAfter, &startDateBefore)
 
 	// Check for errors
 	if err != nil {
@@ -17,11 +17,39 @@ func (a Address) IsValid() bool {
 	return a.PostalCode != "" && a.AddressLocality != ""
 }
 
+// Precision is how closely a location matches the address, from the locality to the
+// building
+type Precision int
+
+const (
+	PrecisionUnknown Precision = iota
+	PrecisionLocality
+	PrecisionStreet
+	PrecisionAddress
+)
+
+// String returns the name of the precision
+func (p Precision) String() string {
+	switch p {
+	case PrecisionLocality:
+		return "locality"
+	case PrecisionStreet:
+		return "street"
+	case PrecisionAddress:
+		return "address"
+	default:
+		return "unknown"
+	}
+}
+
 // Location represents a geocoded location
 type Location struct {
 	Lat    float64 `json:"lat"`
 	Lon    float64 `json:"lon"`
 	Failed bool    `json:"failed"`
+	// Precision and Confidence, between 0 and 1, are set by the providers reporting them
+	Precision  Precision `json:"precision,omitempty"`
+	Confidence float64   `json:"confidence,omitempty"`
 }
 
 // IsAddressValid checks if an address has enough data to be geocoded
@@ -0,0 +1,61 @@
+package geocoding
+
+import (
+	"fmt"
+
+	"tournois-tt/api/pkg/alerting"
+)
+
+// Sustained failure detection over the last attempts of each provider. The alert is
+// resolved once the failure rate drops below half of the threshold.
+const (
+	failureWindow    = 20
+	failureMinimum   = 10
+	failureThreshold = 0.5
+)
+
+// failureTracker holds the outcomes of the last attempts of a provider
+type failureTracker struct {
+	outcomes []bool
+	alerting bool
+}
+
+// trackFailures records an attempt and alerts on sustained failure. Callers hold m.mu.
+func (m *Monitor) trackFailures(provider string, failed bool) {
+	tracker, ok := m.trackers[provider]
+	if !ok {
+		tracker = &failureTracker{}
+		m.trackers[provider] = tracker
+	}
+	tracker.outcomes = append(tracker.outcomes, failed)
+	if len(tracker.outcomes) > failureWindow {
+		tracker.outcomes = tracker.outcomes[1:]
+	}
+	if len(tracker.outcomes) < failureMinimum {
+		return
+	}
+
+	failures := 0
+	for _, failed := range tracker.outcomes {
+		if failed {
+			failures++
+		}
+	}
+	rate := float64(failures) / float64(len(tracker.outcomes))
+
+	key := "geocoding." + provider
+	switch {
+	case !tracker.alerting && rate >= failureThreshold:
+		tracker.alerting = true
+		m.alerts.Fire(alerting.Alert{
+			Key:      key,
+			Severity: alerting.SeverityWarning,
+			Title:    "Geocoding with " + provider + " failing",
+			Message: fmt.Sprintf("%d of the last %d attempts failed, new tournaments may be missing from the map",
+				failures, len(tracker.outcomes)),
+		})
+	case tracker.alerting && rate < failureThreshold/2:
+		tracker.alerting = false
+		m.alerts.Resolve(key, "Geocoding with "+provider+" recovered", "")
+	}
+}
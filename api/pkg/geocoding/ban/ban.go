@@ -0,0 +1,287 @@
+// Package ban geocodes French addresses with the Base Adresse Nationale
+// (api-adresse.data.gouv.fr), one address at a time or in batches with its CSV endpoint
+package ban
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"tournois-tt/api/pkg/geocoding"
+	"tournois-tt/api/pkg/logging"
+	"tournois-tt/api/pkg/utils"
+)
+
+var logger = logging.For("geocoding")
+
+// BaseURL is the address API, serving /search/ and /search/csv/
+const BaseURL = "https://api-adresse.data.gouv.fr"
+
+// Provider implements geocoding.Provider with the BAN
+type Provider struct {
+	// URL is the address API, BaseURL unless self-hosted or testing
+	URL string
+	// MinScore rejects the results scoring less, from 0 to 1
+	MinScore float64
+	client   *http.Client
+}
+
+// NewProvider returns the BAN provider sending its requests with client
+func NewProvider(client *http.Client) *Provider {
+	return &Provider{URL: BaseURL, client: client}
+}
+
+// Factory creates the provider of a geocoding registry
+func Factory(settings geocoding.Settings) geocoding.Provider {
+	provider := NewProvider(settings.Client())
+	if settings.URL != "" {
+		provider.URL = settings.URL
+	}
+	provider.MinScore = settings.MinScore
+	return provider
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "BAN"
+}
+
+// GetCoordinates geocodes an address in its postal code, returning the best match
+func (p *Provider) GetCoordinates(ctx context.Context, address geocoding.Address) (geocoding.Location, error) {
+	text := queryText(address)
+	params := url.Values{}
+	params.Set("q", text)
+	params.Set("limit", "1")
+	if postcode := strings.TrimSpace(address.PostalCode); postcode != "" {
+		params.Set("postcode", postcode)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL+"/search/?"+params.Encode(), nil)
+	if err != nil {
+		return geocoding.Location{Failed: true}, fmt.Errorf("request creation error: %v", err)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return geocoding.Location{Failed: true}, fmt.Errorf("network error: %v", err)
+	}
+	defer resp.Body.Close()
+	if err := statusError(resp); err != nil {
+		return geocoding.Location{Failed: true}, err
+	}
+
+	var collection struct {
+		Features []struct {
+			Geometry struct {
+				// Coordinates are longitude, then latitude
+				Coordinates []float64 `json:"coordinates"`
+			} `json:"geometry"`
+			Properties struct {
+				Label string  `json:"label"`
+				Score float64 `json:"score"`
+				Type  string  `json:"type"`
+			} `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&collection); err != nil {
+		return geocoding.Location{Failed: true}, fmt.Errorf("parsing error: %v", err)
+	}
+	if len(collection.Features) == 0 || len(collection.Features[0].Geometry.Coordinates) != 2 {
+		return geocoding.Location{Failed: true}, fmt.Errorf("%w for address: %s", geocoding.ErrNotFound, text)
+	}
+
+	feature := collection.Features[0]
+	if feature.Properties.Score < p.MinScore {
+		return geocoding.Location{Failed: true}, fmt.Errorf("%w for address: %s, best match %s scores %.2f",
+			geocoding.ErrNotFound, text, feature.Properties.Label, feature.Properties.Score)
+	}
+	location := geocoding.Location{
+		Lat:        feature.Geometry.Coordinates[1],
+		Lon:        feature.Geometry.Coordinates[0],
+		Precision:  precision(feature.Properties.Type),
+		Confidence: feature.Properties.Score,
+	}
+	logger.Debug("Geocoded address", "provider", "ban", "address", text, "label", feature.Properties.Label,
+		"type", feature.Properties.Type, "score", feature.Properties.Score)
+	return location, nil
+}
+
+// GetCoordinatesBatch geocodes addresses in their postal codes with a single request to the
+// CSV endpoint, returning a result per address in the same order. The error is set when the
+// whole batch failed.
+func (p *Provider) GetCoordinatesBatch(ctx context.Context, addresses []geocoding.Address) ([]geocoding.BatchResult, error) {
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+
+	var data bytes.Buffer
+	writer := csv.NewWriter(&data)
+	if err := writer.Write([]string{"id", "q", "postcode"}); err != nil {
+		return nil, err
+	}
+	for i, address := range addresses {
+		if err := writer.Write([]string{strconv.Itoa(i), queryText(address),
+			strings.TrimSpace(address.PostalCode)}); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("data", "addresses.csv")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(data.Bytes()); err != nil {
+		return nil, err
+	}
+	// The q column is searched, filtered by the postcode column
+	for _, field := range [][2]string{{"columns", "q"}, {"postcode", "postcode"}} {
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			return nil, err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL+"/search/csv/", &body)
+	if err != nil {
+		return nil, fmt.Errorf("request creation error: %v", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %v", err)
+	}
+	defer resp.Body.Close()
+	if err := statusError(resp); err != nil {
+		return nil, err
+	}
+
+	results, err := parseBatch(resp.Body, len(addresses), p.MinScore)
+	if err != nil {
+		return nil, fmt.Errorf("parsing error: %v", err)
+	}
+	return results, nil
+}
+
+// statusError returns the error of a response other than 200, a RateLimitedError when the
+// BAN asks to slow down
+func statusError(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusTooManyRequests:
+		return &geocoding.RateLimitedError{RetryAfter: utils.RetryAfter(resp.Header.Get("Retry-After"), time.Now())}
+	default:
+		return fmt.Errorf("HTTP error: %s", resp.Status)
+	}
+}
+
+// parseBatch reads the CSV returned by the batch endpoint, the columns sent followed by the
+// result columns. Results scoring less than minScore are not found.
+func parseBatch(r io.Reader, count int, minScore float64) ([]geocoding.BatchResult, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("empty response")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[strings.TrimPrefix(name, "\ufeff")] = i
+	}
+	for _, name := range []string{"id", "latitude", "longitude", "result_score", "result_type"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %s", name)
+		}
+	}
+	value := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	results := make([]geocoding.BatchResult, count)
+	for i := range results {
+		results[i].Location.Failed = true
+		results[i].Err = fmt.Errorf("%w: missing from the batch response", geocoding.ErrNotFound)
+	}
+	for _, row := range rows[1:] {
+		i, err := strconv.Atoi(value(row, "id"))
+		if err != nil || i < 0 || i >= count {
+			return nil, fmt.Errorf("unexpected id %q", value(row, "id"))
+		}
+
+		text := value(row, "q")
+		switch status := value(row, "result_status"); status {
+		case "", "ok":
+		case "not-found":
+			results[i].Err = fmt.Errorf("%w for address: %s", geocoding.ErrNotFound, text)
+			continue
+		default:
+			results[i].Err = fmt.Errorf("geocoding %s failed: %s", text, status)
+			continue
+		}
+		lat, latErr := strconv.ParseFloat(value(row, "latitude"), 64)
+		lon, lonErr := strconv.ParseFloat(value(row, "longitude"), 64)
+		if latErr != nil || lonErr != nil {
+			results[i].Err = fmt.Errorf("%w for address: %s", geocoding.ErrNotFound, text)
+			continue
+		}
+		score, _ := strconv.ParseFloat(value(row, "result_score"), 64)
+		if score < minScore {
+			results[i].Err = fmt.Errorf("%w for address: %s, best match %s scores %.2f",
+				geocoding.ErrNotFound, text, value(row, "result_label"), score)
+			continue
+		}
+		results[i] = geocoding.BatchResult{Location: geocoding.Location{
+			Lat:        lat,
+			Lon:        lon,
+			Precision:  precision(value(row, "result_type")),
+			Confidence: score,
+		}}
+	}
+	return results, nil
+}
+
+// queryText returns the text searched for an address. Its postal code and city help
+// ranking even when the results are filtered by postal code.
+func queryText(address geocoding.Address) string {
+	street := strings.TrimSpace(address.StreetAddress)
+	if !strings.Contains(street, " ") && address.DisambiguatingDescription != "" {
+		street = strings.TrimSpace(address.DisambiguatingDescription) + " " + street
+	}
+	return strings.Join(strings.Fields(strings.Join([]string{
+		street, address.PostalCode, address.AddressLocality}, " ")), " ")
+}
+
+// precision maps the type of a BAN result
+func precision(resultType string) geocoding.Precision {
+	switch resultType {
+	case "housenumber":
+		return geocoding.PrecisionAddress
+	case "street":
+		return geocoding.PrecisionStreet
+	case "locality", "municipality":
+		return geocoding.PrecisionLocality
+	default:
+		return geocoding.PrecisionUnknown
+	}
+}
@@ -0,0 +1,158 @@
+package ban
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tournois-tt/api/pkg/geocoding"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gymnasium is the address of a tournament venue
+var gymnasium = geocoding.Address{
+	StreetAddress:             "Rue de Saint-Brieuc",
+	PostalCode:                "35000",
+	AddressLocality:           "Rennes",
+	DisambiguatingDescription: "Gymnase Courtemanche",
+}
+
+func TestSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/search/", r.URL.Path)
+		query := r.URL.Query()
+		assert.Equal(t, "35000", query.Get("postcode"))
+		switch query.Get("q") {
+		case "Rue de Saint-Brieuc 35000 Rennes":
+			w.Write([]byte(`{"type": "FeatureCollection", "features": [{
+				"geometry": {"type": "Point", "coordinates": [-1.6891, 48.1185]},
+				"properties": {"label": "Rue de Saint-Brieuc 35000 Rennes", "score": 0.87, "type": "street"}
+			}]}`))
+		case "Salle omnisports 35000 Rennes":
+			w.Write([]byte(`{"type": "FeatureCollection", "features": [{
+				"geometry": {"type": "Point", "coordinates": [-1.6778, 48.1119]},
+				"properties": {"label": "Rue de la Salle 35000 Rennes", "score": 0.31, "type": "street"}
+			}]}`))
+		default:
+			w.Write([]byte(`{"type": "FeatureCollection", "features": []}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewProvider(server.Client())
+	provider.URL = server.URL
+	provider.MinScore = 0.5
+
+	location, err := provider.GetCoordinates(context.Background(), gymnasium)
+	require.NoError(t, err)
+	assert.Equal(t, 48.1185, location.Lat)
+	assert.Equal(t, -1.6891, location.Lon)
+	assert.Equal(t, geocoding.PrecisionStreet, location.Precision)
+	assert.Equal(t, 0.87, location.Confidence)
+
+	_, err = provider.GetCoordinates(context.Background(), geocoding.Address{
+		StreetAddress: "Salle omnisports", PostalCode: "35000", AddressLocality: "Rennes"})
+	assert.ErrorIs(t, err, geocoding.ErrNotFound, "results scoring less than the minimum are rejected")
+
+	_, err = provider.GetCoordinates(context.Background(), geocoding.Address{PostalCode: "35000", AddressLocality: "Nulle part"})
+	assert.ErrorIs(t, err, geocoding.ErrNotFound)
+}
+
+func TestSearchHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	provider := NewProvider(server.Client())
+	provider.URL = server.URL
+
+	_, err := provider.GetCoordinates(context.Background(), gymnasium)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, geocoding.ErrNotFound, "failures other than unknown addresses are retried")
+}
+
+func TestRateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	provider := NewProvider(server.Client())
+	provider.URL = server.URL
+
+	var rateLimited *geocoding.RateLimitedError
+	_, err := provider.GetCoordinates(context.Background(), gymnasium)
+	require.ErrorAs(t, err, &rateLimited)
+	assert.Equal(t, 30*time.Second, rateLimited.RetryAfter)
+
+	_, err = provider.GetCoordinatesBatch(context.Background(), []geocoding.Address{gymnasium})
+	require.ErrorAs(t, err, &rateLimited, "the whole batch is retried later")
+	assert.Equal(t, 30*time.Second, rateLimited.RetryAfter)
+}
+
+func TestBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/search/csv/", r.URL.Path)
+		assert.Equal(t, "q", r.FormValue("columns"))
+		assert.Equal(t, "postcode", r.FormValue("postcode"))
+
+		file, _, err := r.FormFile("data")
+		if !assert.NoError(t, err) {
+			return
+		}
+		rows, err := csv.NewReader(file).ReadAll()
+		assert.NoError(t, err)
+		if assert.Len(t, rows, 5) {
+			assert.Equal(t, []string{"id", "q", "postcode"}, rows[0])
+			assert.Equal(t, []string{"0", "Rue de Saint-Brieuc 35000 Rennes", "35000"}, rows[1])
+			assert.Equal(t, []string{"2", "Lieu inconnu 29000 Quimper", "29000"}, rows[3])
+		}
+
+		// Results are returned in any order, one address missing
+		w.Write([]byte("id,q,postcode,latitude,longitude,result_label,result_score,result_type,result_status\n" +
+			"2,Lieu inconnu 29000 Quimper,29000,,,,,,not-found\n" +
+			"3,Salle omnisports 35000 Rennes,35000,48.1119,-1.6778,Rue de la Salle 35000 Rennes,0.31,street,ok\n" +
+			"0,Rue de Saint-Brieuc 35000 Rennes,35000,48.1185,-1.6891,Rue de Saint-Brieuc 35000 Rennes,0.87,street,ok\n"))
+	}))
+	defer server.Close()
+
+	provider := NewProvider(server.Client())
+	provider.URL = server.URL
+	provider.MinScore = 0.5
+
+	results, err := provider.GetCoordinatesBatch(context.Background(), []geocoding.Address{
+		gymnasium,
+		{StreetAddress: "12 rue de Brest", PostalCode: "29000", AddressLocality: "Quimper"},
+		{StreetAddress: "inconnu", DisambiguatingDescription: "Lieu", PostalCode: "29000", AddressLocality: "Quimper"},
+		{StreetAddress: "Salle omnisports", PostalCode: "35000", AddressLocality: "Rennes"},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, 48.1185, results[0].Location.Lat)
+	assert.Equal(t, -1.6891, results[0].Location.Lon)
+	assert.Equal(t, geocoding.PrecisionStreet, results[0].Location.Precision)
+	assert.Equal(t, 0.87, results[0].Location.Confidence)
+
+	assert.ErrorIs(t, results[1].Err, geocoding.ErrNotFound)
+	assert.True(t, results[1].Location.Failed)
+	assert.ErrorIs(t, results[2].Err, geocoding.ErrNotFound)
+	assert.ErrorIs(t, results[3].Err, geocoding.ErrNotFound, "results scoring less than the minimum are rejected")
+	assert.True(t, results[3].Location.Failed)
+}
+
+func TestPrecision(t *testing.T) {
+	assert.Equal(t, geocoding.PrecisionAddress, precision("housenumber"))
+	assert.Equal(t, geocoding.PrecisionStreet, precision("street"))
+	assert.Equal(t, geocoding.PrecisionLocality, precision("municipality"))
+	assert.Equal(t, geocoding.PrecisionUnknown, precision(""))
+}
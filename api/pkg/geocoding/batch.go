@@ -0,0 +1,156 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// batchSize bounds the addresses sent to a provider in one batch
+const batchSize = 1000
+
+// BatchResult is the outcome of geocoding an address of a batch
+type BatchResult struct {
+	Location Location
+	Err      error
+}
+
+// Batcher is implemented by providers geocoding several addresses with a single request,
+// returning a result per address in the same order. The error is set when the whole batch
+// failed.
+type Batcher interface {
+	GetCoordinatesBatch(ctx context.Context, addresses []Address) ([]BatchResult, error)
+}
+
+// GetCoordinatesBatch geocodes addresses with the strategy of the chain, sending a request
+// per batch to the providers supporting it and a request per address to the others. The
+// addresses left when ctx is done fail with its error.
+func (c *Chain) GetCoordinatesBatch(ctx context.Context, addresses []Address) []BatchResult {
+	results := make([]BatchResult, len(addresses))
+	found := make([]bool, len(addresses))
+	for i := range results {
+		results[i] = BatchResult{Location: Location{Failed: true}, Err: errors.New("no geocoding provider")}
+	}
+
+	for _, provider := range c.Providers {
+		if p, ok := provider.(configurable); ok && !p.Configured() {
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		var pending []int
+		for i := range addresses {
+			if !found[i] || c.Strategy == BestPrecision {
+				pending = append(pending, i)
+			}
+		}
+		for start := 0; start < len(pending); start += batchSize {
+			chunk := pending[start:min(start+batchSize, len(pending))]
+			batch := make([]Address, len(chunk))
+			for j, i := range chunk {
+				batch[j] = addresses[i]
+			}
+
+			for j, result := range geocodeBatch(ctx, provider, batch) {
+				i := chunk[j]
+				if result.Err != nil || result.Location.Failed {
+					if !found[i] {
+						if result.Err == nil {
+							result.Err = fmt.Errorf("%w for address: %s", ErrNotFound, ConstructFullAddress(addresses[i]))
+						}
+						results[i].Err = result.Err
+					}
+					continue
+				}
+				if !found[i] || moreAccurate(result.Location, results[i].Location) {
+					results[i], found[i] = BatchResult{Location: result.Location}, true
+				}
+			}
+		}
+	}
+
+	// Addresses other providers could have found are left for later
+	if err := ctx.Err(); err != nil {
+		for i := range results {
+			if !found[i] {
+				results[i].Err = err
+			}
+		}
+	}
+	return results
+}
+
+// geocodeBatch geocodes addresses with a request per batch when the provider supports it
+func geocodeBatch(ctx context.Context, provider Provider, addresses []Address) []BatchResult {
+	batcher, ok := provider.(Batcher)
+	if !ok {
+		return geocodeEach(ctx, provider, addresses)
+	}
+
+	results, err := batcher.GetCoordinatesBatch(ctx, addresses)
+	if err == nil && len(results) != len(addresses) {
+		err = fmt.Errorf("%s returned %d results for %d addresses", provider.Name(), len(results), len(addresses))
+	}
+	if err != nil {
+		results = make([]BatchResult, len(addresses))
+		for i := range results {
+			results[i] = BatchResult{Location: Location{Failed: true}, Err: err}
+		}
+	}
+	return results
+}
+
+// geocodeEach geocodes addresses one at a time, until ctx is done
+func geocodeEach(ctx context.Context, provider Provider, addresses []Address) []BatchResult {
+	results := make([]BatchResult, len(addresses))
+	for i, address := range addresses {
+		if err := ctx.Err(); err != nil {
+			results[i] = BatchResult{Location: Location{Failed: true}, Err: err}
+			continue
+		}
+		location, err := provider.GetCoordinates(ctx, address)
+		results[i] = BatchResult{Location: location, Err: err}
+	}
+	return results
+}
+
+// GetCoordinatesBatch geocodes addresses with a single request when the provider supports
+// it, retried and counted as one request. Other providers geocode each address in turn.
+func (l *limited) GetCoordinatesBatch(ctx context.Context, addresses []Address) ([]BatchResult, error) {
+	batcher, ok := l.Provider.(Batcher)
+	if !ok {
+		return geocodeEach(ctx, l, addresses), nil
+	}
+
+	var err error
+	for attempt := 0; attempt <= l.policy.Retries; attempt++ {
+		if attempt > 0 {
+			if err := l.sleep(ctx, l.policy.RetryDelay<<(attempt-1)); err != nil {
+				return nil, err
+			}
+		}
+		if err := l.take(ctx); err != nil {
+			return nil, err
+		}
+
+		var results []BatchResult
+		results, err = batcher.GetCoordinatesBatch(ctx, addresses)
+		if err == nil {
+			l.monitor.record(l.Name(), nil)
+			return results, nil
+		}
+		logger.Debug("Batch geocoding failed", "provider", l.Name(), "attempt", attempt+1,
+			"addresses", len(addresses), "error", err)
+		if ctx.Err() != nil {
+			break
+		}
+		var rateLimited *RateLimitedError
+		if errors.As(err, &rateLimited) {
+			l.backOff(rateLimited.RetryAfter)
+		}
+	}
+	l.monitor.record(l.Name(), err)
+	return nil, err
+}
@@ -0,0 +1,86 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchProvider geocodes the addresses of Paris in batches, failing its first batches with
+// err
+type batchProvider struct {
+	failures int
+	err      error
+	batches  int
+}
+
+func (p *batchProvider) GetCoordinates(context.Context, Address) (Location, error) {
+	return Location{Failed: true}, errors.New("batches only")
+}
+
+func (p *batchProvider) GetCoordinatesBatch(_ context.Context, addresses []Address) ([]BatchResult, error) {
+	p.batches++
+	if p.batches <= p.failures {
+		return nil, p.err
+	}
+	results := make([]BatchResult, len(addresses))
+	for i, address := range addresses {
+		if address.AddressLocality != "Paris" {
+			results[i] = BatchResult{Location: Location{Failed: true}, Err: ErrNotFound}
+			continue
+		}
+		results[i] = BatchResult{Location: Location{Lat: 48.85, Lon: 2.35, Precision: PrecisionStreet}}
+	}
+	return results, nil
+}
+
+func (p *batchProvider) Name() string {
+	return "Batch"
+}
+
+func TestChainBatch(t *testing.T) {
+	addresses := []Address{
+		{PostalCode: "75000", AddressLocality: "Paris"},
+		{PostalCode: "35000", AddressLocality: "Rennes"},
+	}
+	batcher := &batchProvider{}
+	fallback := &mockProvider{name: "Fallback", precision: PrecisionLocality}
+	chain := &Chain{Strategy: FirstSuccess, Providers: []Provider{batcher, fallback}}
+
+	results := chain.GetCoordinatesBatch(context.Background(), addresses)
+	require.Len(t, results, 2)
+	assert.Equal(t, 1, batcher.batches)
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, PrecisionStreet, results[0].Location.Precision)
+	require.NoError(t, results[1].Err)
+	assert.Equal(t, PrecisionLocality, results[1].Location.Precision)
+	assert.Equal(t, 1, fallback.calls, "only the addresses left are sent to the next provider")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results = chain.GetCoordinatesBatch(ctx, addresses)
+	assert.ErrorIs(t, results[1].Err, context.Canceled)
+}
+
+func TestPolicyBatches(t *testing.T) {
+	addresses := []Address{
+		{PostalCode: "75000", AddressLocality: "Paris"},
+		{PostalCode: "35000", AddressLocality: "Rennes"},
+	}
+	provider := &batchProvider{failures: 1, err: errors.New("timeout")}
+	l, sleeps := withFakeClock(provider, Policy{Retries: 1, RetryDelay: time.Second, DailyQuota: 2})
+
+	results, err := l.GetCoordinatesBatch(context.Background(), addresses)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, 2, provider.batches, "failed batches are retried")
+	assert.Equal(t, []time.Duration{time.Second}, *sleeps)
+	assert.ErrorIs(t, results[1].Err, ErrNotFound)
+
+	_, err = l.GetCoordinatesBatch(context.Background(), addresses)
+	assert.ErrorIs(t, err, ErrQuotaExceeded, "each attempt counts as one request, whatever the batch size")
+}
@@ -1,11 +1,15 @@
 package geocoding
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
+	"tournois-tt/api/pkg/geocoding/google"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // mockProvider implements the Provider interface for testing
@@ -13,18 +17,22 @@ type mockProvider struct {
 	name         string
 	shouldFail   bool
 	errorMessage string
+	precision    Precision
+	calls        int
 }
 
-func (p *mockProvider) GetCoordinates(address Address) (Location, error) {
+func (p *mockProvider) GetCoordinates(ctx context.Context, address Address) (Location, error) {
+	p.calls++
 	if p.shouldFail {
 		return Location{Failed: true}, errors.New(p.errorMessage)
 	}
 
 	// Mock successful geocoding for testing
 	return Location{
-		Lat:    48.8566,
-		Lon:    2.3522,
-		Failed: false,
+		Lat:       48.856614,
+		Lon:       2.3522219,
+		Failed:    false,
+		Precision: p.precision,
 	}, nil
 }
 
@@ -32,29 +40,36 @@ func (p *mockProvider) Name() string {
 	return p.name
 }
 
-// TestGeocodingProviderSingletons verifies that the geocoding providers are initialized as singletons
-func TestGeocodingProviderSingletons(t *testing.T) {
-	// Since nominatimProvider and googleProvider are package variables
-	// initialized in init(), they should be the same instances across multiple calls
-
-	// First verification
-	nominatim1 := nominatimProvider
-	google1 := googleProvider
-
-	assert.NotNil(t, nominatim1, "Nominatim provider should be initialized")
-	assert.NotNil(t, google1, "Google provider should be initialized")
-
-	// Second verification (should be the same instances)
-	nominatim2 := nominatimProvider
-	google2 := googleProvider
-
-	// Verify exact same instances (pointer equality)
-	assert.Equal(t, nominatim1, nominatim2, "Nominatim provider should be a singleton")
-	assert.Equal(t, google1, google2, "Google provider should be a singleton")
-
-	// Verify the provider names
-	assert.Equal(t, "Nominatim", nominatim1.Name(), "Wrong provider name")
-	assert.Equal(t, "Google", google1.Name(), "Wrong provider name")
+// TestRegistryChain verifies the default providers and their order
+func TestRegistryChain(t *testing.T) {
+	settings := map[string]Settings{
+		"nominatim": {Policy: Policy{Enabled: true}},
+		"google":    {Policy: Policy{Enabled: true}},
+	}
+	chain, err := NewRegistry().Chain(FirstSuccess, []string{"google", "nominatim"}, settings, nil)
+	require.NoError(t, err)
+
+	require.Len(t, chain.Providers, 2)
+	assert.Equal(t, "Google", chain.Providers[0].Name(), "Wrong provider name")
+	assert.Equal(t, "Nominatim", chain.Providers[1].Name(), "Wrong provider name")
+
+	// Disabled providers are left out
+	settings["google"] = Settings{}
+	chain, err = NewRegistry().Chain(FirstSuccess, []string{"google", "nominatim"}, settings, nil)
+	require.NoError(t, err)
+	require.Len(t, chain.Providers, 1)
+	assert.Equal(t, "Nominatim", chain.Providers[0].Name())
+
+	_, err = NewRegistry().Chain(FirstSuccess, []string{"unknown"}, settings, nil)
+	assert.ErrorContains(t, err, "unknown geocoding provider")
+	_, err = NewRegistry().Chain("fastest", nil, settings, nil)
+	assert.ErrorContains(t, err, "unknown geocoding strategy")
+
+	registry := NewRegistry()
+	registry.Register("mock", func(Settings) Provider { return &mockProvider{name: "Mock"} })
+	chain, err = registry.Chain(FirstSuccess, []string{"mock"}, map[string]Settings{"mock": {Policy: Policy{Enabled: true}}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Mock", chain.Providers[0].Name())
 }
 
 func TestConstructFullAddress(t *testing.T) {
@@ -217,20 +232,10 @@ func TestIsAddressValid(t *testing.T) {
 }
 
 func TestGetCoordinatesWithMockProvider(t *testing.T) {
-	// Save original providers
-	origNominatimProvider := nominatimProvider
-	origGoogleProvider := googleProvider
-	defer func() {
-		// Restore original providers
-		nominatimProvider = origNominatimProvider
-		googleProvider = origGoogleProvider
-	}()
-
 	// Test case: Both providers succeed
 	t.Run("Nominatim success", func(t *testing.T) {
 		// Set up mock providers
-		nominatimProvider = &mockProvider{name: "Nominatim", shouldFail: false}
-		googleProvider = &mockProvider{name: "Google", shouldFail: false}
+		chain := &Chain{Providers: []Provider{&mockProvider{name: "Nominatim", shouldFail: false}, &mockProvider{name: "Google", shouldFail: false}}}
 
 		address := Address{
 			StreetAddress:   "123 Rue de la Paix",
@@ -238,7 +243,7 @@ func TestGetCoordinatesWithMockProvider(t *testing.T) {
 			AddressLocality: "Paris",
 		}
 
-		location, err := GetCoordinates(address)
+		location, err := chain.GetCoordinates(context.Background(), address)
 
 		assert.NoError(t, err)
 		assert.Equal(t, 48.856614, location.Lat)
@@ -249,8 +254,7 @@ func TestGetCoordinatesWithMockProvider(t *testing.T) {
 	// Test case: Nominatim fails, Google succeeds
 	t.Run("Nominatim fails, Google succeeds", func(t *testing.T) {
 		// Set up mock providers
-		nominatimProvider = &mockProvider{name: "Nominatim", shouldFail: true, errorMessage: "Nominatim error"}
-		googleProvider = &mockProvider{name: "Google", shouldFail: false}
+		chain := &Chain{Providers: []Provider{&mockProvider{name: "Nominatim", shouldFail: true, errorMessage: "Nominatim error"}, &mockProvider{name: "Google", shouldFail: false}}}
 
 		address := Address{
 			StreetAddress:   "123 Rue de la Paix",
@@ -258,7 +262,7 @@ func TestGetCoordinatesWithMockProvider(t *testing.T) {
 			AddressLocality: "Paris",
 		}
 
-		location, err := GetCoordinates(address)
+		location, err := chain.GetCoordinates(context.Background(), address)
 
 		assert.NoError(t, err)
 		assert.Equal(t, 48.856614, location.Lat)
@@ -269,8 +273,7 @@ func TestGetCoordinatesWithMockProvider(t *testing.T) {
 	// Test case: Both providers fail
 	t.Run("Both providers fail", func(t *testing.T) {
 		// Set up mock providers
-		nominatimProvider = &mockProvider{name: "Nominatim", shouldFail: true, errorMessage: "Nominatim error"}
-		googleProvider = &mockProvider{name: "Google", shouldFail: true, errorMessage: "Google error"}
+		chain := &Chain{Providers: []Provider{&mockProvider{name: "Nominatim", shouldFail: true, errorMessage: "Nominatim error"}, &mockProvider{name: "Google", shouldFail: true, errorMessage: "Google error"}}}
 
 		address := Address{
 			StreetAddress:   "123 Rue de la Paix",
@@ -278,10 +281,40 @@ func TestGetCoordinatesWithMockProvider(t *testing.T) {
 			AddressLocality: "Paris",
 		}
 
-		location, err := GetCoordinates(address)
+		location, err := chain.GetCoordinates(context.Background(), address)
 
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "Google error")
 		assert.True(t, location.Failed)
 	})
+
+	// Test case: unconfigured providers are skipped
+	t.Run("Unconfigured provider skipped", func(t *testing.T) {
+		unconfigured := &googleAdapter{provider: google.NewProvider("", nil)}
+		chain := &Chain{Providers: []Provider{unconfigured}}
+
+		_, err := chain.GetCoordinates(context.Background(), Address{PostalCode: "75000", AddressLocality: "Paris"})
+
+		assert.EqualError(t, err, "no geocoding provider")
+	})
+}
+
+func TestBestPrecisionStrategy(t *testing.T) {
+	locality := &mockProvider{name: "Locality", precision: PrecisionLocality}
+	street := &mockProvider{name: "Street", precision: PrecisionStreet}
+	failing := &mockProvider{name: "Failing", shouldFail: true, errorMessage: "down"}
+	otherStreet := &mockProvider{name: "Other street", precision: PrecisionStreet}
+	address := Address{PostalCode: "75000", AddressLocality: "Paris"}
+
+	chain := &Chain{Strategy: BestPrecision, Providers: []Provider{locality, street, failing, otherStreet}}
+	location, err := chain.GetCoordinates(context.Background(), address)
+	require.NoError(t, err)
+	assert.Equal(t, PrecisionStreet, location.Precision)
+	assert.Equal(t, 1, otherStreet.calls, "every provider is queried")
+
+	chain.Strategy = FirstSuccess
+	location, err = chain.GetCoordinates(context.Background(), address)
+	require.NoError(t, err)
+	assert.Equal(t, PrecisionLocality, location.Precision)
+	assert.Equal(t, 1, street.calls, "providers after the first success are not queried")
 }
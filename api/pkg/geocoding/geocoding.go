@@ -1,73 +1,133 @@
 package geocoding
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
 	"tournois-tt/api/pkg/geocoding/google"
 	"tournois-tt/api/pkg/geocoding/nominatim"
+	"tournois-tt/api/pkg/logging"
+	"tournois-tt/api/pkg/metrics"
 )
 
-// Debug enables verbose logging
-var Debug = false
+var logger = logging.For("geocoding")
 
-// debugLog logs a message if Debug is enabled
-func debugLog(format string, args ...interface{}) {
-	if Debug {
-		log.Printf(format, args...)
-	}
-}
+var attempts = metrics.NewCounterVec("tournois_geocoding_attempts_total",
+	"Geocoding attempts by provider and outcome (success or failure)", "provider", "outcome")
 
-// RateLimitDelay is used for respecting Nominatim usage policy (1 request per second)
-const RateLimitDelay = 1500 * time.Millisecond
+// ErrNotFound is returned by providers when no place matches the address. It is not retried.
+var ErrNotFound = errors.New("no coordinates found")
 
-// Provider defines an interface for geocoding providers
+// Provider defines an interface for geocoding providers. Requests are cancelled with ctx.
 type Provider interface {
-	GetCoordinates(address Address) (Location, error)
+	GetCoordinates(ctx context.Context, address Address) (Location, error)
 	Name() string
 }
 
-// nominatimProvider is the cached Nominatim provider instance
-var nominatimProvider Provider
+// Geocoder finds the coordinates of addresses
+type Geocoder interface {
+	GetCoordinates(ctx context.Context, address Address) (Location, error)
+	// GetCoordinatesBatch geocodes several addresses, returning a result per address
+	GetCoordinatesBatch(ctx context.Context, addresses []Address) []BatchResult
+	// Status returns the state of the providers in the order they are tried
+	Status() []ProviderStatus
+}
 
-// googleProvider is the cached Google provider instance
-var googleProvider Provider
+// Strategy is how a chain combines the locations of its providers
+type Strategy string
 
-// GetCoordinatesFunc is a function type for geocoding operations
-type GetCoordinatesFunc func(address Address) (Location, error)
+const (
+	// FirstSuccess returns the location of the first provider that succeeds
+	FirstSuccess Strategy = "first-success"
+	// BestPrecision queries every provider and returns the most precise location, the first
+	// provider winning ties
+	BestPrecision Strategy = "best-precision"
+)
 
-// GetCoordinatesFn is the current implementation of GetCoordinates
-// This can be replaced in tests to mock geocoding
-var GetCoordinatesFn GetCoordinatesFunc = getCoordinatesImpl
+// Strategies are the known strategies
+var Strategies = []Strategy{FirstSuccess, BestPrecision}
 
-// init initializes the geocoding providers
-func init() {
-	nominatimProvider = &nominatimAdapter{provider: nominatim.NewProvider()}
-	googleProvider = &googleAdapter{provider: google.NewProvider()}
+// Chain is a geocoder combining its providers with a strategy. Providers that are not
+// configured are skipped.
+type Chain struct {
+	Strategy  Strategy
+	Providers []Provider
+	// Monitor holds the status of the providers, none when nil
+	Monitor *Monitor
 }
 
-// ConstructFullAddress formats an address into a single string
-func ConstructFullAddress(addr Address) string {
-	parts := []string{}
+// NewNominatimProvider returns the Nominatim (OpenStreetMap) provider
+func NewNominatimProvider(client *http.Client) Provider {
+	return &nominatimAdapter{provider: nominatim.NewProvider(client)}
+}
 
-	if addr.StreetAddress != "" {
-		parts = append(parts, strings.TrimSpace(addr.StreetAddress))
-	}
+// NewGoogleProvider returns the Google Geocoding API provider
+func NewGoogleProvider(apiKey string, client *http.Client) Provider {
+	return &googleAdapter{provider: google.NewProvider(apiKey, client)}
+}
 
-	if addr.PostalCode != "" {
-		parts = append(parts, strings.TrimSpace(addr.PostalCode))
-	}
+// GetCoordinates returns the location chosen by the strategy, or the error of the last
+// provider when none succeeds
+func (c *Chain) GetCoordinates(ctx context.Context, address Address) (Location, error) {
+	err := errors.New("no geocoding provider")
+	var best Location
+	found := false
+	for _, provider := range c.Providers {
+		if p, ok := provider.(configurable); ok && !p.Configured() {
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return Location{Failed: true}, ctx.Err()
+		}
+
+		location, providerErr := provider.GetCoordinates(ctx, address)
+		if providerErr != nil || location.Failed {
+			if providerErr == nil {
+				providerErr = fmt.Errorf("%w for address: %s", ErrNotFound, ConstructFullAddress(address))
+			}
+			err = providerErr
+			continue
+		}
+		if c.Strategy != BestPrecision {
+			return location, nil
+		}
+		if !found || moreAccurate(location, best) {
+			best, found = location, true
+		}
+	}
+	if found {
+		return best, nil
+	}
+	return Location{Failed: true}, err
+}
 
-	if addr.AddressLocality != "" {
-		parts = append(parts, strings.TrimSpace(addr.AddressLocality))
+// moreAccurate reports whether a is more precise than b, or as precise with a higher
+// confidence
+func moreAccurate(a, b Location) bool {
+	if a.Precision != b.Precision {
+		return a.Precision > b.Precision
 	}
+	return a.Confidence > b.Confidence
+}
 
-	if addr.DisambiguatingDescription != "" {
-		parts = append(parts, strings.TrimSpace(addr.DisambiguatingDescription))
+// ConstructFullAddress formats an address into a single string, for logs and errors
+func ConstructFullAddress(addr Address) string {
+	fullAddress := strings.TrimSpace(addr.StreetAddress)
+
+	// Add disambiguating description if street number is not in the address
+	if !strings.Contains(fullAddress, " ") && addr.DisambiguatingDescription != "" {
+		fullAddress = strings.TrimSpace(strings.TrimSpace(addr.DisambiguatingDescription) + " " + fullAddress)
 	}
 
-	return strings.Join(parts, ", ")
+	return fmt.Sprintf("%s, %s %s, France",
+		fullAddress,
+		strings.TrimSpace(addr.PostalCode),
+		strings.TrimSpace(addr.AddressLocality))
 }
 
 // CreateGeocodeResult creates a geocode result from an address and location
@@ -86,93 +146,45 @@ func CreateGeocodeResult(address Address, location Location, err error) GeocodeR
 	result.Longitude = location.Lon
 	result.Failed = location.Failed
 
-	// Update the address with the location data
-	result.Address.Latitude = location.Lat
-	result.Address.Longitude = location.Lon
-	result.Address.Failed = location.Failed
-
 	return result
 }
 
-// GetCoordinates gets coordinates for an address
-func GetCoordinates(address Address) (Location, error) {
-	return getCoordinatesImpl(address)
-}
-
-// getCoordinatesImpl is the implementation of GetCoordinates
-func getCoordinatesImpl(address Address) (Location, error) {
-	// Try with Nominatim first
-	nominatimResult, err := GetCoordinatesNominatim(address)
-	if err == nil && !nominatimResult.Failed {
-		return Location{Lat: nominatimResult.Address.Latitude, Lon: nominatimResult.Address.Longitude}, nil
-	}
-
-	// Fall back to Google as a backup
-	googleResult, err := GetCoordinatesGoogle(address)
-	if err != nil {
-		return Location{Failed: true}, err
-	}
-
-	return Location{Lat: googleResult.Address.Latitude, Lon: googleResult.Address.Longitude, Failed: googleResult.Failed}, nil
-}
-
-// GetCoordinatesNominatim gets coordinates using Nominatim
-func GetCoordinatesNominatim(address Address) (GeocodeResult, error) {
-	// Use the existing nominatimProvider
-	location, err := nominatimProvider.GetCoordinates(address)
-	if err != nil {
-		return GeocodeResult{}, err
-	}
-
-	// Create and return a GeocodeResult
-	return CreateGeocodeResult(address, location, nil), nil
-}
-
-// GetCoordinatesGoogle gets coordinates using Google Maps API
-func GetCoordinatesGoogle(address Address) (GeocodeResult, error) {
-	// Use the existing googleProvider
-	location, err := googleProvider.GetCoordinates(address)
-	if err != nil {
-		return GeocodeResult{}, err
-	}
-
-	// Create and return a GeocodeResult
-	return CreateGeocodeResult(address, location, nil), nil
-}
-
 // nominatimAdapter adapts the Nominatim provider to the Provider interface
 type nominatimAdapter struct {
 	provider *nominatim.Provider
 }
 
 // GetCoordinates implements the Provider interface for Nominatim
-func (a *nominatimAdapter) GetCoordinates(address Address) (Location, error) {
+func (a *nominatimAdapter) GetCoordinates(ctx context.Context, address Address) (Location, error) {
 	// Convert to the provider's address format
 	providerAddress := nominatim.Address{
-		StreetAddress:   address.StreetAddress,
-		PostalCode:      address.PostalCode,
-		AddressLocality: address.AddressLocality,
+		StreetAddress:             address.StreetAddress,
+		PostalCode:                address.PostalCode,
+		AddressLocality:           address.AddressLocality,
+		DisambiguatingDescription: address.DisambiguatingDescription,
 	}
 
 	// Get coordinates from provider
-	result, err := a.provider.GetCoordinates(providerAddress)
+	result, err := a.provider.GetCoordinates(ctx, providerAddress)
+	if errors.Is(err, nominatim.ErrNotFound) {
+		return Location{Failed: true}, fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+	var rateLimited *nominatim.RateLimitError
+	if errors.As(err, &rateLimited) {
+		return Location{Failed: true}, &RateLimitedError{RetryAfter: rateLimited.RetryAfter}
+	}
 	if err != nil {
-		debugLog("Nominatim geocoding error: %v", err)
 		return Location{Failed: true}, err
 	}
 
-	// Convert back to our Location type
-	location := Location{
-		Lat:    result.Lat,
-		Lon:    result.Lon,
-		Failed: false,
+	// Convert back to our Location type, place ranks of 30 being buildings and 26 to 27 streets
+	location := Location{Lat: result.Lat, Lon: result.Lon, Precision: PrecisionLocality}
+	switch {
+	case result.PlaceRank >= 30:
+		location.Precision = PrecisionAddress
+	case result.PlaceRank >= 26:
+		location.Precision = PrecisionStreet
 	}
-
-	debugLog("Nominatim geocoded [%s] to lat:%f, lon:%f",
-		ConstructFullAddress(address),
-		location.Lat,
-		location.Lon)
-
 	return location, nil
 }
 
@@ -187,33 +199,32 @@ type googleAdapter struct {
 }
 
 // GetCoordinates implements the Provider interface for Google
-func (a *googleAdapter) GetCoordinates(address Address) (Location, error) {
+func (a *googleAdapter) GetCoordinates(ctx context.Context, address Address) (Location, error) {
 	// Convert to the provider's address format
 	providerAddress := google.Address{
-		StreetAddress:   address.StreetAddress,
-		PostalCode:      address.PostalCode,
-		AddressLocality: address.AddressLocality,
+		StreetAddress:             address.StreetAddress,
+		PostalCode:                address.PostalCode,
+		AddressLocality:           address.AddressLocality,
+		DisambiguatingDescription: address.DisambiguatingDescription,
 	}
 
 	// Get coordinates from provider
-	result, err := a.provider.GetCoordinates(providerAddress)
+	result, err := a.provider.GetCoordinates(ctx, providerAddress)
+	if errors.Is(err, google.ErrNotFound) {
+		return Location{Failed: true}, fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
 	if err != nil {
-		debugLog("Google geocoding error: %v", err)
 		return Location{Failed: true}, err
 	}
 
 	// Convert back to our Location type
-	location := Location{
-		Lat:    result.Lat,
-		Lon:    result.Lon,
-		Failed: false,
+	location := Location{Lat: result.Lat, Lon: result.Lon, Precision: PrecisionLocality}
+	switch result.LocationType {
+	case "ROOFTOP", "RANGE_INTERPOLATED":
+		location.Precision = PrecisionAddress
+	case "GEOMETRIC_CENTER":
+		location.Precision = PrecisionStreet
 	}
-
-	debugLog("Google geocoded [%s] to lat:%f, lon:%f",
-		ConstructFullAddress(address),
-		location.Lat,
-		location.Lon)
-
 	return location, nil
 }
 
@@ -221,3 +232,8 @@ func (a *googleAdapter) GetCoordinates(address Address) (Location, error) {
 func (a *googleAdapter) Name() string {
 	return "Google"
 }
+
+// Configured reports whether the Google API key is set
+func (a *googleAdapter) Configured() bool {
+	return a.provider.Configured()
+}
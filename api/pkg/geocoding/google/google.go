@@ -2,17 +2,20 @@ package google
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
-	"time"
+
+	"tournois-tt/api/pkg/logging"
 )
 
+var logger = logging.For("geocoding")
+
 // We need to reference the main geocoding package types, so we'll define them here
 // These should match exactly with the main package definitions
 
@@ -30,24 +33,29 @@ type Location struct {
 	Lat    float64
 	Lon    float64
 	Failed bool
+	// LocationType is ROOFTOP, RANGE_INTERPOLATED, GEOMETRIC_CENTER or APPROXIMATE, from
+	// the most to the least precise
+	LocationType string
 }
 
-const (
-	// BaseURL is the Google Geocoding API endpoint
-	BaseURL = "https://maps.googleapis.com/maps/api/geocode/json"
-)
+// BaseURL is the Google Geocoding API endpoint
+const BaseURL = "https://maps.googleapis.com/maps/api/geocode/json"
 
-// httpClient is the client used for HTTP requests
-var httpClient = &http.Client{
-	Timeout: 10 * time.Second,
-}
+// ErrNotFound is returned when no place matches the address
+var ErrNotFound = errors.New("no coordinates found")
 
 // Provider implements the geocoding provider interface for Google
-type Provider struct{}
+type Provider struct {
+	// URL is the geocoding endpoint, BaseURL unless testing
+	URL    string
+	apiKey string
+	client *http.Client
+}
 
-// NewProvider creates a new Google geocoding provider
-func NewProvider() *Provider {
-	return &Provider{}
+// NewProvider creates a new Google geocoding provider sending its requests with client,
+// disabled without an API key
+func NewProvider(apiKey string, client *http.Client) *Provider {
+	return &Provider{URL: BaseURL, apiKey: apiKey, client: client}
 }
 
 // Name returns the provider name
@@ -55,6 +63,11 @@ func (p *Provider) Name() string {
 	return "Google"
 }
 
+// Configured reports whether the API key is set
+func (p *Provider) Configured() bool {
+	return p.apiKey != ""
+}
+
 // constructFullAddress creates a standardized address string for geocoding
 func constructFullAddress(addr Address) string {
 	fullAddress := addr.StreetAddress
@@ -74,15 +87,14 @@ func constructFullAddress(addr Address) string {
 }
 
 // GetCoordinates geocodes an address using Google Geocoding API
-func (p *Provider) GetCoordinates(address Address) (Location, error) {
+func (p *Provider) GetCoordinates(ctx context.Context, address Address) (Location, error) {
 	fullAddress := constructFullAddress(address)
-	return p.geocodeWithGoogle(fullAddress)
+	return p.geocodeWithGoogle(ctx, fullAddress)
 }
 
 // geocodeWithGoogle attempts to geocode an address using Google Geocoding API
-func (p *Provider) geocodeWithGoogle(fullAddress string) (Location, error) {
-	// Get API key from environment
-	apiKey := os.Getenv("GOOGLE_GEOCODING_API_KEY")
+func (p *Provider) geocodeWithGoogle(ctx context.Context, fullAddress string) (Location, error) {
+	apiKey := p.apiKey
 	if apiKey == "" {
 		return Location{Failed: true}, fmt.Errorf("Google Geocoding API key not set")
 	}
@@ -93,13 +105,13 @@ func (p *Provider) geocodeWithGoogle(fullAddress string) (Location, error) {
 	params.Add("key", apiKey)
 
 	// Create request
-	req, err := http.NewRequest("GET", BaseURL+"?"+params.Encode(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", p.URL+"?"+params.Encode(), nil)
 	if err != nil {
 		return Location{Failed: true}, fmt.Errorf("request creation error: %v", err)
 	}
 
 	// Send request
-	resp, err := httpClient.Do(req)
+	resp, err := p.client.Do(req)
 	if err != nil {
 		return Location{Failed: true}, fmt.Errorf("network error: %v", err)
 	}
@@ -127,6 +139,7 @@ func (p *Provider) geocodeWithGoogle(fullAddress string) (Location, error) {
 					Lat float64 `json:"lat"`
 					Lng float64 `json:"lng"`
 				} `json:"location"`
+				LocationType string `json:"location_type"`
 			} `json:"geometry"`
 			Status string `json:"status"`
 		} `json:"results"`
@@ -139,18 +152,20 @@ func (p *Provider) geocodeWithGoogle(fullAddress string) (Location, error) {
 	}
 
 	// Check response status
-	if googleResp.Status != "OK" || len(googleResp.Results) == 0 {
-		return Location{Failed: true}, fmt.Errorf("no coordinates found for address: %s", fullAddress)
+	if googleResp.Status == "ZERO_RESULTS" || (googleResp.Status == "OK" && len(googleResp.Results) == 0) {
+		return Location{Failed: true}, fmt.Errorf("%w for address: %s", ErrNotFound, fullAddress)
+	}
+	if googleResp.Status != "OK" {
+		return Location{Failed: true}, fmt.Errorf("API error %s: %s", googleResp.Status, googleResp.Error)
 	}
 
-	log.Printf("Geocoded address with Google: %s -> (%.6f, %.6f)",
-		fullAddress,
-		googleResp.Results[0].Geometry.Location.Lat,
-		googleResp.Results[0].Geometry.Location.Lng)
+	logger.Info("Geocoded address", "provider", "google", "address", fullAddress,
+		"lat", googleResp.Results[0].Geometry.Location.Lat, "lon", googleResp.Results[0].Geometry.Location.Lng)
 
 	return Location{
-		Lat:    googleResp.Results[0].Geometry.Location.Lat,
-		Lon:    googleResp.Results[0].Geometry.Location.Lng,
-		Failed: false,
+		Lat:          googleResp.Results[0].Geometry.Location.Lat,
+		Lon:          googleResp.Results[0].Geometry.Location.Lng,
+		Failed:       false,
+		LocationType: googleResp.Results[0].Geometry.LocationType,
 	}, nil
 }
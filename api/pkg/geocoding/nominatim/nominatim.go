@@ -1,17 +1,22 @@
 package nominatim
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
-	"math/rand"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"time"
+
+	"tournois-tt/api/pkg/logging"
+	"tournois-tt/api/pkg/utils"
 )
 
+var logger = logging.For("geocoding")
+
 // We need to reference the main geocoding package types, so we'll define them here
 // These should match exactly with the main package definitions
 
@@ -29,33 +34,37 @@ type Location struct {
 	Lat    float64
 	Lon    float64
 	Failed bool
+	// PlaceRank is 30 for buildings and house numbers, 26 to 27 for streets and lower for
+	// larger areas
+	PlaceRank int
 }
 
-const (
-	// RateLimitDelay respects Nominatim usage policy (1 request per second with buffer)
-	RateLimitDelay = 1500 * time.Millisecond
+// BaseURL is the Nominatim API endpoint
+const BaseURL = "https://nominatim.openstreetmap.org/search"
 
-	// BaseURL is the Nominatim API endpoint
-	BaseURL = "https://nominatim.openstreetmap.org/search"
+// ErrNotFound is returned when no place matches the address
+var ErrNotFound = errors.New("no coordinates found")
 
-	// DefaultMaxRetries defines how many times to retry on failure
-	DefaultMaxRetries = 3
-
-	// RetryDelay defines the base delay between retry attempts
-	RetryDelay = 5 * time.Second
-)
+// RateLimitError is returned when Nominatim asks to slow down, for RetryAfter when it says
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
 
-// httpClient is the client used for HTTP requests
-var httpClient = &http.Client{
-	Timeout: 10 * time.Second,
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s", e.RetryAfter)
 }
 
-// Provider implements the geocoding provider interface for Nominatim
-type Provider struct{}
+// Provider implements the geocoding provider interface for Nominatim. Rate limiting, as
+// required by the Nominatim usage policy, and retries are left to the caller.
+type Provider struct {
+	// URL is the search endpoint, BaseURL unless testing
+	URL    string
+	client *http.Client
+}
 
-// NewProvider creates a new Nominatim geocoding provider
-func NewProvider() *Provider {
-	return &Provider{}
+// NewProvider creates a new Nominatim geocoding provider sending its requests with client
+func NewProvider(client *http.Client) *Provider {
+	return &Provider{URL: BaseURL, client: client}
 }
 
 // Name returns the provider name
@@ -82,106 +91,54 @@ func constructFullAddress(addr Address) string {
 }
 
 // GetCoordinates geocodes an address using Nominatim
-func (p *Provider) GetCoordinates(address Address) (Location, error) {
+func (p *Provider) GetCoordinates(ctx context.Context, address Address) (Location, error) {
 	fullAddress := constructFullAddress(address)
 
-	// Prepare Nominatim parameters
 	params := url.Values{}
 	params.Add("q", fullAddress)
-	params.Add("format", "json")
+	params.Add("format", "jsonv2")
 	params.Add("limit", "1")
 
-	// Rate limit to respect Nominatim usage policy
-	time.Sleep(RateLimitDelay)
-
-	return p.geocodeWithRetry(fullAddress, params)
-}
-
-// geocodeWithRetry attempts to geocode an address with multiple retry attempts
-func (p *Provider) geocodeWithRetry(fullAddress string, params url.Values) (Location, error) {
-	var lastErr error
-
-	for attempt := 0; attempt < DefaultMaxRetries; attempt++ {
-		// Add User-Agent to respect Nominatim usage policy
-		req, err := http.NewRequest("GET", BaseURL+"?"+params.Encode(), nil)
-		if err != nil {
-			lastErr = fmt.Errorf("request creation error: %v", err)
-			time.Sleep(RetryDelay)
-			continue
-		}
-		req.Header.Set("Accept-Language", "fr") // Add French language preference
-
-		resp, err := httpClient.Do(req)
-		if err != nil {
-			lastErr = fmt.Errorf("network error on attempt %d: %v", attempt+1, err)
-
-			// Exponential backoff with jitter
-			backoff := RetryDelay * time.Duration(attempt+1)
-			jitter := time.Duration(float64(backoff) * (0.5 + rand.Float64())) // Add 50-150% jitter
-			time.Sleep(jitter)
-			continue
-		}
-		defer resp.Body.Close()
-
-		// Check for rate limiting response
-		if resp.StatusCode == http.StatusTooManyRequests {
-			// Get retry-after header or use default
-			retryAfter := 60 * time.Second
-			if retryHeader := resp.Header.Get("Retry-After"); retryHeader != "" {
-				if seconds, err := strconv.Atoi(retryHeader); err == nil {
-					retryAfter = time.Duration(seconds) * time.Second
-				}
-			}
-			time.Sleep(retryAfter)
-			continue
-		}
-
-		// Check for other HTTP errors
-		if resp.StatusCode != http.StatusOK {
-			lastErr = fmt.Errorf("HTTP error: %s", resp.Status)
-			time.Sleep(RetryDelay * time.Duration(attempt+1))
-			continue
-		}
-
-		var results []struct {
-			Lat string `json:"lat"`
-			Lon string `json:"lon"`
-		}
-
-		if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
-			lastErr = fmt.Errorf("parsing error: %v", err)
-			time.Sleep(RetryDelay)
-			continue
-		}
+	req, err := http.NewRequestWithContext(ctx, "GET", p.URL+"?"+params.Encode(), nil)
+	if err != nil {
+		return Location{Failed: true}, fmt.Errorf("request creation error: %v", err)
+	}
+	req.Header.Set("Accept-Language", "fr") // Add French language preference
 
-		if len(results) == 0 {
-			lastErr = fmt.Errorf("no coordinates found for address: %s", fullAddress)
-			break
-		}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Location{Failed: true}, fmt.Errorf("network error: %v", err)
+	}
+	defer resp.Body.Close()
 
-		// Parse coordinates
-		var lat, lon float64
-		_, err = fmt.Sscanf(results[0].Lat, "%f", &lat)
-		if err != nil {
-			lastErr = fmt.Errorf("invalid latitude: %v", err)
-			time.Sleep(RetryDelay)
-			continue
-		}
-		_, err = fmt.Sscanf(results[0].Lon, "%f", &lon)
-		if err != nil {
-			lastErr = fmt.Errorf("invalid longitude: %v", err)
-			time.Sleep(RetryDelay)
-			continue
-		}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return Location{Failed: true}, &RateLimitError{RetryAfter: utils.RetryAfter(resp.Header.Get("Retry-After"), time.Now())}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Location{Failed: true}, fmt.Errorf("HTTP error: %s", resp.Status)
+	}
 
-		log.Printf("Geocoded address with Nominatim: %s -> (%.6f, %.6f)", fullAddress, lat, lon)
+	var results []struct {
+		Lat       string `json:"lat"`
+		Lon       string `json:"lon"`
+		PlaceRank int    `json:"place_rank"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return Location{Failed: true}, fmt.Errorf("parsing error: %v", err)
+	}
+	if len(results) == 0 {
+		return Location{Failed: true}, fmt.Errorf("%w for address: %s", ErrNotFound, fullAddress)
+	}
 
-		return Location{
-			Lat:    lat,
-			Lon:    lon,
-			Failed: false,
-		}, nil
+	lat, err := strconv.ParseFloat(results[0].Lat, 64)
+	if err != nil {
+		return Location{Failed: true}, fmt.Errorf("invalid latitude: %v", err)
+	}
+	lon, err := strconv.ParseFloat(results[0].Lon, 64)
+	if err != nil {
+		return Location{Failed: true}, fmt.Errorf("invalid longitude: %v", err)
 	}
 
-	return Location{Failed: true}, lastErr
+	logger.Info("Geocoded address", "provider", "nominatim", "address", fullAddress, "lat", lat, "lon", lon)
+	return Location{Lat: lat, Lon: lon, PlaceRank: results[0].PlaceRank}, nil
 }
@@ -0,0 +1,156 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExceeded is returned when a provider has used its daily quota
+var ErrQuotaExceeded = errors.New("daily geocoding quota exceeded")
+
+// Policy limits the requests sent to a provider
+type Policy struct {
+	// Enabled providers are part of the chain
+	Enabled bool
+	// Interval is the minimum delay between two requests
+	Interval time.Duration
+	// Timeout bounds each request
+	Timeout time.Duration
+	// Retries are made after failures other than ErrNotFound, waiting RetryDelay, then
+	// twice as long before each new retry
+	Retries    int
+	RetryDelay time.Duration
+	// DailyQuota is the maximum number of requests per day, unlimited when 0
+	DailyQuota int
+}
+
+// RateLimitedError is returned by providers asked to slow down. The next request waits
+// RetryAfter at least.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s", e.RetryAfter)
+}
+
+// limited is a provider applying a policy
+type limited struct {
+	Provider
+	policy  Policy
+	monitor *Monitor
+
+	// now and sleep are replaced by tests
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+
+	mu        sync.Mutex
+	last      time.Time
+	notBefore time.Time
+	day       string
+	used      int
+}
+
+// WithPolicy returns provider spacing, retrying and counting its requests as set by policy.
+// Its outcomes are recorded by monitor, when not nil.
+func WithPolicy(provider Provider, policy Policy, monitor *Monitor) Provider {
+	return &limited{Provider: provider, policy: policy, monitor: monitor, now: time.Now, sleep: sleep}
+}
+
+// sleep waits for d, or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GetCoordinates geocodes address, retrying failures
+func (l *limited) GetCoordinates(ctx context.Context, address Address) (Location, error) {
+	var err error
+	for attempt := 0; attempt <= l.policy.Retries; attempt++ {
+		if attempt > 0 {
+			if err := l.sleep(ctx, l.policy.RetryDelay<<(attempt-1)); err != nil {
+				return Location{Failed: true}, err
+			}
+		}
+		if err := l.take(ctx); err != nil {
+			return Location{Failed: true}, err
+		}
+
+		var location Location
+		location, err = l.Provider.GetCoordinates(ctx, address)
+		if err == nil && !location.Failed {
+			l.monitor.record(l.Name(), nil)
+			return location, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("%w for address: %s", ErrNotFound, ConstructFullAddress(address))
+		}
+		logger.Debug("Geocoding failed", "provider", l.Name(), "attempt", attempt+1, "error", err)
+		if errors.Is(err, ErrNotFound) || ctx.Err() != nil {
+			break
+		}
+		var rateLimited *RateLimitedError
+		if errors.As(err, &rateLimited) {
+			l.backOff(rateLimited.RetryAfter)
+		}
+	}
+	l.monitor.record(l.Name(), err)
+	return Location{Failed: true}, err
+}
+
+// take counts a request against the daily quota and waits for its turn: the interval
+// since the previous request, and the delay asked by the provider
+func (l *limited) take(ctx context.Context) error {
+	l.mu.Lock()
+	now := l.now()
+	if day := now.Format("2006-01-02"); day != l.day {
+		l.day, l.used = day, 0
+	}
+	if l.policy.DailyQuota > 0 && l.used >= l.policy.DailyQuota {
+		l.mu.Unlock()
+		return fmt.Errorf("%s: %w (%d requests)", l.Name(), ErrQuotaExceeded, l.policy.DailyQuota)
+	}
+
+	// The turn is reserved before waiting, so that concurrent requests queue behind it
+	turn := now
+	if next := l.last.Add(l.policy.Interval); next.After(turn) {
+		turn = next
+	}
+	if l.notBefore.After(turn) {
+		turn = l.notBefore
+	}
+	l.last = turn
+	l.used++
+	l.mu.Unlock()
+
+	if wait := turn.Sub(now); wait > 0 {
+		return l.sleep(ctx, wait)
+	}
+	return nil
+}
+
+// backOff delays the next request by d
+func (l *limited) backOff(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := l.now().Add(d); until.After(l.notBefore) {
+		l.notBefore = until
+	}
+}
+
+// Configured reports whether the provider has its credentials
+func (l *limited) Configured() bool {
+	if p, ok := l.Provider.(configurable); ok {
+		return p.Configured()
+	}
+	return true
+}
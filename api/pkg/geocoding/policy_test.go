@@ -0,0 +1,132 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyProvider fails its first calls with err
+type flakyProvider struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (p *flakyProvider) GetCoordinates(context.Context, Address) (Location, error) {
+	p.calls++
+	if p.calls <= p.failures {
+		return Location{Failed: true}, p.err
+	}
+	return Location{Lat: 48.11, Lon: -1.68}, nil
+}
+
+func (p *flakyProvider) Name() string {
+	return "Flaky"
+}
+
+// withFakeClock applies policy to provider with a clock advanced by sleeps, which are
+// returned
+func withFakeClock(provider Provider, policy Policy) (*limited, *[]time.Duration) {
+	l := WithPolicy(provider, policy, nil).(*limited)
+	now := time.Date(2026, 3, 14, 23, 59, 0, 0, time.UTC)
+	var sleeps []time.Duration
+	l.now = func() time.Time { return now }
+	l.sleep = func(_ context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		now = now.Add(d)
+		return nil
+	}
+	return l, &sleeps
+}
+
+func TestPolicyRetries(t *testing.T) {
+	address := Address{PostalCode: "35000", AddressLocality: "Rennes"}
+
+	provider := &flakyProvider{failures: 2, err: errors.New("timeout")}
+	l, sleeps := withFakeClock(provider, Policy{Retries: 2, RetryDelay: time.Second})
+	location, err := l.GetCoordinates(context.Background(), address)
+	require.NoError(t, err)
+	assert.Equal(t, 48.11, location.Lat)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, *sleeps, "the delay doubles")
+
+	provider = &flakyProvider{failures: 3, err: errors.New("timeout")}
+	l, _ = withFakeClock(provider, Policy{Retries: 1})
+	_, err = l.GetCoordinates(context.Background(), address)
+	assert.EqualError(t, err, "timeout")
+	assert.Equal(t, 2, provider.calls)
+
+	provider = &flakyProvider{failures: 3, err: fmt.Errorf("%w: nothing in Rennes", ErrNotFound)}
+	l, _ = withFakeClock(provider, Policy{Retries: 2})
+	_, err = l.GetCoordinates(context.Background(), address)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, 1, provider.calls, "unknown addresses are not retried")
+}
+
+func TestPolicyIntervalAndQuota(t *testing.T) {
+	address := Address{PostalCode: "35000", AddressLocality: "Rennes"}
+	provider := &flakyProvider{}
+	l, sleeps := withFakeClock(provider, Policy{Interval: 1500 * time.Millisecond, DailyQuota: 2})
+
+	for i := 0; i < 2; i++ {
+		_, err := l.GetCoordinates(context.Background(), address)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []time.Duration{1500 * time.Millisecond}, *sleeps, "only the second request waits")
+
+	_, err := l.GetCoordinates(context.Background(), address)
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	assert.Equal(t, 2, provider.calls)
+
+	// The quota is reset the next day
+	l.sleep(context.Background(), time.Minute)
+	_, err = l.GetCoordinates(context.Background(), address)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, provider.calls)
+}
+
+func TestPolicyWaitsForRetryAfter(t *testing.T) {
+	address := Address{PostalCode: "35000", AddressLocality: "Rennes"}
+	provider := &flakyProvider{failures: 1, err: &RateLimitedError{RetryAfter: 30 * time.Second}}
+	l, sleeps := withFakeClock(provider, Policy{Retries: 1, RetryDelay: time.Second})
+
+	_, err := l.GetCoordinates(context.Background(), address)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{time.Second, 29 * time.Second}, *sleeps,
+		"the retry waits for the delay asked by the provider")
+}
+
+func TestPolicySleepsAreCancelled(t *testing.T) {
+	address := Address{PostalCode: "35000", AddressLocality: "Rennes"}
+	provider := &flakyProvider{failures: 1, err: errors.New("timeout")}
+	l := WithPolicy(provider, Policy{Retries: 1, RetryDelay: time.Hour}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := l.GetCoordinates(ctx, address)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, provider.calls)
+}
+
+func TestNominatimRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	provider := NewNominatimProvider(server.Client()).(*nominatimAdapter)
+	provider.provider.URL = server.URL
+	_, err := provider.GetCoordinates(context.Background(), Address{PostalCode: "35000", AddressLocality: "Rennes"})
+
+	var rateLimited *RateLimitedError
+	require.ErrorAs(t, err, &rateLimited)
+	assert.Equal(t, 2*time.Minute, rateLimited.RetryAfter)
+}
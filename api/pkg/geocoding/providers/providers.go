@@ -0,0 +1,15 @@
+// Package providers lists the geocoding providers, so that the configuration accepts the
+// providers the application can build
+package providers
+
+import (
+	"tournois-tt/api/pkg/geocoding"
+	"tournois-tt/api/pkg/geocoding/ban"
+)
+
+// Registry returns a registry of every provider: nominatim, google and ban
+func Registry() *geocoding.Registry {
+	registry := geocoding.NewRegistry()
+	registry.Register("ban", ban.Factory)
+	return registry
+}
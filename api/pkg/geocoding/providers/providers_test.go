@@ -0,0 +1,11 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	assert.Equal(t, []string{"ban", "google", "nominatim"}, Registry().Names())
+}
@@ -0,0 +1,90 @@
+package geocoding
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+)
+
+// Settings configure a provider of a registry
+type Settings struct {
+	Policy Policy
+	// APIKey is required by some providers
+	APIKey string
+	// URL replaces the endpoint of the provider, such as a self-hosted instance
+	URL string
+	// MinScore is the minimum score of the results of providers scoring them, from 0 to 1
+	MinScore float64
+}
+
+// Client returns an HTTP client with the timeout of the policy
+func (s Settings) Client() *http.Client {
+	return &http.Client{Timeout: s.Policy.Timeout}
+}
+
+// Factory creates a provider from its settings. The registry applies the policy.
+type Factory func(Settings) Provider
+
+// Registry builds chains of providers by name
+type Registry struct {
+	factories map[string]Factory
+}
+
+// NewRegistry returns a registry with the nominatim and google providers
+func NewRegistry() *Registry {
+	r := &Registry{factories: make(map[string]Factory)}
+	r.Register("nominatim", func(s Settings) Provider {
+		adapter := NewNominatimProvider(s.Client()).(*nominatimAdapter)
+		if s.URL != "" {
+			adapter.provider.URL = s.URL
+		}
+		return adapter
+	})
+	r.Register("google", func(s Settings) Provider {
+		adapter := NewGoogleProvider(s.APIKey, s.Client()).(*googleAdapter)
+		if s.URL != "" {
+			adapter.provider.URL = s.URL
+		}
+		return adapter
+	})
+	return r
+}
+
+// Register adds a provider, replacing any provider of the same name
+func (r *Registry) Register(name string, factory Factory) {
+	r.factories[name] = factory
+}
+
+// Names returns the names of the registered providers, sorted
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Chain returns a chain of the providers named by order, with their settings, recording
+// their status in monitor. Disabled providers are left out.
+func (r *Registry) Chain(strategy Strategy, order []string, settings map[string]Settings, monitor *Monitor) (*Chain, error) {
+	if !slices.Contains(Strategies, strategy) {
+		return nil, fmt.Errorf("unknown geocoding strategy %q", strategy)
+	}
+
+	chain := &Chain{Strategy: strategy, Monitor: monitor}
+	for _, name := range order {
+		factory, ok := r.factories[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown geocoding provider %q, expected one of %v", name, r.Names())
+		}
+		s := settings[name]
+		if !s.Policy.Enabled {
+			logger.Debug("Geocoding provider disabled", "provider", name)
+			continue
+		}
+		chain.Providers = append(chain.Providers, WithPolicy(factory(s), s.Policy, monitor))
+	}
+	return chain, nil
+}
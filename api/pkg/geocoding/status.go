@@ -0,0 +1,100 @@
+package geocoding
+
+import (
+	"sync"
+	"time"
+
+	"tournois-tt/api/pkg/alerting"
+)
+
+// ProviderStatus is the state of a geocoding provider as seen by its last attempts
+type ProviderStatus struct {
+	Name        string     `json:"name"`
+	Configured  bool       `json:"configured"`
+	Available   bool       `json:"available"`
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+	LastFailure *time.Time `json:"lastFailure,omitempty"`
+}
+
+// configurable is implemented by providers that need credentials
+type configurable interface {
+	Configured() bool
+}
+
+// Monitor remembers the outcome of the attempts of each provider, and alerts on sustained
+// failures
+type Monitor struct {
+	mu       sync.Mutex
+	alerts   *alerting.Dispatcher
+	statuses map[string]*ProviderStatus
+	trackers map[string]*failureTracker
+}
+
+// NewMonitor returns a monitor alerting through alerts, which may be nil
+func NewMonitor(alerts *alerting.Dispatcher) *Monitor {
+	return &Monitor{
+		alerts:   alerts,
+		statuses: make(map[string]*ProviderStatus),
+		trackers: make(map[string]*failureTracker),
+	}
+}
+
+// record counts a geocoding attempt and remembers its outcome. Nothing is remembered
+// without a monitor.
+func (m *Monitor) record(provider string, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	attempts.With(provider, outcome).Inc()
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status, ok := m.statuses[provider]
+	if !ok {
+		status = &ProviderStatus{Name: provider}
+		m.statuses[provider] = status
+	}
+	m.trackFailures(provider, err != nil)
+
+	now := time.Now()
+	if err != nil {
+		status.LastFailure = &now
+	} else {
+		status.LastSuccess = &now
+	}
+}
+
+// Status returns the state of the providers in the order they are tried. A configured
+// provider is available unless its last attempt failed.
+func (c *Chain) Status() []ProviderStatus {
+	recorded := make(map[string]ProviderStatus)
+	if m := c.Monitor; m != nil {
+		m.mu.Lock()
+		for name, status := range m.statuses {
+			recorded[name] = *status
+		}
+		m.mu.Unlock()
+	}
+
+	result := make([]ProviderStatus, 0, len(c.Providers))
+	for _, provider := range c.Providers {
+		status, ok := recorded[provider.Name()]
+		if !ok {
+			status = ProviderStatus{Name: provider.Name()}
+		}
+
+		status.Configured = true
+		if p, ok := provider.(configurable); ok {
+			status.Configured = p.Configured()
+		}
+		status.Available = status.Configured &&
+			(status.LastFailure == nil || (status.LastSuccess != nil && status.LastSuccess.After(*status.LastFailure)))
+		result = append(result, status)
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+package geocoding
+
+import (
+	"errors"
+	"testing"
+
+	"tournois-tt/api/pkg/alerting"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusFollowsLastAttempt(t *testing.T) {
+	monitor := NewMonitor(nil)
+	chain, err := NewRegistry().Chain(FirstSuccess, []string{"nominatim", "google"}, map[string]Settings{
+		"nominatim": {Policy: Policy{Enabled: true}},
+		"google":    {Policy: Policy{Enabled: true}},
+	}, monitor)
+	require.NoError(t, err)
+
+	status := chain.Status()
+	require.Len(t, status, 2)
+	assert.Equal(t, "Nominatim", status[0].Name)
+	assert.True(t, status[0].Available, "providers are available until they fail")
+	assert.Equal(t, "Google", status[1].Name)
+	assert.False(t, status[1].Configured)
+	assert.False(t, status[1].Available)
+
+	monitor.record("Nominatim", errors.New("timeout"))
+	assert.False(t, chain.Status()[0].Available)
+
+	monitor.record("Nominatim", nil)
+	status = chain.Status()
+	assert.True(t, status[0].Available)
+	assert.NotNil(t, status[0].LastFailure)
+	assert.NotNil(t, status[0].LastSuccess)
+}
+
+func TestSustainedFailuresAlert(t *testing.T) {
+	recorder := &alerting.Recorder{}
+	alerts := alerting.NewDispatcher()
+	t.Cleanup(alerts.Close)
+	alerts.AddChannel("test", recorder)
+	alerts.Route(alerting.SeverityWarning, "test")
+
+	m := NewMonitor(alerts)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := 0; i < failureMinimum-1; i++ {
+		m.trackFailures("Test", true)
+	}
+	alerts.Wait()
+	assert.Empty(t, recorder.Alerts(), "too few attempts to alert")
+
+	m.trackFailures("Test", true)
+	for i := 0; i < failureWindow; i++ {
+		m.trackFailures("Test", i%2 == 0)
+	}
+	alerts.Wait()
+	require.Len(t, recorder.Alerts(), 1)
+	assert.Equal(t, "geocoding.Test", recorder.Alerts()[0].Key)
+
+	for i := 0; i < failureWindow; i++ {
+		m.trackFailures("Test", false)
+	}
+	alerts.Wait()
+	require.Len(t, recorder.Alerts(), 2)
+	assert.True(t, recorder.Alerts()[1].Resolved)
+}
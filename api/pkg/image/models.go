@@ -2,22 +2,15 @@ package image
 
 // TournamentImage holds the data required to render an Instagram-ready image
 type TournamentImage struct {
-    Name          string
-    Type          string
-    Club          string
-    Endowment     int
-    StartDate     string
-    EndDate       string
-    Address       string
-    RulesURL      string
-    Page          string
-    TournamentID  int
-    TournamentURL string
+	Name          string
+	Type          string
+	Club          string
+	Endowment     int
+	StartDate     string
+	EndDate       string
+	Address       string
+	RulesURL      string
+	Page          string
+	TournamentID  int
+	TournamentURL string
 }
-
-
-
-
-
-
-
@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotatingFile writes logs to one file per UTC day, <dir>/<name>-YYYY-MM-DD.log, and
+// deletes the files older than the retention period
+type RotatingFile struct {
+	dir       string
+	name      string
+	retention int
+	now       func() time.Time
+
+	mu   sync.Mutex
+	day  string
+	file *os.File
+}
+
+// NewRotatingFile returns a sink in dir keeping retentionDays days of logs
+func NewRotatingFile(dir, name string, retentionDays int) (*RotatingFile, error) {
+	if retentionDays < 1 {
+		return nil, fmt.Errorf("log retention must be at least one day")
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %v", err)
+	}
+	return &RotatingFile{dir: dir, name: name, retention: retentionDays, now: time.Now}, nil
+}
+
+// Write appends to the file of the current day, rotating when the day changes
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if day := f.now().UTC().Format(time.DateOnly); day != f.day || f.file == nil {
+		if err := f.rotate(day); err != nil {
+			return 0, err
+		}
+	}
+	return f.file.Write(p)
+}
+
+// Close closes the current file
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// rotate opens the file of day and purges expired files. Callers hold f.mu.
+func (f *RotatingFile) rotate(day string) error {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+
+	file, err := os.OpenFile(filepath.Join(f.dir, f.name+"-"+day+".log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+	f.file, f.day = file, day
+
+	f.purge()
+	return nil
+}
+
+// purge deletes the files of days before the retention period. Callers hold f.mu.
+func (f *RotatingFile) purge() {
+	oldest := f.now().UTC().AddDate(0, 0, -(f.retention - 1)).Format(time.DateOnly)
+
+	paths, _ := filepath.Glob(filepath.Join(f.dir, f.name+"-*.log"))
+	for _, path := range paths {
+		day := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), f.name+"-"), ".log")
+		if _, err := time.Parse(time.DateOnly, day); err != nil {
+			continue
+		}
+		if day < oldest {
+			os.Remove(path)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFileRotatesAndPurges(t *testing.T) {
+	dir := t.TempDir()
+	expired := filepath.Join(dir, "api-2025-09-28.log")
+	unrelated := filepath.Join(dir, "other-2025-01-01.log")
+	require.NoError(t, os.WriteFile(expired, []byte("old\n"), 0640))
+	require.NoError(t, os.WriteFile(unrelated, []byte("keep\n"), 0640))
+
+	f, err := NewRotatingFile(dir, "api", 3)
+	require.NoError(t, err)
+	defer f.Close()
+
+	now := time.Date(2025, 10, 1, 23, 0, 0, 0, time.UTC)
+	f.now = func() time.Time { return now }
+
+	_, err = f.Write([]byte("first\n"))
+	require.NoError(t, err)
+	assert.NoFileExists(t, expired)
+	assert.FileExists(t, unrelated)
+
+	now = now.Add(2 * time.Hour)
+	_, err = f.Write([]byte("second\n"))
+	require.NoError(t, err)
+
+	first, err := os.ReadFile(filepath.Join(dir, "api-2025-10-01.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "first\n", string(first))
+	second, err := os.ReadFile(filepath.Join(dir, "api-2025-10-02.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "second\n", string(second))
+
+	// Three days later, only the last three days are kept
+	now = now.Add(72 * time.Hour)
+	_, err = f.Write([]byte("third\n"))
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(dir, "api-2025-10-01.log"))
+	assert.NoFileExists(t, filepath.Join(dir, "api-2025-10-02.log"))
+	assert.FileExists(t, filepath.Join(dir, "api-2025-10-05.log"))
+
+	_, err = NewRotatingFile(dir, "api", 0)
+	assert.Error(t, err)
+}
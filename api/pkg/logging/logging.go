@@ -0,0 +1,170 @@
+// Package logging configures structured logging with log/slog. Each package logs through
+// its own logger so that levels can be tuned per package, and request IDs carried by
+// contexts are added to every record.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// defaultRetentionDays is how long log files are kept when LOG_RETENTION_DAYS is not set
+const defaultRetentionDays = 30
+
+// config is the active output and levels
+type config struct {
+	handler slog.Handler
+	level   slog.Level
+	levels  map[string]slog.Level
+}
+
+var active atomic.Pointer[config]
+
+func init() {
+	Configure(os.Stderr, "text", slog.LevelInfo, nil)
+}
+
+// Options configure logging
+type Options struct {
+	// Format is json or text
+	Format string
+	// Level is debug, info, warn or error, info by default
+	Level string
+	// Levels are per package levels, e.g. "fftt=debug,geocoding=warn"
+	Levels string
+	// Dir, when set, also receives the logs in daily files
+	Dir string
+	// RetentionDays is the number of days of log files kept in Dir, 30 by default
+	RetentionDays int
+}
+
+// Setup configures logging
+func Setup(opts Options) error {
+	format := opts.Format
+	if format == "" {
+		format = "text"
+	}
+
+	level := slog.LevelInfo
+	if opts.Level != "" {
+		if err := level.UnmarshalText([]byte(opts.Level)); err != nil {
+			return fmt.Errorf("invalid LOG_LEVEL %q", opts.Level)
+		}
+	}
+
+	levels, err := ParseLevels(opts.Levels)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stderr
+	if opts.Dir != "" {
+		retention := opts.RetentionDays
+		if retention <= 0 {
+			retention = defaultRetentionDays
+		}
+
+		file, err := NewRotatingFile(opts.Dir, "api", retention)
+		if err != nil {
+			return err
+		}
+		w = io.MultiWriter(os.Stderr, file)
+	}
+
+	Configure(w, format, level, levels)
+	return nil
+}
+
+// ParseLevels parses a comma separated list of package=level pairs
+func ParseLevels(value string) (map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, levelName, ok := strings.Cut(pair, "=")
+		var level slog.Level
+		if !ok || level.UnmarshalText([]byte(strings.TrimSpace(levelName))) != nil {
+			return nil, fmt.Errorf("invalid LOG_LEVELS entry %q", pair)
+		}
+		levels[strings.TrimSpace(name)] = level
+	}
+	return levels, nil
+}
+
+// Configure sends logs to w in the given format ("json" or "text") with a default level
+// and per package levels. It also routes the standard log package and slog's default
+// logger through the same output.
+func Configure(w io.Writer, format string, level slog.Level, levels map[string]slog.Level) {
+	// Levels are enforced by package loggers, the output accepts everything
+	options := &slog.HandlerOptions{Level: slog.Level(-100)}
+
+	var handler slog.Handler
+	if format == "json" {
+		handler = slog.NewJSONHandler(w, options)
+	} else {
+		handler = slog.NewTextHandler(w, options)
+	}
+
+	active.Store(&config{handler: handler, level: level, levels: levels})
+	slog.SetDefault(For(""))
+}
+
+// For returns the logger of a package. Its records carry a "package" attribute and are
+// filtered by the level configured for the package.
+func For(pkg string) *slog.Logger {
+	return slog.New(&packageHandler{pkg: pkg})
+}
+
+// packageHandler writes to the active output, so that loggers created at package
+// initialization follow a configuration made later by main
+type packageHandler struct {
+	pkg string
+	ops []func(slog.Handler) slog.Handler
+}
+
+func (h *packageHandler) level() slog.Level {
+	cfg := active.Load()
+	if level, ok := cfg.levels[h.pkg]; ok {
+		return level
+	}
+	return cfg.level
+}
+
+func (h *packageHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level()
+}
+
+func (h *packageHandler) Handle(ctx context.Context, record slog.Record) error {
+	handler := active.Load().handler
+	if h.pkg != "" {
+		handler = handler.WithAttrs([]slog.Attr{slog.String("package", h.pkg)})
+	}
+	for _, op := range h.ops {
+		handler = op(handler)
+	}
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return handler.Handle(ctx, record)
+}
+
+func (h *packageHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h *packageHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
+
+func (h *packageHandler) with(op func(slog.Handler) slog.Handler) *packageHandler {
+	ops := make([]func(slog.Handler) slog.Handler, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &packageHandler{pkg: h.pkg, ops: append(ops, op)}
+}
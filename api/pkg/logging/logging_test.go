@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capture configures logging to a buffer for the duration of a test
+func capture(t *testing.T, level slog.Level, levels map[string]slog.Level) *bytes.Buffer {
+	t.Helper()
+	t.Cleanup(func() { Configure(os.Stderr, "text", slog.LevelInfo, nil) })
+
+	var buf bytes.Buffer
+	Configure(&buf, "json", level, levels)
+	return &buf
+}
+
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var result []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		result = append(result, record)
+	}
+	return result
+}
+
+func TestPackageLevelsAndRequestIDs(t *testing.T) {
+	// Created before configuration, as package level loggers are
+	fftt := For("fftt").With("provider", "fftt")
+	cache := For("cache")
+
+	buf := capture(t, slog.LevelInfo, map[string]slog.Level{"fftt": slog.LevelDebug, "cache": slog.LevelWarn})
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	fftt.DebugContext(ctx, "Requesting tournaments", "tournament_id", 42)
+	cache.Info("Saved cache")
+	cache.Warn("Failed to save cache")
+
+	logged := records(t, buf)
+	require.Len(t, logged, 2)
+
+	assert.Equal(t, "DEBUG", logged[0]["level"])
+	assert.Equal(t, "fftt", logged[0]["package"])
+	assert.Equal(t, "fftt", logged[0]["provider"])
+	assert.Equal(t, "req-1", logged[0]["request_id"])
+	assert.EqualValues(t, 42, logged[0]["tournament_id"])
+
+	assert.Equal(t, "Failed to save cache", logged[1]["msg"])
+	assert.NotContains(t, logged[1], "request_id")
+}
+
+func TestStandardLogIsRouted(t *testing.T) {
+	buf := capture(t, slog.LevelInfo, nil)
+
+	log.Printf("from the log package")
+
+	logged := records(t, buf)
+	require.Len(t, logged, 1)
+	assert.Equal(t, "from the log package", logged[0]["msg"])
+	assert.Equal(t, "INFO", logged[0]["level"])
+}
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels("fftt=debug, geocoding=WARN,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]slog.Level{"fftt": slog.LevelDebug, "geocoding": slog.LevelWarn}, levels)
+
+	_, err = ParseLevels("fftt")
+	assert.Error(t, err)
+	_, err = ParseLevels("fftt=loud")
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// useRegistry registers the metrics created by the test in a registry of their own
+func useRegistry(t *testing.T) *Registry {
+	t.Helper()
+
+	original := Default
+	Default = NewRegistry()
+	t.Cleanup(func() { Default = original })
+	return Default
+}
+
+func text(r *Registry) string {
+	var b strings.Builder
+	r.WriteText(&b)
+	return b.String()
+}
+
+func TestCountersAndGauges(t *testing.T) {
+	r := useRegistry(t)
+
+	requests := NewCounterVec("requests_total", "Requests", "route", "status")
+	requests.With("/b", "200").Inc()
+	requests.With("/a", "200").Add(2)
+	requests.With("/a", "200").Inc()
+	NewGaugeVec("last_refresh", "Last refresh").With().Set(1.5)
+	NewGaugeFunc("size", "Size", func() float64 { return 42 })
+
+	assert.Equal(t, `# HELP last_refresh Last refresh
+# TYPE last_refresh gauge
+last_refresh 1.5
+# HELP requests_total Requests
+# TYPE requests_total counter
+requests_total{route="/a",status="200"} 3
+requests_total{route="/b",status="200"} 1
+# HELP size Size
+# TYPE size gauge
+size 42
+`, text(r))
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	r := useRegistry(t)
+
+	durations := NewHistogramVec("duration_seconds", "Durations", []float64{1, 0.1}, "job")
+	durations.With("refresh").Observe(0.05)
+	durations.With("refresh").Observe(0.5)
+	durations.With("refresh").Observe(3)
+
+	assert.Equal(t, `# HELP duration_seconds Durations
+# TYPE duration_seconds histogram
+duration_seconds_bucket{job="refresh",le="0.1"} 1
+duration_seconds_bucket{job="refresh",le="1"} 2
+duration_seconds_bucket{job="refresh",le="+Inf"} 3
+duration_seconds_sum{job="refresh"} 3.55
+duration_seconds_count{job="refresh"} 3
+`, text(r))
+}
+
+func TestLabelValuesAreEscaped(t *testing.T) {
+	r := useRegistry(t)
+
+	NewCounterVec("errors_total", "Errors", "message").With("say \"hi\"\\\n").Inc()
+
+	assert.Contains(t, text(r), `errors_total{message="say \"hi\"\\\n"} 1`)
+}
+
+func TestRegisteringTwicePanics(t *testing.T) {
+	useRegistry(t)
+
+	NewCounterVec("twice_total", "Twice")
+	assert.Panics(t, func() { NewCounterVec("twice_total", "Twice") })
+}
+
+func TestHandler(t *testing.T) {
+	r := useRegistry(t)
+	NewCounterVec("served_total", "Served").With().Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "served_total 1\n")
+}
@@ -0,0 +1,120 @@
+// Package privacy pseudonymizes personal data before it is logged: client IPs are replaced
+// by a keyed hash or truncated, and User-Agents are reduced to a browser and OS family.
+package privacy
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"sync"
+	"time"
+)
+
+// Mode is how client IPs are logged
+type Mode string
+
+// Modes
+const (
+	// ModeHash replaces IPs with a keyed hash. The key rotates and is never stored, so
+	// hashes cannot be reversed or linked across rotations.
+	ModeHash Mode = "hash"
+	// ModeTruncate keeps the /24 network of IPv4 addresses and the /48 of IPv6 ones
+	ModeTruncate Mode = "truncate"
+	// ModeOff logs raw IPs and User-Agents
+	ModeOff Mode = "off"
+)
+
+// DefaultSaltRotation is how long a hash key is used
+const DefaultSaltRotation = 24 * time.Hour
+
+// ParseMode parses a mode name, defaulting to ModeHash
+func ParseMode(value string) (Mode, error) {
+	switch mode := Mode(value); mode {
+	case "":
+		return ModeHash, nil
+	case ModeHash, ModeTruncate, ModeOff:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown privacy mode %q", value)
+	}
+}
+
+// Pseudonymizer replaces client data according to a mode
+type Pseudonymizer struct {
+	mode     Mode
+	rotation time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	salt    []byte
+	saltEra int64
+}
+
+// NewPseudonymizer returns a pseudonymizer whose hash key changes every rotation
+func NewPseudonymizer(mode Mode, rotation time.Duration) *Pseudonymizer {
+	return &Pseudonymizer{mode: mode, rotation: rotation, now: time.Now}
+}
+
+// Mode returns the mode of the pseudonymizer
+func (p *Pseudonymizer) Mode() Mode {
+	return p.mode
+}
+
+// IP returns the loggable form of an IP address. Values that are not IPs are hashed in
+// every mode but ModeOff.
+func (p *Pseudonymizer) IP(ip string) string {
+	switch p.mode {
+	case ModeOff:
+		return ip
+	case ModeTruncate:
+		if addr, err := netip.ParseAddr(ip); err == nil {
+			return truncate(addr.Unmap())
+		}
+	}
+	return p.hash(ip)
+}
+
+// UserAgent returns the loggable form of a User-Agent
+func (p *Pseudonymizer) UserAgent(userAgent string) string {
+	if p.mode == ModeOff {
+		return userAgent
+	}
+	return UserAgentFamily(userAgent)
+}
+
+func truncate(addr netip.Addr) string {
+	bits := 24
+	if addr.Is6() {
+		bits = 48
+	}
+	prefix, _ := addr.Prefix(bits)
+	return prefix.String()
+}
+
+// hash returns a short HMAC of value keyed with the current salt
+func (p *Pseudonymizer) hash(value string) string {
+	mac := hmac.New(sha256.New, p.currentSalt())
+	mac.Write([]byte(value))
+	return "ip_" + hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// currentSalt returns the salt of the current rotation period, drawing a new one when
+// the period changes
+func (p *Pseudonymizer) currentSalt() []byte {
+	era := p.now().UnixNano() / int64(p.rotation)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.salt == nil || era != p.saltEra {
+		salt := make([]byte, 32)
+		if _, err := rand.Read(salt); err != nil {
+			panic(fmt.Sprintf("failed to generate salt: %v", err))
+		}
+		p.salt, p.saltEra = salt, era
+	}
+	return p.salt
+}
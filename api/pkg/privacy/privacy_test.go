@@ -0,0 +1,63 @@
+package privacy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashedIPsRotate(t *testing.T) {
+	p := NewPseudonymizer(ModeHash, DefaultSaltRotation)
+	now := time.Date(2025, 10, 1, 8, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	first := p.IP("203.0.113.7")
+	assert.True(t, strings.HasPrefix(first, "ip_"))
+	assert.NotContains(t, first, "203.0.113")
+	assert.Equal(t, first, p.IP("203.0.113.7"), "hashes are stable within a rotation")
+	assert.NotEqual(t, first, p.IP("203.0.113.8"))
+
+	now = now.Add(DefaultSaltRotation)
+	assert.NotEqual(t, first, p.IP("203.0.113.7"), "hashes change with the salt")
+}
+
+func TestTruncatedIPs(t *testing.T) {
+	p := NewPseudonymizer(ModeTruncate, DefaultSaltRotation)
+
+	assert.Equal(t, "203.0.113.0/24", p.IP("203.0.113.7"))
+	assert.Equal(t, "203.0.113.0/24", p.IP("::ffff:203.0.113.7"))
+	assert.Equal(t, "2001:db8:1234::/48", p.IP("2001:db8:1234:5678::1"))
+	assert.True(t, strings.HasPrefix(p.IP("unknown"), "ip_"))
+}
+
+func TestModes(t *testing.T) {
+	mode, err := ParseMode("")
+	require.NoError(t, err)
+	assert.Equal(t, ModeHash, mode)
+
+	_, err = ParseMode("encrypt")
+	assert.Error(t, err)
+
+	off := NewPseudonymizer(ModeOff, DefaultSaltRotation)
+	assert.Equal(t, "203.0.113.7", off.IP("203.0.113.7"))
+	assert.Equal(t, "curl/8.0", off.UserAgent("curl/8.0"))
+}
+
+func TestUserAgentFamily(t *testing.T) {
+	for userAgent, expected := range map[string]string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36":                         "Chrome/Windows",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0":           "Edge/Windows",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1": "Safari/iOS",
+		"Mozilla/5.0 (Android 14; Mobile; rv:121.0) Gecko/121.0 Firefox/121.0":                                                                    "Firefox/Android",
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)":                                                                "Googlebot",
+		"Mozilla/5.0 (compatible; SomeCrawler/1.0)":                                                                                               "bot",
+		"curl/8.4.0": "curl",
+		"":           "unknown",
+		"Lynx/2.8.9": "other/other",
+	} {
+		assert.Equal(t, expected, UserAgentFamily(userAgent), userAgent)
+	}
+}
@@ -0,0 +1,83 @@
+package privacy
+
+import "strings"
+
+// family is a User-Agent token and the name it is logged as. Order matters: browsers
+// embedding other browsers' tokens come first.
+type family struct {
+	token string
+	name  string
+}
+
+var bots = []family{
+	{"googlebot", "Googlebot"},
+	{"bingbot", "Bingbot"},
+	{"applebot", "Applebot"},
+	{"duckduckbot", "DuckDuckBot"},
+	{"yandexbot", "YandexBot"},
+	{"facebookexternalhit", "Facebook"},
+	{"twitterbot", "Twitterbot"},
+	{"curl/", "curl"},
+	{"wget/", "Wget"},
+	{"python-requests", "python-requests"},
+	{"go-http-client", "Go"},
+	{"node-fetch", "node-fetch"},
+	{"axios/", "axios"},
+	{"postmanruntime", "Postman"},
+}
+
+var browsers = []family{
+	{"edg/", "Edge"},
+	{"opr/", "Opera"},
+	{"samsungbrowser", "Samsung Internet"},
+	{"firefox/", "Firefox"},
+	{"fxios/", "Firefox"},
+	{"crios/", "Chrome"},
+	{"chrome/", "Chrome"},
+	{"safari/", "Safari"},
+}
+
+var systems = []family{
+	{"android", "Android"},
+	{"iphone", "iOS"},
+	{"ipad", "iOS"},
+	{"windows", "Windows"},
+	{"mac os x", "macOS"},
+	{"cros", "ChromeOS"},
+	{"linux", "Linux"},
+}
+
+// UserAgentFamily reduces a User-Agent to its browser and OS families, e.g. "Firefox/Windows",
+// or to the name of a known bot or HTTP client
+func UserAgentFamily(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return "unknown"
+	}
+
+	if name := match(ua, bots); name != "" {
+		return name
+	}
+	if strings.Contains(ua, "bot") || strings.Contains(ua, "spider") || strings.Contains(ua, "crawler") {
+		return "bot"
+	}
+
+	browser := match(ua, browsers)
+	if browser == "" {
+		browser = "other"
+	}
+	system := match(ua, systems)
+	if system == "" {
+		system = "other"
+	}
+	return browser + "/" + system
+}
+
+func match(ua string, families []family) string {
+	for _, f := range families {
+		if strings.Contains(ua, f.token) {
+			return f.name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,168 @@
+// Package ratelimit keeps token bucket limiters per client in a sharded store. Idle
+// limiters are evicted and the number of limiters is capped, so memory stays bounded
+// however many clients are seen.
+package ratelimit
+
+import (
+	"context"
+	"hash/maphash"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Store defaults
+const (
+	DefaultShards     = 32
+	DefaultMaxEntries = 100000
+	DefaultIdleTTL    = 10 * time.Minute
+)
+
+// Policy is a rate limit: a sustained rate per minute and a burst
+type Policy struct {
+	RequestsPerMinute int
+	Burst             int
+}
+
+// Result is the outcome of a request against a limiter
+type Result struct {
+	Allowed bool
+	// Remaining is the number of requests left in the burst
+	Remaining int
+	// Reset is the time until the burst is fully replenished
+	Reset time.Duration
+	// RetryAfter is the time until a rejected request would be allowed
+	RetryAfter time.Duration
+}
+
+type entry struct {
+	limiter  *rate.Limiter
+	policy   Policy
+	lastSeen time.Time
+}
+
+type shard struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+// Store holds the limiters of every client
+type Store struct {
+	seed     maphash.Seed
+	shards   []shard
+	perShard int
+	idleTTL  time.Duration
+	now      func() time.Time
+}
+
+// NewStore returns a store spread over shards, holding at most maxEntries limiters and
+// dropping those unused for idleTTL
+func NewStore(shards, maxEntries int, idleTTL time.Duration) *Store {
+	s := &Store{
+		seed:     maphash.MakeSeed(),
+		shards:   make([]shard, shards),
+		perShard: max(maxEntries/shards, 1),
+		idleTTL:  idleTTL,
+		now:      time.Now,
+	}
+	for i := range s.shards {
+		s.shards[i].entries = make(map[string]*entry)
+	}
+	return s
+}
+
+// Allow takes a token from the limiter of key, created with policy if needed. A limiter
+// whose policy changed is replaced.
+func (s *Store) Allow(key string, policy Policy) Result {
+	now := s.now()
+	sh := &s.shards[maphash.String(s.seed, key)%uint64(len(s.shards))]
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	e, ok := sh.entries[key]
+	if !ok || e.policy != policy {
+		if !ok && len(sh.entries) >= s.perShard {
+			s.evict(sh, now)
+		}
+		e = &entry{
+			limiter: rate.NewLimiter(rate.Limit(float64(policy.RequestsPerMinute)/60), policy.Burst),
+			policy:  policy,
+		}
+		sh.entries[key] = e
+	}
+	e.lastSeen = now
+
+	perToken := time.Minute / time.Duration(policy.RequestsPerMinute)
+	result := Result{Allowed: e.limiter.AllowN(now, 1)}
+
+	tokens := e.limiter.TokensAt(now)
+	result.Remaining = max(int(tokens), 0)
+	result.Reset = time.Duration(math.Ceil((float64(policy.Burst) - tokens) * float64(perToken)))
+	if !result.Allowed {
+		result.RetryAfter = time.Duration(math.Ceil((1 - tokens) * float64(perToken)))
+	}
+	return result
+}
+
+// evict drops the idle limiters of a full shard, or its least recently used one if none
+// is idle. Callers hold sh.mu.
+func (s *Store) evict(sh *shard, now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, e := range sh.entries {
+		if now.Sub(e.lastSeen) >= s.idleTTL {
+			delete(sh.entries, key)
+			continue
+		}
+		if oldestKey == "" || e.lastSeen.Before(oldest) {
+			oldestKey, oldest = key, e.lastSeen
+		}
+	}
+	if len(sh.entries) >= s.perShard {
+		delete(sh.entries, oldestKey)
+	}
+}
+
+// Sweep drops every limiter unused for the idle TTL
+func (s *Store) Sweep() {
+	now := s.now()
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		for key, e := range sh.entries {
+			if now.Sub(e.lastSeen) >= s.idleTTL {
+				delete(sh.entries, key)
+			}
+		}
+		sh.mu.Unlock()
+	}
+}
+
+// Len returns the number of limiters held
+func (s *Store) Len() int {
+	n := 0
+	for i := range s.shards {
+		s.shards[i].mu.Lock()
+		n += len(s.shards[i].entries)
+		s.shards[i].mu.Unlock()
+	}
+	return n
+}
+
+// SweepEvery sweeps the store every interval until ctx is done
+func (s *Store) SweepEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Sweep()
+		}
+	}
+}
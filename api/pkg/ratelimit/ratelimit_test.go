@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStore returns a store with a controllable clock
+func newTestStore(shards, maxEntries int) (*Store, *time.Time) {
+	s := NewStore(shards, maxEntries, time.Minute)
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func TestAllowReportsRemainingAndRetryAfter(t *testing.T) {
+	s, now := newTestStore(1, 10)
+	policy := Policy{RequestsPerMinute: 30, Burst: 2}
+
+	result := s.Allow("ip:192.0.2.1", policy)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+	assert.Equal(t, 2*time.Second, result.Reset)
+
+	assert.True(t, s.Allow("ip:192.0.2.1", policy).Allowed)
+	result = s.Allow("ip:192.0.2.1", policy)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 2*time.Second, result.RetryAfter)
+	assert.Equal(t, 4*time.Second, result.Reset)
+
+	// Other clients have their own bucket
+	assert.True(t, s.Allow("ip:192.0.2.2", policy).Allowed)
+
+	*now = now.Add(2 * time.Second)
+	assert.True(t, s.Allow("ip:192.0.2.1", policy).Allowed)
+
+	// A new policy replaces the limiter
+	assert.True(t, s.Allow("ip:192.0.2.1", Policy{RequestsPerMinute: 60, Burst: 5}).Allowed)
+}
+
+func TestStoreIsBounded(t *testing.T) {
+	s, now := newTestStore(1, 3)
+	policy := Policy{RequestsPerMinute: 6, Burst: 1}
+
+	for i := 0; i < 3; i++ {
+		s.Allow(fmt.Sprintf("ip:%d", i), policy)
+		*now = now.Add(time.Second)
+	}
+	require.False(t, s.Allow("ip:0", policy).Allowed)
+
+	// The least recently used limiter makes room for a new client
+	s.Allow("ip:3", policy)
+	assert.Equal(t, 3, s.Len())
+	assert.False(t, s.Allow("ip:0", policy).Allowed, "ip:0 was used last and must be kept")
+	assert.True(t, s.Allow("ip:1", policy).Allowed, "ip:1 was evicted and starts afresh")
+
+	*now = now.Add(time.Minute)
+	s.Sweep()
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestAllowIsSafeForConcurrentUse(t *testing.T) {
+	s := NewStore(DefaultShards, 1000, time.Minute)
+	policy := Policy{RequestsPerMinute: 60, Burst: 10}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s.Allow(fmt.Sprintf("ip:%d", (i*100+j)%2000), policy)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, s.Len(), 1000)
+}
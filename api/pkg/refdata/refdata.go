@@ -0,0 +1,249 @@
+// Package refdata provides French administrative reference data (regions and departments)
+// with their official INSEE codes, and resolves postal codes to departments and regions.
+package refdata
+
+import (
+	"strings"
+)
+
+// Region is a French administrative region
+type Region struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// Department is a French administrative department
+type Department struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	RegionCode string `json:"regionCode"`
+}
+
+// regions lists the 18 French regions with their INSEE codes
+var regions = []Region{
+	{Code: "01", Name: "Guadeloupe"},
+	{Code: "02", Name: "Martinique"},
+	{Code: "03", Name: "Guyane"},
+	{Code: "04", Name: "La Réunion"},
+	{Code: "06", Name: "Mayotte"},
+	{Code: "11", Name: "Île-de-France"},
+	{Code: "24", Name: "Centre-Val de Loire"},
+	{Code: "27", Name: "Bourgogne-Franche-Comté"},
+	{Code: "28", Name: "Normandie"},
+	{Code: "32", Name: "Hauts-de-France"},
+	{Code: "44", Name: "Grand Est"},
+	{Code: "52", Name: "Pays de la Loire"},
+	{Code: "53", Name: "Bretagne"},
+	{Code: "75", Name: "Nouvelle-Aquitaine"},
+	{Code: "76", Name: "Occitanie"},
+	{Code: "84", Name: "Auvergne-Rhône-Alpes"},
+	{Code: "93", Name: "Provence-Alpes-Côte d'Azur"},
+	{Code: "94", Name: "Corse"},
+}
+
+// departments lists the 101 French departments with their INSEE codes
+var departments = []Department{
+	{Code: "01", Name: "Ain", RegionCode: "84"},
+	{Code: "02", Name: "Aisne", RegionCode: "32"},
+	{Code: "03", Name: "Allier", RegionCode: "84"},
+	{Code: "04", Name: "Alpes-de-Haute-Provence", RegionCode: "93"},
+	{Code: "05", Name: "Hautes-Alpes", RegionCode: "93"},
+	{Code: "06", Name: "Alpes-Maritimes", RegionCode: "93"},
+	{Code: "07", Name: "Ardèche", RegionCode: "84"},
+	{Code: "08", Name: "Ardennes", RegionCode: "44"},
+	{Code: "09", Name: "Ariège", RegionCode: "76"},
+	{Code: "10", Name: "Aube", RegionCode: "44"},
+	{Code: "11", Name: "Aude", RegionCode: "76"},
+	{Code: "12", Name: "Aveyron", RegionCode: "76"},
+	{Code: "13", Name: "Bouches-du-Rhône", RegionCode: "93"},
+	{Code: "14", Name: "Calvados", RegionCode: "28"},
+	{Code: "15", Name: "Cantal", RegionCode: "84"},
+	{Code: "16", Name: "Charente", RegionCode: "75"},
+	{Code: "17", Name: "Charente-Maritime", RegionCode: "75"},
+	{Code: "18", Name: "Cher", RegionCode: "24"},
+	{Code: "19", Name: "Corrèze", RegionCode: "75"},
+	{Code: "2A", Name: "Corse-du-Sud", RegionCode: "94"},
+	{Code: "2B", Name: "Haute-Corse", RegionCode: "94"},
+	{Code: "21", Name: "Côte-d'Or", RegionCode: "27"},
+	{Code: "22", Name: "Côtes-d'Armor", RegionCode: "53"},
+	{Code: "23", Name: "Creuse", RegionCode: "75"},
+	{Code: "24", Name: "Dordogne", RegionCode: "75"},
+	{Code: "25", Name: "Doubs", RegionCode: "27"},
+	{Code: "26", Name: "Drôme", RegionCode: "84"},
+	{Code: "27", Name: "Eure", RegionCode: "28"},
+	{Code: "28", Name: "Eure-et-Loir", RegionCode: "24"},
+	{Code: "29", Name: "Finistère", RegionCode: "53"},
+	{Code: "30", Name: "Gard", RegionCode: "76"},
+	{Code: "31", Name: "Haute-Garonne", RegionCode: "76"},
+	{Code: "32", Name: "Gers", RegionCode: "76"},
+	{Code: "33", Name: "Gironde", RegionCode: "75"},
+	{Code: "34", Name: "Hérault", RegionCode: "76"},
+	{Code: "35", Name: "Ille-et-Vilaine", RegionCode: "53"},
+	{Code: "36", Name: "Indre", RegionCode: "24"},
+	{Code: "37", Name: "Indre-et-Loire", RegionCode: "24"},
+	{Code: "38", Name: "Isère", RegionCode: "84"},
+	{Code: "39", Name: "Jura", RegionCode: "27"},
+	{Code: "40", Name: "Landes", RegionCode: "75"},
+	{Code: "41", Name: "Loir-et-Cher", RegionCode: "24"},
+	{Code: "42", Name: "Loire", RegionCode: "84"},
+	{Code: "43", Name: "Haute-Loire", RegionCode: "84"},
+	{Code: "44", Name: "Loire-Atlantique", RegionCode: "52"},
+	{Code: "45", Name: "Loiret", RegionCode: "24"},
+	{Code: "46", Name: "Lot", RegionCode: "76"},
+	{Code: "47", Name: "Lot-et-Garonne", RegionCode: "75"},
+	{Code: "48", Name: "Lozère", RegionCode: "76"},
+	{Code: "49", Name: "Maine-et-Loire", RegionCode: "52"},
+	{Code: "50", Name: "Manche", RegionCode: "28"},
+	{Code: "51", Name: "Marne", RegionCode: "44"},
+	{Code: "52", Name: "Haute-Marne", RegionCode: "44"},
+	{Code: "53", Name: "Mayenne", RegionCode: "52"},
+	{Code: "54", Name: "Meurthe-et-Moselle", RegionCode: "44"},
+	{Code: "55", Name: "Meuse", RegionCode: "44"},
+	{Code: "56", Name: "Morbihan", RegionCode: "53"},
+	{Code: "57", Name: "Moselle", RegionCode: "44"},
+	{Code: "58", Name: "Nièvre", RegionCode: "27"},
+	{Code: "59", Name: "Nord", RegionCode: "32"},
+	{Code: "60", Name: "Oise", RegionCode: "32"},
+	{Code: "61", Name: "Orne", RegionCode: "28"},
+	{Code: "62", Name: "Pas-de-Calais", RegionCode: "32"},
+	{Code: "63", Name: "Puy-de-Dôme", RegionCode: "84"},
+	{Code: "64", Name: "Pyrénées-Atlantiques", RegionCode: "75"},
+	{Code: "65", Name: "Hautes-Pyrénées", RegionCode: "76"},
+	{Code: "66", Name: "Pyrénées-Orientales", RegionCode: "76"},
+	{Code: "67", Name: "Bas-Rhin", RegionCode: "44"},
+	{Code: "68", Name: "Haut-Rhin", RegionCode: "44"},
+	{Code: "69", Name: "Rhône", RegionCode: "84"},
+	{Code: "70", Name: "Haute-Saône", RegionCode: "27"},
+	{Code: "71", Name: "Saône-et-Loire", RegionCode: "27"},
+	{Code: "72", Name: "Sarthe", RegionCode: "52"},
+	{Code: "73", Name: "Savoie", RegionCode: "84"},
+	{Code: "74", Name: "Haute-Savoie", RegionCode: "84"},
+	{Code: "75", Name: "Paris", RegionCode: "11"},
+	{Code: "76", Name: "Seine-Maritime", RegionCode: "28"},
+	{Code: "77", Name: "Seine-et-Marne", RegionCode: "11"},
+	{Code: "78", Name: "Yvelines", RegionCode: "11"},
+	{Code: "79", Name: "Deux-Sèvres", RegionCode: "75"},
+	{Code: "80", Name: "Somme", RegionCode: "32"},
+	{Code: "81", Name: "Tarn", RegionCode: "76"},
+	{Code: "82", Name: "Tarn-et-Garonne", RegionCode: "76"},
+	{Code: "83", Name: "Var", RegionCode: "93"},
+	{Code: "84", Name: "Vaucluse", RegionCode: "93"},
+	{Code: "85", Name: "Vendée", RegionCode: "52"},
+	{Code: "86", Name: "Vienne", RegionCode: "75"},
+	{Code: "87", Name: "Haute-Vienne", RegionCode: "75"},
+	{Code: "88", Name: "Vosges", RegionCode: "44"},
+	{Code: "89", Name: "Yonne", RegionCode: "27"},
+	{Code: "90", Name: "Territoire de Belfort", RegionCode: "27"},
+	{Code: "91", Name: "Essonne", RegionCode: "11"},
+	{Code: "92", Name: "Hauts-de-Seine", RegionCode: "11"},
+	{Code: "93", Name: "Seine-Saint-Denis", RegionCode: "11"},
+	{Code: "94", Name: "Val-de-Marne", RegionCode: "11"},
+	{Code: "95", Name: "Val-d'Oise", RegionCode: "11"},
+	{Code: "971", Name: "Guadeloupe", RegionCode: "01"},
+	{Code: "972", Name: "Martinique", RegionCode: "02"},
+	{Code: "973", Name: "Guyane", RegionCode: "03"},
+	{Code: "974", Name: "La Réunion", RegionCode: "04"},
+	{Code: "976", Name: "Mayotte", RegionCode: "06"},
+}
+
+// Lookup indexes, built once at package initialization
+var (
+	regionsByCode     = make(map[string]Region, len(regions))
+	regionsByName     = make(map[string]Region, len(regions))
+	departmentsByCode = make(map[string]Department, len(departments))
+	departmentsByName = make(map[string]Department, len(departments))
+)
+
+func init() {
+	for _, r := range regions {
+		regionsByCode[r.Code] = r
+		regionsByName[normalizeName(r.Name)] = r
+	}
+	for _, d := range departments {
+		departmentsByCode[d.Code] = d
+		departmentsByName[normalizeName(d.Name)] = d
+	}
+}
+
+// Regions returns all regions ordered by INSEE code
+func Regions() []Region {
+	return append([]Region(nil), regions...)
+}
+
+// Departments returns all departments ordered by INSEE code
+func Departments() []Department {
+	return append([]Department(nil), departments...)
+}
+
+// RegionByCode returns the region with the given INSEE code
+func RegionByCode(code string) (Region, bool) {
+	r, ok := regionsByCode[strings.TrimSpace(code)]
+	return r, ok
+}
+
+// LookupRegion resolves a region from its INSEE code or its name (case insensitive)
+func LookupRegion(value string) (Region, bool) {
+	if r, ok := RegionByCode(value); ok {
+		return r, true
+	}
+	r, ok := regionsByName[normalizeName(value)]
+	return r, ok
+}
+
+// DepartmentByCode returns the department with the given INSEE code.
+// Single digit codes are zero-padded ("1" -> "01").
+func DepartmentByCode(code string) (Department, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) == 1 {
+		code = "0" + code
+	}
+	d, ok := departmentsByCode[code]
+	return d, ok
+}
+
+// LookupDepartment resolves a department from its INSEE code or its name (case insensitive)
+func LookupDepartment(value string) (Department, bool) {
+	if d, ok := DepartmentByCode(value); ok {
+		return d, true
+	}
+	d, ok := departmentsByName[normalizeName(value)]
+	return d, ok
+}
+
+// DepartmentFromPostalCode resolves the department of a French postal code.
+// Corsican postal codes (20xxx) are split between Corse-du-Sud (2A, below 20200) and
+// Haute-Corse (2B), and overseas postal codes (97xxx) use 3-digit department codes.
+func DepartmentFromPostalCode(postalCode string) (Department, bool) {
+	postalCode = strings.TrimSpace(postalCode)
+	if len(postalCode) != 5 {
+		return Department{}, false
+	}
+	for _, r := range postalCode {
+		if r < '0' || r > '9' {
+			return Department{}, false
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(postalCode, "20"):
+		if postalCode < "20200" {
+			return DepartmentByCode("2A")
+		}
+		return DepartmentByCode("2B")
+	case strings.HasPrefix(postalCode, "97"):
+		return DepartmentByCode(postalCode[:3])
+	default:
+		return DepartmentByCode(postalCode[:2])
+	}
+}
+
+// RegionOfDepartment returns the region a department belongs to
+func RegionOfDepartment(d Department) Region {
+	return regionsByCode[d.RegionCode]
+}
+
+// normalizeName lowercases a name and unifies separators for lookups
+func normalizeName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer("-", " ", "'", " ", "’", " ").Replace(name)
+}
@@ -0,0 +1,71 @@
+package refdata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferenceDataConsistency(t *testing.T) {
+	assert.Len(t, Regions(), 18)
+	assert.Len(t, Departments(), 101)
+
+	for _, d := range Departments() {
+		_, ok := RegionByCode(d.RegionCode)
+		assert.True(t, ok, "department %s has unknown region %s", d.Code, d.RegionCode)
+	}
+}
+
+func TestDepartmentFromPostalCode(t *testing.T) {
+	testCases := []struct {
+		postalCode string
+		department string
+		region     string
+	}{
+		{"35000", "35", "Bretagne"},
+		{"01000", "01", "Auvergne-Rhône-Alpes"},
+		{"75015", "75", "Île-de-France"},
+		{"20000", "2A", "Corse"},
+		{"20137", "2A", "Corse"},
+		{"20200", "2B", "Corse"},
+		{"20600", "2B", "Corse"},
+		{"97400", "974", "La Réunion"},
+		{"97133", "971", "Guadeloupe"},
+		{"97600", "976", "Mayotte"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.postalCode, func(t *testing.T) {
+			d, ok := DepartmentFromPostalCode(tc.postalCode)
+			assert.True(t, ok)
+			assert.Equal(t, tc.department, d.Code)
+			assert.Equal(t, tc.region, RegionOfDepartment(d).Name)
+		})
+	}
+
+	for _, invalid := range []string{"", "3500", "97500", "98000", "ABCDE"} {
+		_, ok := DepartmentFromPostalCode(invalid)
+		assert.False(t, ok, "postal code %q should not resolve", invalid)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	d, ok := LookupDepartment("ille et vilaine")
+	assert.True(t, ok)
+	assert.Equal(t, "35", d.Code)
+
+	d, ok = LookupDepartment("2a")
+	assert.True(t, ok)
+	assert.Equal(t, "Corse-du-Sud", d.Name)
+
+	d, ok = LookupDepartment("1")
+	assert.True(t, ok)
+	assert.Equal(t, "Ain", d.Name)
+
+	r, ok := LookupRegion("provence alpes côte d’azur")
+	assert.True(t, ok)
+	assert.Equal(t, "93", r.Code)
+
+	_, ok = LookupRegion("Atlantide")
+	assert.False(t, ok)
+}
@@ -0,0 +1,196 @@
+// Package stats computes aggregated tournament figures from the tournament cache
+package stats
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/refdata"
+	"tournois-tt/api/pkg/utils"
+)
+
+// Figures holds the aggregated values for a group of tournaments
+type Figures struct {
+	Count           int     `json:"count"`
+	TotalEndowment  int     `json:"totalEndowment"`
+	MedianEndowment float64 `json:"medianEndowment"`
+	AverageTableFee float64 `json:"averageTableFee"`
+	Clubs           int     `json:"clubs"`
+}
+
+// SeasonStats holds the figures of a season, overall and broken down by dimension
+type SeasonStats struct {
+	Season       string             `json:"season"`
+	Total        Figures            `json:"total"`
+	ByRegion     map[string]Figures `json:"byRegion"`
+	ByDepartment map[string]Figures `json:"byDepartment"`
+	ByType       map[string]Figures `json:"byType"`
+	ByMonth      map[string]Figures `json:"byMonth"`
+}
+
+// unknownKey groups tournaments for which a dimension could not be determined
+const unknownKey = "Inconnu"
+
+// memo caches computed stats per season until the tournaments change
+var memo = struct {
+	sync.Mutex
+	store    *cache.Store
+	revision uint64
+	seasons  map[string]SeasonStats
+}{seasons: make(map[string]SeasonStats)}
+
+// ForSeason returns the stats of the given season ("2025-2026"), memoized per store revision
+func ForSeason(store *cache.Store, season string) (SeasonStats, error) {
+	start, end, err := utils.ParseSeason(season)
+	if err != nil {
+		return SeasonStats{}, err
+	}
+
+	memo.Lock()
+	defer memo.Unlock()
+
+	if revision := store.Revision(); memo.store != store || memo.revision != revision {
+		memo.store = store
+		memo.revision = revision
+		memo.seasons = make(map[string]SeasonStats)
+	}
+
+	if stats, ok := memo.seasons[season]; ok {
+		return stats, nil
+	}
+
+	stats := Compute(season, store.All(), start, end)
+	memo.seasons[season] = stats
+	return stats, nil
+}
+
+// Compute aggregates the tournaments starting between start and end
+func Compute(season string, tournaments map[string]cache.TournamentCache, start, end time.Time) SeasonStats {
+	all := newAccumulator()
+	byRegion := make(map[string]*accumulator)
+	byDepartment := make(map[string]*accumulator)
+	byType := make(map[string]*accumulator)
+	byMonth := make(map[string]*accumulator)
+
+	for _, t := range tournaments {
+		startDate, ok := utils.ParseTournamentDate(t.StartDate)
+		if !ok || startDate.Before(start) || startDate.After(end) {
+			continue
+		}
+
+		all.add(t)
+		accumulatorFor(byRegion, region(t)).add(t)
+		accumulatorFor(byDepartment, department(t)).add(t)
+		accumulatorFor(byType, utils.MapTournamentType(t.Type)).add(t)
+		accumulatorFor(byMonth, startDate.Format("2006-01")).add(t)
+	}
+
+	return SeasonStats{
+		Season:       season,
+		Total:        all.figures(),
+		ByRegion:     figuresOf(byRegion),
+		ByDepartment: figuresOf(byDepartment),
+		ByType:       figuresOf(byType),
+		ByMonth:      figuresOf(byMonth),
+	}
+}
+
+// region returns the region of the organizing club
+func region(t cache.TournamentCache) string {
+	if r := strings.TrimSpace(t.Club.Region); r != "" {
+		return r
+	}
+	return unknownKey
+}
+
+// department returns the department of the organizing club, falling back to the venue postal code
+func department(t cache.TournamentCache) string {
+	if d := strings.TrimSpace(t.Club.Department); d != "" {
+		return d
+	}
+	if d, ok := refdata.DepartmentFromPostalCode(t.Address.PostalCode); ok {
+		return d.Code
+	}
+	return unknownKey
+}
+
+// accumulator collects the raw values needed to compute Figures
+type accumulator struct {
+	count          int
+	totalEndowment int
+	endowments     []int
+	tableFees      int
+	tablesWithFee  int
+	clubs          map[int]struct{}
+}
+
+func newAccumulator() *accumulator {
+	return &accumulator{clubs: make(map[int]struct{})}
+}
+
+func accumulatorFor(groups map[string]*accumulator, key string) *accumulator {
+	acc, ok := groups[key]
+	if !ok {
+		acc = newAccumulator()
+		groups[key] = acc
+	}
+	return acc
+}
+
+func (a *accumulator) add(t cache.TournamentCache) {
+	a.count++
+	a.totalEndowment += t.Endowment
+	a.endowments = append(a.endowments, t.Endowment)
+
+	// Tables without a fee are usually missing data rather than free entries
+	for _, table := range t.Tables {
+		if table.Fee > 0 {
+			a.tableFees += table.Fee
+			a.tablesWithFee++
+		}
+	}
+
+	if t.Club.ID != 0 {
+		a.clubs[t.Club.ID] = struct{}{}
+	}
+}
+
+func (a *accumulator) figures() Figures {
+	f := Figures{
+		Count:           a.count,
+		TotalEndowment:  a.totalEndowment,
+		MedianEndowment: median(a.endowments),
+		Clubs:           len(a.clubs),
+	}
+	if a.tablesWithFee > 0 {
+		f.AverageTableFee = float64(a.tableFees) / float64(a.tablesWithFee)
+	}
+	return f
+}
+
+func figuresOf(groups map[string]*accumulator) map[string]Figures {
+	result := make(map[string]Figures, len(groups))
+	for key, acc := range groups {
+		result[key] = acc.figures()
+	}
+	return result
+}
+
+// median returns the median of the values, or 0 if there are none
+func median(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return float64(sorted[mid-1]+sorted[mid]) / 2
+	}
+	return float64(sorted[mid])
+}
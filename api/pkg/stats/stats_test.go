@@ -0,0 +1,72 @@
+package stats
+
+import (
+	"testing"
+
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/geocoding"
+	"tournois-tt/api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompute(t *testing.T) {
+	start, end, err := utils.ParseSeason("2025-2026")
+	require.NoError(t, err)
+
+	tournaments := map[string]cache.TournamentCache{
+		"1": {
+			ID:        1,
+			Type:      "P",
+			StartDate: "2025-09-13T00:00:00",
+			Endowment: 300,
+			Club:      cache.Club{ID: 10, Department: "35", Region: "Bretagne"},
+			Tables:    []cache.Table{{Fee: 8}, {Fee: 10}},
+		},
+		"2": {
+			ID:        2,
+			Type:      "P",
+			StartDate: "2025-09-20T00:00:00",
+			Endowment: 500,
+			Club:      cache.Club{ID: 10, Department: "35", Region: "Bretagne"},
+			Tables:    []cache.Table{{Fee: 0}, {Fee: 12}},
+		},
+		"3": {
+			ID:        3,
+			Type:      "A",
+			StartDate: "2026-01-10",
+			Endowment: 1000,
+			Address:   geocoding.Address{PostalCode: "97400"},
+			Club:      cache.Club{ID: 20},
+		},
+		// Outside of the season
+		"4": {
+			ID:        4,
+			Type:      "P",
+			StartDate: "2025-06-01T00:00:00",
+			Endowment: 9999,
+			Club:      cache.Club{ID: 30},
+		},
+	}
+
+	stats := Compute("2025-2026", tournaments, start, end)
+
+	assert.Equal(t, "2025-2026", stats.Season)
+	assert.Equal(t, Figures{Count: 3, TotalEndowment: 1800, MedianEndowment: 500, AverageTableFee: 10, Clubs: 2}, stats.Total)
+
+	assert.Equal(t, 2, stats.ByRegion["Bretagne"].Count)
+	assert.Equal(t, 1, stats.ByRegion[unknownKey].Count)
+	assert.Equal(t, 400.0, stats.ByDepartment["35"].MedianEndowment)
+	assert.Equal(t, 1, stats.ByDepartment["974"].Count)
+	assert.Equal(t, 2, stats.ByType["Promotionnel"].Count)
+	assert.Equal(t, 1, stats.ByType["National A"].Count)
+	assert.Equal(t, 2, stats.ByMonth["2025-09"].Count)
+	assert.Equal(t, 1, stats.ByMonth["2026-01"].Count)
+}
+
+func TestMedian(t *testing.T) {
+	assert.Equal(t, 0.0, median(nil))
+	assert.Equal(t, 2.0, median([]int{3, 1, 2}))
+	assert.Equal(t, 2.5, median([]int{4, 1, 3, 2}))
+}
@@ -0,0 +1,190 @@
+// Package tally counts events per day, such as newsletter signups or redirect clicks, and
+// persists the counts for the admin dashboard
+package tally
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/logging"
+)
+
+var logger = logging.For("tally")
+
+// Retention is how long daily counts are kept
+const Retention = 90 * 24 * time.Hour
+
+// Counters counted by the API
+const (
+	NewsletterSignups = "newsletter"
+	RulesRedirects    = "redirect"
+)
+
+const dayFormat = "2006-01-02"
+
+// DayCount is the count of a day
+type DayCount struct {
+	Day   string `json:"day"`
+	Count int64  `json:"count"`
+}
+
+// KeyCount is the count of a key over several days
+type KeyCount struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// Store holds counts by counter name, UTC day and key
+type Store struct {
+	mu     sync.Mutex
+	path   string
+	now    func() time.Time
+	counts map[string]map[string]map[string]int64
+	dirty  bool
+}
+
+// NewStore loads the counts stored at path
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path:   path,
+		now:    time.Now,
+		counts: make(map[string]map[string]map[string]int64),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read counts: %v", err)
+	}
+	if err := json.Unmarshal(data, &s.counts); err != nil {
+		return nil, fmt.Errorf("failed to parse counts: %v", err)
+	}
+	return s, nil
+}
+
+// Add counts one event of a counter for a key, today
+func (s *Store) Add(name, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	days, ok := s.counts[name]
+	if !ok {
+		days = make(map[string]map[string]int64)
+		s.counts[name] = days
+	}
+	day := s.now().UTC().Format(dayFormat)
+	keys, ok := days[day]
+	if !ok {
+		keys = make(map[string]int64)
+		days[day] = keys
+	}
+	keys[key]++
+	s.dirty = true
+}
+
+// Daily returns the totals of a counter for each of the last days, oldest first
+func (s *Store) Daily(name string, days int) []DayCount {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	today := s.now().UTC()
+	result := make([]DayCount, 0, days)
+	for i := days - 1; i >= 0; i-- {
+		day := today.AddDate(0, 0, -i).Format(dayFormat)
+		var total int64
+		for _, count := range s.counts[name][day] {
+			total += count
+		}
+		result = append(result, DayCount{Day: day, Count: total})
+	}
+	return result
+}
+
+// Top returns the keys of a counter with the highest totals over the last days
+func (s *Store) Top(name string, days, limit int) []KeyCount {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	since := s.now().UTC().AddDate(0, 0, -days+1).Format(dayFormat)
+	totals := make(map[string]int64)
+	for day, keys := range s.counts[name] {
+		if day < since {
+			continue
+		}
+		for key, count := range keys {
+			totals[key] += count
+		}
+	}
+
+	result := make([]KeyCount, 0, len(totals))
+	for key, count := range totals {
+		result = append(result, KeyCount{Key: key, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Key < result[j].Key
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// Save drops counts older than the retention and writes the others if they changed
+func (s *Store) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+
+	oldest := s.now().UTC().Add(-Retention).Format(dayFormat)
+	for _, days := range s.counts {
+		for day := range days {
+			if day < oldest {
+				delete(days, day)
+			}
+		}
+	}
+
+	data, err := json.Marshal(s.counts)
+	if err != nil {
+		return fmt.Errorf("failed to marshal counts: %v", err)
+	}
+	if err := cache.WriteFileAtomic(s.path, data, 0644); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// FlushEvery saves the counts every interval until ctx is done, then a last time
+func (s *Store) FlushEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.Save(); err != nil {
+				logger.Error("Failed to save counts", "error", err)
+			}
+			return
+		case <-ticker.C:
+			if err := s.Save(); err != nil {
+				logger.Error("Failed to save counts", "error", err)
+			}
+		}
+	}
+}
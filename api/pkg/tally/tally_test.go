@@ -0,0 +1,54 @@
+package tally
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDailyAndTopCounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tally.json")
+	s, err := NewStore(path)
+	require.NoError(t, err)
+	now := time.Date(2025, 10, 3, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	s.Add(RulesRedirects, "3340")
+	s.Add(NewsletterSignups, "created")
+	now = now.AddDate(0, 0, -2)
+	s.Add(RulesRedirects, "3341")
+	s.Add(RulesRedirects, "3341")
+	s.Add(NewsletterSignups, "updated")
+	now = now.AddDate(0, 0, 2)
+
+	assert.Equal(t, []DayCount{
+		{Day: "2025-10-01", Count: 1},
+		{Day: "2025-10-02", Count: 0},
+		{Day: "2025-10-03", Count: 1},
+	}, s.Daily(NewsletterSignups, 3))
+	assert.Equal(t, []KeyCount{{Key: "3341", Count: 2}, {Key: "3340", Count: 1}}, s.Top(RulesRedirects, 7, 5))
+	assert.Equal(t, []KeyCount{{Key: "3340", Count: 1}}, s.Top(RulesRedirects, 1, 5))
+
+	require.NoError(t, s.Save())
+	reloaded, err := NewStore(path)
+	require.NoError(t, err)
+	reloaded.now = s.now
+	assert.Equal(t, s.Top(RulesRedirects, 7, 5), reloaded.Top(RulesRedirects, 7, 5))
+}
+
+func TestSaveDropsExpiredDays(t *testing.T) {
+	s, err := NewStore(filepath.Join(t.TempDir(), "tally.json"))
+	require.NoError(t, err)
+	now := time.Date(2025, 10, 3, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	s.Add(RulesRedirects, "3340")
+	now = now.Add(Retention + 24*time.Hour)
+	s.Add(RulesRedirects, "3341")
+	require.NoError(t, s.Save())
+
+	assert.Equal(t, []KeyCount{{Key: "3341", Count: 1}}, s.Top(RulesRedirects, 1000, 5))
+}
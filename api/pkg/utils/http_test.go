@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 10, 4, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 2*time.Minute, RetryAfter("120", now))
+	assert.Equal(t, 30*time.Second, RetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now))
+	assert.Zero(t, RetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+	assert.Zero(t, RetryAfter("", now))
+	assert.Zero(t, RetryAfter("soon", now))
+}
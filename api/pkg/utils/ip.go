@@ -3,17 +3,22 @@ package utils
 import (
 	"net"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-// GetIPsFromRequest retrieves all IPs from various headers in the Gin context
-func GetIPsFromRequest(c *gin.Context) string {
+// GetIPsFromRequest retrieves all IPs from various headers in the Gin context, sorted
+func GetIPsFromRequest(c *gin.Context) []string {
 	IPsMap := make(map[string]struct{})
 
 	// Get IP from RemoteAddr
-	addIP(strings.Split(c.Request.RemoteAddr, ":")[0], IPsMap)
+	if host, _, err := net.SplitHostPort(c.Request.RemoteAddr); err == nil {
+		addIP(host, IPsMap)
+	} else {
+		addIP(c.Request.RemoteAddr, IPsMap)
+	}
 
 	// Get ClientIP from Gin's built-in method
 	addIP(c.ClientIP(), IPsMap)
@@ -41,8 +46,8 @@ func GetIPsFromRequest(c *gin.Context) string {
 		ipsSlice = append(ipsSlice, IP)
 	}
 
-	// Join all IPs as a single string
-	return strings.Join(ipsSlice, ", ")
+	sort.Strings(ipsSlice)
+	return ipsSlice
 }
 
 // addIP adds an IP to the map if it's valid and not already present
@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 	_ "time/tzdata"
 )
@@ -46,3 +49,43 @@ func getSeasonDates(offset int) (time.Time, time.Time) {
 	endYear := currentYear + offset
 	return time.Date(startYear, time.July, 1, 0, 0, 0, 0, loc), time.Date(endYear, time.June, 30, 23, 59, 59, 999999999, loc)
 }
+
+// ParseSeason parses a season label such as "2025-2026" and returns its start and end
+// in France time zone. The second year must directly follow the first one.
+func ParseSeason(label string) (time.Time, time.Time, error) {
+	parts := strings.Split(strings.TrimSpace(label), "-")
+	if len(parts) != 2 {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid season %q, expected YYYY-YYYY", label)
+	}
+
+	startYear, err := strconv.Atoi(parts[0])
+	if err != nil || len(parts[0]) != 4 {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid season %q, expected YYYY-YYYY", label)
+	}
+	endYear, err := strconv.Atoi(parts[1])
+	if err != nil || endYear != startYear+1 {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid season %q, years must be consecutive", label)
+	}
+
+	loc := parisLocation()
+	return time.Date(startYear, time.July, 1, 0, 0, 0, 0, loc), time.Date(endYear, time.June, 30, 23, 59, 59, 999999999, loc), nil
+}
+
+// SeasonLabel returns the label ("2025-2026") of the season containing the given date
+func SeasonLabel(t time.Time) string {
+	t = t.In(parisLocation())
+	startYear := t.Year()
+	if t.Month() < time.July {
+		startYear--
+	}
+	return fmt.Sprintf("%d-%d", startYear, startYear+1)
+}
+
+// parisLocation returns the France time zone, falling back to UTC
+func parisLocation() *time.Location {
+	loc, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
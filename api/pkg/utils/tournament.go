@@ -1,5 +1,31 @@
 package utils
 
+import "time"
+
+// tournamentDateLayouts lists the date formats returned by the FFTT API
+var tournamentDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// ParseTournamentDate parses a tournament start or end date as returned by the FFTT API
+func ParseTournamentDate(date string) (time.Time, bool) {
+	for _, layout := range tournamentDateLayouts {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Today returns the date of now in France time zone, at midnight UTC like the dates parsed by
+// ParseTournamentDate, so that tournaments ending today are not in the past after 22:00
+func Today(now time.Time) time.Time {
+	year, month, day := now.In(parisLocation()).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
 // MapTournamentType converts single letter type to full name
 func MapTournamentType(t string) string {
 	switch t {
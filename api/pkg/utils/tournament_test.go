@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTodayUsesFranceTimeZone(t *testing.T) {
+	winter := time.Date(2026, 3, 14, 23, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), Today(winter), "00:30 in Paris")
+
+	summer := time.Date(2026, 7, 14, 21, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 7, 14, 0, 0, 0, 0, time.UTC), Today(summer), "23:30 in Paris")
+
+	end, _ := ParseTournamentDate("2026-03-14")
+	assert.True(t, end.Before(Today(winter)), "the tournament ended yesterday in Paris")
+}
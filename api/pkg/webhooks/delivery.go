@@ -0,0 +1,289 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"tournois-tt/api/pkg/events"
+)
+
+// Delivery policy
+const (
+	maxAttempts     = 8
+	baseBackoff     = 30 * time.Second
+	deliveryTimeout = 10 * time.Second
+	pollInterval    = 5 * time.Second
+)
+
+// Signature headers sent with every delivery
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Sign returns the signature of a payload sent at timestamp (Unix seconds): the hex encoded
+// HMAC-SHA256 of "<timestamp>.<payload>" keyed with the subscription secret
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewDeliveryClient returns an HTTP client that refuses to connect to special-purpose
+// addresses, such as loopback, private and link-local ones, so that subscriptions cannot be
+// used to reach internal services
+func NewDeliveryClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: deliveryTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !allowedIP(ip) {
+				return fmt.Errorf("webhook destination %s is not allowed", host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   deliveryTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// deniedPrefixes are the special-purpose ranges deliveries may not connect to, as
+// registered by IANA: "this network", private, shared (carrier-grade NAT), loopback,
+// link-local (including cloud metadata endpoints), documentation, benchmarking, reserved,
+// multicast and broadcast addresses, and their IPv6 counterparts
+var deniedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// allowedIP reports whether deliveries may connect to ip, outside of deniedPrefixes.
+// IPv4-mapped IPv6 addresses are checked as IPv4 addresses.
+func allowedIP(ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range deniedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Run enqueues the events published on bus and delivers them until ctx is done, saving
+// the deliveries after each pass
+func (m *Manager) Run(ctx context.Context, bus *events.Bus) {
+	consumed := make(chan struct{})
+	go func() {
+		defer close(consumed)
+		m.consume(ctx, bus)
+	}()
+	defer func() {
+		<-consumed
+		m.flush()
+	}()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		m.DeliverDue(ctx)
+		m.flush()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-m.wake:
+		}
+	}
+}
+
+// consume enqueues bus events, resubscribing from the last seen event when dropped
+func (m *Manager) consume(ctx context.Context, bus *events.Bus) {
+	var lastID uint64
+	for {
+		replay, subscription, complete := bus.Subscribe(bus.Epoch(), lastID)
+		if !complete {
+			logger.WarnContext(ctx, "Events are no longer buffered, some deliveries were skipped", "after_event_id", lastID)
+		}
+
+		handle := func(event events.Event) {
+			lastID = event.ID
+			if err := m.Enqueue(event); err != nil {
+				logger.ErrorContext(ctx, "Failed to enqueue event", "event_id", event.ID, "error", err)
+			}
+		}
+		for _, event := range replay {
+			handle(event)
+		}
+
+		for open := true; open; {
+			select {
+			case <-ctx.Done():
+				bus.Unsubscribe(subscription)
+				return
+			case event, ok := <-subscription.Events:
+				if ok {
+					handle(event)
+				}
+				open = ok
+			}
+		}
+	}
+}
+
+// DeliverDue attempts every pending delivery whose next attempt is due
+func (m *Manager) DeliverDue(ctx context.Context) {
+	now := m.now()
+
+	m.mu.Lock()
+	var due []Delivery
+	for _, delivery := range m.deliveries {
+		if delivery.Status == StatusPending && delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, delivery := range due {
+		if ctx.Err() != nil {
+			return
+		}
+
+		subscription, ok := m.Subscription(delivery.SubscriptionID)
+		if !ok {
+			continue
+		}
+		statusCode, err := m.send(ctx, subscription, delivery)
+		m.record(delivery.ID, statusCode, err)
+	}
+}
+
+// send posts a signed delivery and returns the response status code
+func (m *Manager) send(ctx context.Context, subscription Subscription, delivery Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := m.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "tournois-tt-webhooks/1.0")
+	req.Header.Set(HeaderID, delivery.ID)
+	req.Header.Set(HeaderEvent, string(delivery.EventType))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, delivery.Payload))
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// record stores the outcome of an attempt, scheduling a retry or dead-lettering on failure
+func (m *Manager) record(id string, statusCode int, sendErr error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.deliveries {
+		delivery := &m.deliveries[i]
+		if delivery.ID != id {
+			continue
+		}
+
+		now := m.now().UTC()
+		delivery.Attempts++
+		delivery.LastStatusCode = statusCode
+		delivery.UpdatedAt = now
+		delivery.LastError = ""
+
+		switch {
+		case sendErr == nil:
+			delivery.Status = StatusSucceeded
+			delivery.NextAttemptAt = nil
+		case delivery.Attempts >= maxAttempts:
+			delivery.Status = StatusDead
+			delivery.LastError = sendErr.Error()
+			delivery.NextAttemptAt = nil
+			logger.Warn("Delivery dead-lettered", "delivery_id", delivery.ID,
+				"subscription_id", delivery.SubscriptionID, "attempts", delivery.Attempts, "error", sendErr)
+		default:
+			next := now.Add(backoff(delivery.Attempts))
+			delivery.LastError = sendErr.Error()
+			delivery.NextAttemptAt = &next
+		}
+		m.dirty = true
+		break
+	}
+}
+
+// flush saves the deliveries, logging failures
+func (m *Manager) flush() {
+	if err := m.Flush(); err != nil {
+		logger.Error("Failed to save webhook store", "error", err)
+	}
+}
+
+// backoff returns the delay before the attempt following the given number of attempts:
+// 30s, 1m, 2m, 4m... doubling each time
+func backoff(attempts int) time.Duration {
+	return baseBackoff << (attempts - 1)
+}
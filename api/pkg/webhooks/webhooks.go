@@ -0,0 +1,383 @@
+// Package webhooks delivers tournament events to subscribed URLs. Payloads are signed with
+// HMAC-SHA256, failed deliveries are retried with exponential backoff until they are
+// dead-lettered, and subscriptions and the delivery log are persisted on disk.
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/events"
+	"tournois-tt/api/pkg/logging"
+
+	"github.com/google/uuid"
+)
+
+var logger = logging.For("webhooks")
+
+// Limits of the webhook store
+const (
+	maxSubscriptions         = 1000
+	maxSubscriptionsPerOwner = 10
+	maxDeliveries            = 5000
+	// maxPendingPerSubscription keeps a failing subscription from filling the store
+	maxPendingPerSubscription = 500
+)
+
+// Delivery statuses
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusDead      = "dead"
+)
+
+// Subscription is a URL notified of the tournament events matching its filters
+type Subscription struct {
+	ID         string        `json:"id"`
+	URL        string        `json:"url"`
+	Secret     string        `json:"secret,omitempty"`
+	Department string        `json:"department,omitempty"`
+	Region     string        `json:"region,omitempty"`
+	Type       string        `json:"type,omitempty"`
+	Events     []events.Type `json:"events"`
+	// Owner is the id of the API key that created the subscription
+	Owner     string    `json:"owner,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Delivery is an event sent, or to be sent, to a subscription
+type Delivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscriptionId"`
+	EventID        uint64          `json:"eventId"`
+	EventType      events.Type     `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	LastStatusCode int             `json:"lastStatusCode,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+}
+
+// state is the on-disk representation of the store
+type state struct {
+	Subscriptions []Subscription `json:"subscriptions"`
+	Deliveries    []Delivery     `json:"deliveries"`
+}
+
+// Manager stores subscriptions and delivers events to them
+type Manager struct {
+	mu            sync.Mutex
+	path          string
+	client        *http.Client
+	now           func() time.Time
+	lookup        func(host string) ([]net.IP, error)
+	subscriptions map[string]Subscription
+	deliveries    []Delivery
+	// dirty is set when deliveries changed since the last save. They are saved by Run,
+	// once per batch of events rather than on each event.
+	dirty bool
+	wake  chan struct{}
+}
+
+// NewManager loads the webhook store at path. Deliveries are made with client.
+func NewManager(path string, client *http.Client) (*Manager, error) {
+	m := &Manager{
+		path:          path,
+		client:        client,
+		now:           time.Now,
+		lookup:        net.LookupIP,
+		subscriptions: make(map[string]Subscription),
+		wake:          make(chan struct{}, 1),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook store: %v", err)
+	}
+
+	var s state
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook store: %v", err)
+	}
+	for _, subscription := range s.Subscriptions {
+		m.subscriptions[subscription.ID] = subscription
+	}
+	m.deliveries = s.Deliveries
+
+	return m, nil
+}
+
+// SetResolver replaces the DNS lookup checking the subscribed hosts, for tests
+func (m *Manager) SetResolver(lookup func(host string) ([]net.IP, error)) {
+	m.lookup = lookup
+}
+
+// Subscribe validates and registers a subscription of its owner, generating its id and
+// secret. The URL must be https and resolve to public addresses only.
+func (m *Manager) Subscribe(subscription Subscription) (Subscription, error) {
+	if err := m.checkTarget(subscription.URL); err != nil {
+		return Subscription{}, err
+	}
+
+	if len(subscription.Events) == 0 {
+		subscription.Events = []events.Type{events.TournamentCreated, events.TournamentUpdated, events.TournamentCancelled, events.TournamentRemoved}
+	}
+	for _, eventType := range subscription.Events {
+		switch eventType {
+		case events.TournamentCreated, events.TournamentUpdated, events.TournamentCancelled, events.TournamentRemoved:
+		default:
+			return Subscription{}, fmt.Errorf("unknown event type %q", eventType)
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return Subscription{}, fmt.Errorf("failed to generate secret: %v", err)
+	}
+
+	subscription.ID = uuid.NewString()
+	subscription.Secret = "whsec_" + hex.EncodeToString(secret)
+	subscription.CreatedAt = m.now().UTC()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.subscriptions) >= maxSubscriptions {
+		return Subscription{}, fmt.Errorf("too many webhook subscriptions")
+	}
+	owned := 0
+	for _, existing := range m.subscriptions {
+		if existing.Owner == subscription.Owner {
+			owned++
+		}
+	}
+	if owned >= maxSubscriptionsPerOwner {
+		return Subscription{}, fmt.Errorf("at most %d webhook subscriptions per API key", maxSubscriptionsPerOwner)
+	}
+	m.subscriptions[subscription.ID] = subscription
+
+	return subscription, m.save()
+}
+
+// checkTarget rejects URLs that are not https or whose host resolves to an internal address.
+// Deliveries check the address again when connecting, as DNS answers can change.
+func (m *Manager) checkTarget(rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil || target.Scheme != "https" || target.Hostname() == "" {
+		return fmt.Errorf("url must be an absolute https URL")
+	}
+
+	host := target.Hostname()
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		if ips, err = m.lookup(host); err != nil || len(ips) == 0 {
+			return fmt.Errorf("url host %s does not resolve", host)
+		}
+	}
+	for _, ip := range ips {
+		if !allowedIP(ip) {
+			return fmt.Errorf("url host %s is not a public address", host)
+		}
+	}
+	return nil
+}
+
+// Subscription returns a subscription by id
+func (m *Manager) Subscription(id string) (Subscription, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	subscription, ok := m.subscriptions[id]
+	return subscription, ok
+}
+
+// Unsubscribe removes a subscription and its pending deliveries
+func (m *Manager) Unsubscribe(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.subscriptions, id)
+	kept := m.deliveries[:0]
+	for _, delivery := range m.deliveries {
+		if delivery.SubscriptionID != id || delivery.Status != StatusPending {
+			kept = append(kept, delivery)
+		}
+	}
+	m.deliveries = kept
+
+	return m.save()
+}
+
+// Deliveries returns the delivery log of a subscription, newest first, optionally filtered by status
+func (m *Manager) Deliveries(subscriptionID, status string) []Delivery {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := []Delivery{}
+	for i := len(m.deliveries) - 1; i >= 0; i-- {
+		delivery := m.deliveries[i]
+		if delivery.SubscriptionID == subscriptionID && (status == "" || delivery.Status == status) {
+			result = append(result, delivery)
+		}
+	}
+	return result
+}
+
+// Enqueue records a pending delivery of an event for every matching subscription. It is
+// saved by the next Flush.
+func (m *Manager) Enqueue(event events.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %v", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]string, 0, len(m.subscriptions))
+	for id := range m.subscriptions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	now := m.now().UTC()
+	enqueued := 0
+	for _, id := range ids {
+		if !m.subscriptions[id].matches(event) {
+			continue
+		}
+		next := now
+		m.deliveries = append(m.deliveries, Delivery{
+			ID:             uuid.NewString(),
+			SubscriptionID: id,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         StatusPending,
+			NextAttemptAt:  &next,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+		enqueued++
+	}
+	if enqueued == 0 {
+		return nil
+	}
+
+	m.trim()
+	m.dirty = true
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// matches reports whether an event passes the subscription filters
+func (s Subscription) matches(event events.Event) bool {
+	t := event.Tournament
+	if s.Department != "" && t.Club.Department != s.Department {
+		return false
+	}
+	if s.Region != "" && !strings.EqualFold(t.Club.Region, s.Region) {
+		return false
+	}
+	if s.Type != "" && t.Type != s.Type {
+		return false
+	}
+	for _, eventType := range s.Events {
+		if eventType == event.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// trim dead-letters the oldest pending deliveries of a subscription beyond
+// maxPendingPerSubscription, then drops the oldest finished deliveries beyond maxDeliveries.
+// Callers hold m.mu.
+func (m *Manager) trim() {
+	pending := make(map[string]int)
+	for _, delivery := range m.deliveries {
+		if delivery.Status == StatusPending {
+			pending[delivery.SubscriptionID]++
+		}
+	}
+	now := m.now().UTC()
+	for i := range m.deliveries {
+		delivery := &m.deliveries[i]
+		if delivery.Status != StatusPending || pending[delivery.SubscriptionID] <= maxPendingPerSubscription {
+			continue
+		}
+		pending[delivery.SubscriptionID]--
+		delivery.Status = StatusDead
+		delivery.LastError = "dropped, too many pending deliveries"
+		delivery.NextAttemptAt = nil
+		delivery.UpdatedAt = now
+		logger.Warn("Delivery dead-lettered", "delivery_id", delivery.ID,
+			"subscription_id", delivery.SubscriptionID, "attempts", delivery.Attempts, "error", delivery.LastError)
+	}
+
+	excess := len(m.deliveries) - maxDeliveries
+	if excess <= 0 {
+		return
+	}
+
+	kept := make([]Delivery, 0, maxDeliveries)
+	for _, delivery := range m.deliveries {
+		if excess > 0 && delivery.Status != StatusPending {
+			excess--
+			continue
+		}
+		kept = append(kept, delivery)
+	}
+	m.deliveries = kept
+}
+
+// save writes the store to disk atomically. Callers hold m.mu.
+func (m *Manager) save() error {
+	s := state{
+		Subscriptions: make([]Subscription, 0, len(m.subscriptions)),
+		Deliveries:    m.deliveries,
+	}
+	for _, subscription := range m.subscriptions {
+		s.Subscriptions = append(s.Subscriptions, subscription)
+	}
+	sort.Slice(s.Subscriptions, func(i, j int) bool { return s.Subscriptions[i].CreatedAt.Before(s.Subscriptions[j].CreatedAt) })
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook store: %v", err)
+	}
+	if err := cache.WriteFileAtomic(m.path, data, 0600); err != nil {
+		return err
+	}
+	m.dirty = false
+	return nil
+}
+
+// Flush saves the deliveries enqueued or attempted since the last save
+func (m *Manager) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.dirty {
+		return nil
+	}
+	return m.save()
+}
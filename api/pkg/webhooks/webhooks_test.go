@@ -0,0 +1,267 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tournois-tt/api/pkg/cache"
+	"tournois-tt/api/pkg/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestManager returns a manager stored in a temporary directory with a controllable clock
+func newTestManager(t *testing.T) (*Manager, *time.Time) {
+	t.Helper()
+
+	m, err := NewManager(filepath.Join(t.TempDir(), "webhooks.json"), http.DefaultClient)
+	require.NoError(t, err)
+
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	m.SetResolver(publicResolver)
+	return m, &now
+}
+
+// publicResolver resolves every host to a public address
+func publicResolver(string) ([]net.IP, error) {
+	return []net.IP{net.ParseIP("93.184.215.14")}, nil
+}
+
+// newTestServer starts a TLS server that the manager reaches as https://example.com and
+// returns the URL to subscribe
+func newTestServer(t *testing.T, m *Manager, handler http.HandlerFunc) string {
+	t.Helper()
+
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	client := server.Client()
+	client.Transport.(*http.Transport).DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	m.client = client
+	return "https://example.com/hook"
+}
+
+func rennesEvent(id uint64) events.Event {
+	return events.Event{
+		ID:         id,
+		Type:       events.TournamentCreated,
+		Tournament: cache.TournamentCache{ID: 1, Type: "P", Club: cache.Club{Department: "35", Region: "Bretagne"}},
+	}
+}
+
+func TestDeliveriesAreSigned(t *testing.T) {
+	m, now := newTestManager(t)
+
+	var secret string
+	received := make(chan bool, 1)
+	target := newTestServer(t, m, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		received <- timestamp == now.Unix() &&
+			r.Header.Get(HeaderEvent) == string(events.TournamentCreated) &&
+			r.Header.Get(HeaderSignature) == Sign(secret, timestamp, body)
+	})
+
+	subscription, err := m.Subscribe(Subscription{URL: target, Region: "bretagne"})
+	require.NoError(t, err)
+	secret = subscription.Secret
+
+	require.NoError(t, m.Enqueue(rennesEvent(1)))
+	m.DeliverDue(context.Background())
+
+	assert.True(t, <-received, "signature headers do not verify")
+
+	deliveries := m.Deliveries(subscription.ID, "")
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, StatusSucceeded, deliveries[0].Status)
+	assert.Equal(t, http.StatusOK, deliveries[0].LastStatusCode)
+}
+
+func TestFailedDeliveriesAreRetriedThenDeadLettered(t *testing.T) {
+	m, now := newTestManager(t)
+
+	var attempts atomic.Int32
+	target := newTestServer(t, m, func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	subscription, err := m.Subscribe(Subscription{URL: target})
+	require.NoError(t, err)
+	require.NoError(t, m.Enqueue(rennesEvent(1)))
+
+	m.DeliverDue(context.Background())
+	delivery := m.Deliveries(subscription.ID, StatusPending)[0]
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, now.Add(30*time.Second), *delivery.NextAttemptAt)
+
+	// Not due yet
+	m.DeliverDue(context.Background())
+	assert.EqualValues(t, 1, attempts.Load())
+
+	for i := 1; i < maxAttempts; i++ {
+		*now = now.Add(backoff(i))
+		m.DeliverDue(context.Background())
+	}
+
+	assert.EqualValues(t, maxAttempts, attempts.Load())
+	dead := m.Deliveries(subscription.ID, StatusDead)
+	require.Len(t, dead, 1)
+	assert.Equal(t, http.StatusServiceUnavailable, dead[0].LastStatusCode)
+	assert.Nil(t, dead[0].NextAttemptAt)
+}
+
+func TestSubscriptionFiltersAndPersistence(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	matching, err := m.Subscribe(Subscription{URL: "https://example.com/a", Department: "35", Type: "P"})
+	require.NoError(t, err)
+	other, err := m.Subscribe(Subscription{URL: "https://example.com/b", Department: "29"})
+	require.NoError(t, err)
+	cancelledOnly, err := m.Subscribe(Subscription{URL: "https://example.com/c", Events: []events.Type{events.TournamentCancelled}})
+	require.NoError(t, err)
+
+	_, err = m.Subscribe(Subscription{URL: "https://example.com/d", Events: []events.Type{"tournament.deleted"}})
+	assert.Error(t, err)
+	_, err = m.Subscribe(Subscription{URL: "/relative"})
+	assert.Error(t, err)
+
+	require.NoError(t, m.Enqueue(rennesEvent(1)))
+	assert.Len(t, m.Deliveries(matching.ID, StatusPending), 1)
+	assert.Empty(t, m.Deliveries(other.ID, ""))
+	assert.Empty(t, m.Deliveries(cancelledOnly.ID, ""))
+
+	require.NoError(t, m.Flush())
+	reloaded, err := NewManager(m.path, http.DefaultClient)
+	require.NoError(t, err)
+	restored, ok := reloaded.Subscription(matching.ID)
+	require.True(t, ok)
+	assert.Equal(t, matching.Secret, restored.Secret)
+	assert.Len(t, reloaded.Deliveries(matching.ID, StatusPending), 1)
+
+	require.NoError(t, reloaded.Unsubscribe(matching.ID))
+	_, ok = reloaded.Subscription(matching.ID)
+	assert.False(t, ok)
+	assert.Empty(t, reloaded.Deliveries(matching.ID, ""))
+}
+
+func TestPendingDeliveriesAreCappedPerSubscription(t *testing.T) {
+	m, _ := newTestManager(t)
+	subscription, err := m.Subscribe(Subscription{URL: "https://example.com/hook"})
+	require.NoError(t, err)
+
+	for id := uint64(1); id <= maxPendingPerSubscription+2; id++ {
+		require.NoError(t, m.Enqueue(rennesEvent(id)))
+	}
+
+	assert.Len(t, m.Deliveries(subscription.ID, StatusPending), maxPendingPerSubscription)
+	dead := m.Deliveries(subscription.ID, StatusDead)
+	require.Len(t, dead, 2)
+	assert.Equal(t, []uint64{2, 1}, []uint64{dead[0].EventID, dead[1].EventID}, "the oldest deliveries are dropped")
+	assert.Contains(t, dead[0].LastError, "too many pending deliveries")
+	assert.Nil(t, dead[0].NextAttemptAt)
+}
+
+func TestSubscribeRejectsInternalTargets(t *testing.T) {
+	m, _ := newTestManager(t)
+	m.SetResolver(func(host string) ([]net.IP, error) {
+		if host == "metadata.internal" {
+			return []net.IP{net.ParseIP("169.254.169.254")}, nil
+		}
+		return publicResolver(host)
+	})
+
+	for _, target := range []string{
+		"http://example.com/hook",
+		"https://127.0.0.1/hook",
+		"https://[::1]/hook",
+		"https://10.0.0.5/hook",
+		"https://192.168.1.1/hook",
+		"https://169.254.169.254/latest/meta-data",
+		"https://metadata.internal/hook",
+	} {
+		_, err := m.Subscribe(Subscription{URL: target, Owner: "key"})
+		assert.Error(t, err, target)
+	}
+
+	for i := 0; i < maxSubscriptionsPerOwner; i++ {
+		_, err := m.Subscribe(Subscription{URL: "https://example.com/hook", Owner: "key"})
+		require.NoError(t, err)
+	}
+	_, err := m.Subscribe(Subscription{URL: "https://example.com/hook", Owner: "key"})
+	assert.ErrorContains(t, err, "per API key")
+	_, err = m.Subscribe(Subscription{URL: "https://example.com/hook", Owner: "other"})
+	assert.NoError(t, err)
+}
+
+func TestDeliveryClientRejectsInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := NewDeliveryClient().Post(server.URL, "application/json", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not allowed")
+}
+
+func TestRunDeliversBusEvents(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	received := make(chan string, 1)
+	target := newTestServer(t, m, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case received <- r.Header.Get(HeaderEvent):
+		default:
+		}
+	})
+
+	_, err := m.Subscribe(Subscription{URL: target})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bus := events.NewBus(events.DefaultReplaySize)
+	stopped := make(chan struct{})
+	go func() {
+		m.Run(ctx, bus)
+		close(stopped)
+	}()
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	// Wait for the manager to subscribe before publishing
+	require.Eventually(t, func() bool {
+		bus.Publish(events.TournamentCancelled, cache.TournamentCache{ID: 1}, nil)
+		select {
+		case eventType := <-received:
+			return eventType == string(events.TournamentCancelled)
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestAllowedIP(t *testing.T) {
+	for _, ip := range []string{"93.184.215.14", "2606:2800:21f:cb07:6820:80da:af6b:8b2c"} {
+		assert.True(t, allowedIP(net.ParseIP(ip)), ip)
+	}
+	for _, ip := range []string{
+		"0.0.0.0", "0.1.2.3", "10.1.2.3", "100.64.0.1", "100.127.255.254", "127.0.0.1", "169.254.169.254",
+		"172.16.0.1", "192.168.1.1", "198.18.0.1", "224.0.0.1", "255.255.255.255",
+		"::", "::1", "::ffff:10.0.0.1", "::ffff:100.64.0.1", "64:ff9b::a00:1", "fd00::1", "fe80::1", "ff02::1",
+	} {
+		assert.False(t, allowedIP(net.ParseIP(ip)), ip)
+	}
+}
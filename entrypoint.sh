@@ -42,9 +42,7 @@ OUTPUT_DIR=/usr/share/nginx/html node scripts/generate-sitemap.js
 OUTPUT_DIR=/usr/share/nginx/html node scripts/generate-rss.js
 echo "Static content generated successfully"
 
-# Start API service
+# Start API service, replacing the shell so that it receives the stop signal and shuts
+# down gracefully
 echo "Starting API service..."
-cd /app/api && ./api
-
-# Keep container running
-wait
\ No newline at end of file
+cd /app/api && exec ./tournois serve
\ No newline at end of file